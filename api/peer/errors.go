@@ -107,6 +107,14 @@ func (e ErrPeerRemoveNotInList) Error() string {
 	return fmt.Sprintf("can't remove peer (%s) because it is not in peerlist", string(e))
 }
 
+// ErrPeerChangeNotInList is returned to peer list updater if the peerlist
+// is not tracking the peer to change for a given identifier
+type ErrPeerChangeNotInList string
+
+func (e ErrPeerChangeNotInList) Error() string {
+	return fmt.Sprintf("can't change peer (%s) because it is not in peerlist", string(e))
+}
+
 // ErrChooseContextHasNoDeadline is returned when a context is sent to a peerlist with no deadline
 // DEPRECATED use yarpcerrors api instead.
 type ErrChooseContextHasNoDeadline string
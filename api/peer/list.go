@@ -51,6 +51,11 @@ type ListUpdates struct {
 
 	// Removals are the identifiers that should be removed to the list
 	Removals []Identifier
+
+	// Changes are identifiers of peers already in the list whose attributes,
+	// like their weight, have changed. Lists that do not track attributes of
+	// identifiers may ignore changes.
+	Changes []Identifier
 }
 
 // ChooserList is both a Chooser and a List, useful for expressing both
@@ -48,6 +48,38 @@ type Identifier interface {
 	Identifier() string
 }
 
+// WeightedIdentifier is an Identifier for a peer that should receive a share
+// of requests proportional to its weight relative to the other peers in a
+// list.
+// Peer lists that do not support weights treat all peers equally.
+type WeightedIdentifier interface {
+	Identifier
+
+	// Weight of the peer. Peers with a weight of zero or less are treated as
+	// having the default weight of 1.
+	Weight() int
+}
+
+// WeightOf returns the weight of the peer with the given identifier: its
+// weight if it is a WeightedIdentifier with a positive weight, and 1
+// otherwise.
+func WeightOf(pid Identifier) int {
+	if w, ok := pid.(WeightedIdentifier); ok && w.Weight() > 0 {
+		return w.Weight()
+	}
+	return 1
+}
+
+// ZonedIdentifier is an Identifier for a peer in a particular zone, like a
+// data center or availability zone.
+// Peer lists that are not locality-aware ignore zones.
+type ZonedIdentifier interface {
+	Identifier
+
+	// Zone of the peer, or an empty string if unknown.
+	Zone() string
+}
+
 // StatusPeer captures a concrete peer implementation for a particular
 // transport, exposing its Identifier and Status.
 // StatusPeer provides observability without mutability.
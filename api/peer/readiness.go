@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peer
+
+import (
+	"context"
+	"math"
+)
+
+// Readiness specifies how many peers of a peer list must be available for
+// the list to be ready to send requests.
+//
+// A peer list is ready when at least one of its peers is available and
+// either threshold is met. The zero value requires only one available peer.
+type Readiness struct {
+	// MinAvailable is the number of available peers that makes the list
+	// ready.
+	MinAvailable int
+
+	// MinAvailableFraction is the fraction of the peers in the list, between
+	// 0 and 1, that must be available for the list to be ready.
+	MinAvailableFraction float64
+}
+
+// IsReady returns whether a peer list with the given number of available
+// peers, out of the given total, is ready.
+func (r Readiness) IsReady(available, total int) bool {
+	if available <= 0 {
+		return false
+	}
+	if r.MinAvailable <= 0 && r.MinAvailableFraction <= 0 {
+		return true
+	}
+	if r.MinAvailable > 0 && available >= r.MinAvailable {
+		return true
+	}
+	return r.MinAvailableFraction > 0 &&
+		available >= int(math.Ceil(r.MinAvailableFraction*float64(total)))
+}
+
+// ReadinessWaiter is implemented by peer lists and choosers that can report
+// when they are ready to send requests, letting callers wait for connections
+// to be established rather than have their first requests time out.
+type ReadinessWaiter interface {
+	// WaitUntilReady blocks until the peer list meets the readiness
+	// criteria, or returns an error if the context finishes first.
+	WaitUntilReady(ctx context.Context, r Readiness) error
+}
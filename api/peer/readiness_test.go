@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessIsReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		readiness Readiness
+		available int
+		total     int
+		want      bool
+	}{
+		{name: "default no peers", want: false},
+		{name: "default one peer", available: 1, total: 3, want: true},
+		{name: "min available unmet", readiness: Readiness{MinAvailable: 2}, available: 1, total: 3, want: false},
+		{name: "min available met", readiness: Readiness{MinAvailable: 2}, available: 2, total: 3, want: true},
+		{name: "fraction unmet", readiness: Readiness{MinAvailableFraction: 0.5}, available: 1, total: 3, want: false},
+		{name: "fraction met", readiness: Readiness{MinAvailableFraction: 0.5}, available: 2, total: 3, want: true},
+		{name: "fraction no peers", readiness: Readiness{MinAvailableFraction: 0.5}, want: false},
+		{
+			name:      "either threshold",
+			readiness: Readiness{MinAvailable: 3, MinAvailableFraction: 0.5},
+			available: 3,
+			total:     10,
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.readiness.IsReady(tt.available, tt.total))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peer
+
+import "context"
+
+// Watcher watches a service discovery system, like Consul, etcd or ZooKeeper,
+// for the peers of a service.
+//
+// Watchers report what they observe as a stream of snapshots. Use the
+// go.uber.org/yarpc/peer/watch package to keep a peer list up to date with a
+// Watcher.
+type Watcher interface {
+	// Watch begins watching for peers, sending snapshots to the returned
+	// channel until the context is cancelled, at which point the watcher
+	// closes the channel.
+	//
+	// Watchers are responsible for retrying and reconnecting to the
+	// discovery system. Failures should be reported as snapshots with an
+	// error rather than by closing the channel.
+	Watch(ctx context.Context) (<-chan Snapshot, error)
+}
+
+// Snapshot is an observation from a Watcher. A snapshot either lists every
+// peer of the service, or describes the changes since the previous snapshot.
+type Snapshot struct {
+	// Full indicates that Peers lists every peer of the service. Otherwise,
+	// Updates holds the changes since the previous snapshot.
+	Full bool
+
+	// Peers of the service, if the snapshot is full.
+	Peers []Identifier
+
+	// Changes since the previous snapshot, if the snapshot is not full.
+	Updates ListUpdates
+
+	// Err reports a failure to watch the discovery system. Peers and Updates
+	// are ignored for snapshots with an error.
+	Err error
+}
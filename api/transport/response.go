@@ -20,13 +20,21 @@
 
 package transport
 
-import "io"
+import (
+	"io"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
 
 // Response is the low level response representation.
 type Response struct {
 	Headers          Headers
 	Body             io.ReadCloser
 	ApplicationError bool
+
+	// ApplicationErrorCode is the code of the application error in this
+	// response, if the server specified one, or CodeOK otherwise.
+	ApplicationErrorCode yarpcerrors.Code
 }
 
 // ResponseWriter allows Handlers to write responses in a streaming fashion.
@@ -47,3 +55,14 @@ type ResponseWriter interface {
 	// of Write().
 	SetApplicationError()
 }
+
+// ApplicationErrorCodeSetter is implemented by ResponseWriters that can
+// convey the code of an application error to the caller, so that callers
+// and observability middleware can tell, for instance, an exception meaning
+// "not found" from one meaning "invalid argument".
+type ApplicationErrorCodeSetter interface {
+	// SetApplicationErrorCode specifies the code of the application error in
+	// this response. If called, this MUST be called along with
+	// SetApplicationError and before any invocation of Write().
+	SetApplicationErrorCode(yarpcerrors.Code)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package transporttest
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// _pipeBufferSize is the number of messages each direction of a StreamPipe
+// holds before SendMessage blocks.
+const _pipeBufferSize = 10
+
+// StreamPipe is one end of an in-memory stream, for testing stream clients
+// and handlers without a transport. Messages sent on one end are received on
+// the other.
+type StreamPipe struct {
+	ctx     context.Context
+	request *transport.StreamRequest
+	send    chan<- *transport.StreamMessage
+	recv    <-chan *transport.StreamMessage
+
+	// closed is closed when this end is closed, and peerClosed when the
+	// other end is.
+	closed     chan struct{}
+	peerClosed <-chan struct{}
+	closeOnce  sync.Once
+}
+
+var _ transport.StreamCloser = (*StreamPipe)(nil)
+
+// NewStreamPipe returns the client and server ends of an in-memory stream
+// for the given request.
+//
+// Closing one end makes ReceiveMessage return io.EOF on the other end once
+// it has received all messages sent before.
+func NewStreamPipe(ctx context.Context, req *transport.StreamRequest) (client, server *StreamPipe) {
+	toServer := make(chan *transport.StreamMessage, _pipeBufferSize)
+	toClient := make(chan *transport.StreamMessage, _pipeBufferSize)
+	clientClosed := make(chan struct{})
+	serverClosed := make(chan struct{})
+	client = &StreamPipe{
+		ctx:        ctx,
+		request:    req,
+		send:       toServer,
+		recv:       toClient,
+		closed:     clientClosed,
+		peerClosed: serverClosed,
+	}
+	server = &StreamPipe{
+		ctx:        ctx,
+		request:    req,
+		send:       toClient,
+		recv:       toServer,
+		closed:     serverClosed,
+		peerClosed: clientClosed,
+	}
+	return client, server
+}
+
+// Context returns the context of the stream.
+func (s *StreamPipe) Context() context.Context {
+	return s.ctx
+}
+
+// Request returns the request the stream was opened with.
+func (s *StreamPipe) Request() *transport.StreamRequest {
+	return s.request
+}
+
+// SendMessage sends a message to the other end of the stream. It blocks
+// while the buffer of the stream is full, until the given context is done.
+//
+// Messages may not be sent once this end is closed.
+func (s *StreamPipe) SendMessage(ctx context.Context, msg *transport.StreamMessage) error {
+	select {
+	case <-s.closed:
+		return yarpcerrors.FailedPreconditionErrorf("cannot send a message on a closed stream")
+	default:
+	}
+
+	select {
+	case s.send <- msg:
+		return nil
+	case <-s.closed:
+		return yarpcerrors.FailedPreconditionErrorf("cannot send a message on a closed stream")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReceiveMessage receives the next message from the other end of the
+// stream, or io.EOF if the other end is closed. It blocks until a message is
+// available or the given context is done.
+func (s *StreamPipe) ReceiveMessage(ctx context.Context) (*transport.StreamMessage, error) {
+	select {
+	case msg := <-s.recv:
+		return msg, nil
+	case <-s.peerClosed:
+		// Deliver messages sent before the other end was closed.
+		select {
+		case msg := <-s.recv:
+			return msg, nil
+		default:
+			return nil, io.EOF
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close closes this end of the stream. Closing it again has no effect.
+func (s *StreamPipe) Close(context.Context) error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package transporttest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func newStreamMessage(body string) *transport.StreamMessage {
+	return &transport.StreamMessage{Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+}
+
+func TestStreamPipe(t *testing.T) {
+	ctx := context.Background()
+	req := &transport.StreamRequest{Meta: &transport.RequestMeta{Procedure: "proc"}}
+	client, server := NewStreamPipe(ctx, req)
+	assert.Equal(t, req, server.Request())
+	assert.Equal(t, ctx, server.Context())
+
+	msg := newStreamMessage("hello")
+	require.NoError(t, client.SendMessage(ctx, msg))
+	require.NoError(t, client.Close(ctx))
+	assert.NoError(t, client.Close(ctx), "closing twice should have no effect")
+
+	// Messages sent before the close are still delivered.
+	got, err := server.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, msg, got)
+	_, err = server.ReceiveMessage(ctx)
+	assert.Equal(t, io.EOF, err)
+
+	err = client.SendMessage(ctx, newStreamMessage("too late"))
+	assert.True(t, yarpcerrors.IsFailedPrecondition(err), "expected a failed precondition error, got %v", err)
+
+	// The other direction stays open.
+	require.NoError(t, server.SendMessage(ctx, msg))
+	got, err = client.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, msg, got)
+}
+
+func TestStreamPipeContext(t *testing.T) {
+	client, server := NewStreamPipe(context.Background(), &transport.StreamRequest{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := server.ReceiveMessage(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	for i := 0; i < _pipeBufferSize; i++ {
+		require.NoError(t, client.SendMessage(context.Background(), newStreamMessage("fill")))
+	}
+	assert.Equal(t, context.Canceled, client.SendMessage(ctx, newStreamMessage("full")))
+}
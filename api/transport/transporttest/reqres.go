@@ -29,6 +29,7 @@ import (
 	"testing"
 
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // RequestMatcher may be used in gomock argument lists to assert that two
@@ -210,9 +211,10 @@ func (m ResponseMatcher) Matches(got interface{}) bool {
 // FakeResponseWriter is a ResponseWriter that records the headers and the body
 // written to it.
 type FakeResponseWriter struct {
-	IsApplicationError bool
-	Headers            transport.Headers
-	Body               bytes.Buffer
+	IsApplicationError   bool
+	ApplicationErrorCode yarpcerrors.Code
+	Headers              transport.Headers
+	Body                 bytes.Buffer
 }
 
 // SetApplicationError for FakeResponseWriter.
@@ -220,6 +222,11 @@ func (fw *FakeResponseWriter) SetApplicationError() {
 	fw.IsApplicationError = true
 }
 
+// SetApplicationErrorCode for FakeResponseWriter.
+func (fw *FakeResponseWriter) SetApplicationErrorCode(code yarpcerrors.Code) {
+	fw.ApplicationErrorCode = code
+}
+
 // AddHeaders for FakeResponseWriter.
 func (fw *FakeResponseWriter) AddHeaders(h transport.Headers) {
 	for k, v := range h.OriginalItems() {
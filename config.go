@@ -29,6 +29,9 @@ import (
 	"go.uber.org/net/metrics"
 	"go.uber.org/net/metrics/tallypush"
 	"go.uber.org/yarpc/api/middleware"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/authorization"
 	"go.uber.org/yarpc/internal/observability"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -39,6 +42,8 @@ const (
 	// to be configurable.
 	_tallyPushInterval = 500 * time.Millisecond
 	_packageName       = "yarpc"
+
+	_defaultOutboundReadinessTimeout = 10 * time.Second
 )
 
 // LogLevelConfig configures the levels at which YARPC logs various things.
@@ -146,6 +151,119 @@ func (c MetricsConfig) scope(name string, logger *zap.Logger) (*metrics.Scope, c
 	return meter, stopMeter
 }
 
+// AuthorizationAction is the outcome of an AuthorizationRule.
+//
+// The zero value leaves the action unspecified.
+type AuthorizationAction int
+
+const (
+	// AuthorizationDeny rejects requests with a CodePermissionDenied error.
+	AuthorizationDeny AuthorizationAction = iota + 1
+
+	// AuthorizationAllow lets requests through to the handler.
+	AuthorizationAllow
+)
+
+// AuthorizationRule matches inbound requests by caller, service, procedure
+// and encoding.
+//
+// Callers, Services and Procedures are lists of glob patterns in the syntax
+// accepted by path.Match, for example "KeyValue::get*". Encodings are matched
+// exactly. An empty list matches all requests.
+type AuthorizationRule struct {
+	// Whether matching requests are allowed or denied.
+	//
+	// Defaults to AuthorizationDeny.
+	Action AuthorizationAction
+
+	Callers    []string
+	Services   []string
+	Procedures []string
+	Encodings  []transport.Encoding
+}
+
+// AuthorizationConfig restricts which callers may invoke which procedures
+// of this service.
+//
+// Denied requests fail with a CodePermissionDenied error and are logged at
+// warn level.
+type AuthorizationConfig struct {
+	// Rules are evaluated in order against every inbound request. The first
+	// rule that matches decides whether the request is allowed.
+	//
+	// If there are no rules and Default is unspecified, all requests are
+	// allowed.
+	Rules []AuthorizationRule
+
+	// Default is the action taken for requests that match none of the
+	// Rules. Setting it to AuthorizationDeny without any Rules denies all
+	// requests.
+	//
+	// Defaults to AuthorizationDeny if there are Rules.
+	Default AuthorizationAction
+}
+
+func (c AuthorizationConfig) middleware(logger *zap.Logger) (*authorization.Middleware, error) {
+	rules := make([]authorization.Rule, len(c.Rules))
+	for i, r := range c.Rules {
+		rules[i] = authorization.Rule{
+			Action:     authorizationAction(r.Action),
+			Callers:    r.Callers,
+			Services:   r.Services,
+			Procedures: r.Procedures,
+			Encodings:  r.Encodings,
+		}
+	}
+
+	return authorization.NewMiddleware(authorization.Config{
+		Logger:  logger,
+		Rules:   rules,
+		Default: authorizationAction(c.Default),
+	})
+}
+
+func authorizationAction(a AuthorizationAction) authorization.Action {
+	if a == AuthorizationAllow {
+		return authorization.Allow
+	}
+	return authorization.Deny
+}
+
+// OutboundReadinessConfig configures whether the dispatcher waits for the
+// peer lists of its outbounds to become ready before it starts its inbounds,
+// so that the first requests the service handles do not time out waiting for
+// connections to its dependencies.
+//
+// Only outbounds whose peer choosers implement peer.ReadinessWaiter are
+// waited for, like those backed by the peer lists in the go.uber.org/yarpc/peer
+// packages. The dispatcher logs a warning naming the other outbounds.
+type OutboundReadinessConfig struct {
+	// Enabled makes the dispatcher wait for outbound readiness before
+	// starting inbounds, both in Start and in PhasedStarter.StartInbounds.
+	Enabled bool
+
+	// Readiness specifies how many peers of each peer list must be
+	// available. Defaults to one.
+	Readiness peer.Readiness
+
+	// Timeout is the longest the dispatcher waits for readiness.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// FailOnTimeout makes startup fail if the outbounds are not ready
+	// within the timeout. By default, the dispatcher logs a warning and
+	// starts its inbounds anyway.
+	FailOnTimeout bool
+}
+
+func (c OutboundReadinessConfig) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return _defaultOutboundReadinessTimeout
+}
+
 // Config specifies the parameters of a new Dispatcher constructed via
 // NewDispatcher.
 type Config struct {
@@ -186,6 +304,13 @@ type Config struct {
 	// Configures telemetry.
 	Metrics MetricsConfig
 
+	// Restricts which callers may invoke which procedures.
+	Authorization AuthorizationConfig
+
+	// Waits for outbound peer lists to become ready before starting
+	// inbounds.
+	OutboundReadiness OutboundReadinessConfig
+
 	// DisableAutoObservabilityMiddleware is used to stop the dispatcher from
 	// automatically attaching observability middleware to all inbounds and
 	// outbounds.  It is the assumption that if if this option is disabled the
@@ -79,7 +79,9 @@ func NewDispatcher(cfg Config) *Dispatcher {
 	extractor := cfg.Logging.extractor()
 
 	meter, stopMeter := cfg.Metrics.scope(cfg.Name, logger)
+	cfg = addAuthorizationMiddleware(cfg, logger)
 	cfg = addObservingMiddleware(cfg, meter, logger, extractor)
+	readinessWaiters, unwaitableOutbounds := collectReadinessWaiters(cfg.Outbounds)
 
 	return &Dispatcher{
 		name:              cfg.Name,
@@ -87,6 +89,9 @@ func NewDispatcher(cfg Config) *Dispatcher {
 		inbounds:          cfg.Inbounds,
 		outbounds:         convertOutbounds(cfg.Outbounds, cfg.OutboundMiddleware),
 		transports:        collectTransports(cfg.Inbounds, cfg.Outbounds),
+		readiness:         cfg.OutboundReadiness,
+		readinessWaiters:  readinessWaiters,
+		unwaitable:        unwaitableOutbounds,
 		inboundMiddleware: cfg.InboundMiddleware,
 		log:               logger,
 		meter:             meter,
@@ -95,6 +100,25 @@ func NewDispatcher(cfg Config) *Dispatcher {
 	}
 }
 
+func addAuthorizationMiddleware(cfg Config, logger *zap.Logger) Config {
+	if len(cfg.Authorization.Rules) == 0 && cfg.Authorization.Default != AuthorizationDeny {
+		return cfg
+	}
+
+	authorizer, err := cfg.Authorization.middleware(logger)
+	if err != nil {
+		panic("yarpc.NewDispatcher expects valid authorization rules: " + err.Error())
+	}
+
+	// Authorization runs after the observability middleware so that denied
+	// requests are still reflected in logs and metrics.
+	cfg.InboundMiddleware.Unary = inboundmiddleware.UnaryChain(authorizer, cfg.InboundMiddleware.Unary)
+	cfg.InboundMiddleware.Oneway = inboundmiddleware.OnewayChain(authorizer, cfg.InboundMiddleware.Oneway)
+	cfg.InboundMiddleware.Stream = inboundmiddleware.StreamChain(authorizer, cfg.InboundMiddleware.Stream)
+
+	return cfg
+}
+
 func addObservingMiddleware(cfg Config, meter *metrics.Scope, logger *zap.Logger, extractor observability.ContextExtractor) Config {
 	if cfg.DisableAutoObservabilityMiddleware {
 		return cfg
@@ -210,13 +234,18 @@ type Dispatcher struct {
 	outbounds  Outbounds
 	transports []transport.Transport
 
+	readiness        OutboundReadinessConfig
+	readinessWaiters []outboundReadinessWaiter
+	unwaitable       []string // outbounds whose readiness is unknown
+
 	inboundMiddleware InboundMiddleware
 
 	log       *zap.Logger
 	meter     *metrics.Scope
 	stopMeter context.CancelFunc
 
-	once *lifecycle.Once
+	once      *lifecycle.Once
+	lifecycle lifecycleObservers
 }
 
 // Inbounds returns a copy of the list of inbounds for this RPC object.
@@ -395,6 +424,7 @@ func (d *Dispatcher) Stop() error {
 	}
 	return d.once.Stop(func() error {
 		d.log.Info("shutting down dispatcher")
+		d.lifecycle.notifyStopping()
 		return multierr.Combine(
 			stopper.StopInbounds(),
 			stopper.StopOutbounds(),
@@ -413,7 +443,10 @@ func (d *Dispatcher) Stop() error {
 // a nil error; the caller is responsible for using the PhasedStopper to
 // complete shutdown.
 func (d *Dispatcher) PhasedStop() (*PhasedStopper, error) {
-	if err := d.once.Stop(func() error { return nil }); err != nil {
+	if err := d.once.Stop(func() error {
+		d.lifecycle.notifyStopping()
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 	return &PhasedStopper{
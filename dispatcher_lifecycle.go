@@ -0,0 +1,88 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yarpc
+
+import "sync"
+
+// LifecycleObserver is notified as a Dispatcher starts and stops. Register
+// observers with the Dispatcher's ObserveLifecycle method.
+//
+// Observers are notified synchronously and must not block.
+type LifecycleObserver interface {
+	// DispatcherStarted is called once the Dispatcher has started its
+	// inbounds, with Start or PhasedStart, and is serving requests.
+	DispatcherStarted()
+
+	// DispatcherStopping is called when the Dispatcher begins to stop, with
+	// Stop or PhasedStop, before its inbounds are stopped.
+	DispatcherStopping()
+}
+
+// lifecycleObservers tracks the observers of a Dispatcher and how far along
+// its lifecycle the Dispatcher is.
+type lifecycleObservers struct {
+	mu        sync.Mutex
+	started   bool
+	stopping  bool
+	observers []LifecycleObserver
+}
+
+// ObserveLifecycle registers an observer to be notified as the Dispatcher
+// starts and stops. If the Dispatcher has already started or begun stopping,
+// the observer is notified immediately.
+func (d *Dispatcher) ObserveLifecycle(o LifecycleObserver) {
+	d.lifecycle.mu.Lock()
+	defer d.lifecycle.mu.Unlock()
+
+	d.lifecycle.observers = append(d.lifecycle.observers, o)
+	if d.lifecycle.started {
+		o.DispatcherStarted()
+	}
+	if d.lifecycle.stopping {
+		o.DispatcherStopping()
+	}
+}
+
+func (l *lifecycleObservers) notifyStarted() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.started || l.stopping {
+		return
+	}
+	l.started = true
+	for _, o := range l.observers {
+		o.DispatcherStarted()
+	}
+}
+
+func (l *lifecycleObservers) notifyStopping() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopping {
+		return
+	}
+	l.stopping = true
+	for _, o := range l.observers {
+		o.DispatcherStopping()
+	}
+}
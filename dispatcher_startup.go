@@ -21,9 +21,13 @@
 package yarpc
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
 
+	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/internal/errorsync"
 
@@ -106,6 +110,10 @@ func (s *PhasedStarter) StartOutbounds() error {
 // configured on the dispatcher, which allows any registered procedures to
 // begin receiving requests. It's safe to call concurrently, but all calls
 // after the first return an error.
+//
+// If the dispatcher is configured to wait for outbound readiness,
+// StartInbounds first waits for the peer lists of the outbounds to become
+// ready.
 func (s *PhasedStarter) StartInbounds() error {
 	if !s.transportsStarted.Load() || !s.outboundsStarted.Load() {
 		return errors.New("must start inbounds after transports and outbounds")
@@ -113,6 +121,9 @@ func (s *PhasedStarter) StartInbounds() error {
 	if s.inboundsStartInitiated.Swap(true) {
 		return errors.New("already began starting inbounds")
 	}
+	if err := s.waitForOutbounds(); err != nil {
+		return s.abort([]error{err})
+	}
 	s.log.Info("starting inbounds")
 	wait := errorsync.ErrorWaiter{}
 	for _, i := range s.dispatcher.inbounds {
@@ -122,9 +133,54 @@ func (s *PhasedStarter) StartInbounds() error {
 		return s.abort(errs)
 	}
 	s.log.Debug("started inbounds")
+	s.dispatcher.lifecycle.notifyStarted()
 	return nil
 }
 
+// waitForOutbounds waits for the peer lists of the outbounds to become ready,
+// if the dispatcher is configured to. It fails only if the dispatcher is
+// configured to fail when the outbounds are not ready in time.
+func (s *PhasedStarter) waitForOutbounds() error {
+	cfg := s.dispatcher.readiness
+	if !cfg.Enabled {
+		return nil
+	}
+	if len(s.dispatcher.unwaitable) > 0 {
+		s.log.Warn("cannot wait for outbounds whose peer lists do not support readiness",
+			zap.Strings("outbounds", s.dispatcher.unwaitable))
+	}
+	if len(s.dispatcher.readinessWaiters) == 0 {
+		return nil
+	}
+
+	s.log.Info("waiting for outbounds to become ready")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout())
+	defer cancel()
+
+	wait := errorsync.ErrorWaiter{}
+	for _, w := range s.dispatcher.readinessWaiters {
+		w := w
+		wait.Submit(func() error {
+			if err := w.waiter.WaitUntilReady(ctx, cfg.Readiness); err != nil {
+				return fmt.Errorf("%s outbound %q is not ready: %v", w.rpcType, w.outboundKey, err)
+			}
+			return nil
+		})
+	}
+	errs := wait.Wait()
+	switch {
+	case len(errs) == 0:
+		s.log.Debug("outbounds are ready")
+		return nil
+	case cfg.FailOnTimeout:
+		return multierr.Combine(errs...)
+	default:
+		s.log.Warn("outbounds are not ready, starting inbounds anyway",
+			zap.Error(multierr.Combine(errs...)))
+		return nil
+	}
+}
+
 func (s *PhasedStarter) start(lc transport.Lifecycle) func() error {
 	return func() error {
 		if lc == nil {
@@ -168,6 +224,65 @@ func (s *PhasedStarter) setRouters() {
 	s.log.Debug("set router for inbounds")
 }
 
+// outboundReadinessWaiter is a peer chooser of an outbound that can report
+// its readiness.
+type outboundReadinessWaiter struct {
+	outboundKey string
+	rpcType     transport.Type
+	waiter      peer.ReadinessWaiter
+}
+
+// collectReadinessWaiters collects the peer choosers of the given outbounds
+// that can report their readiness, and describes the outbounds with peer
+// choosers that cannot.
+func collectReadinessWaiters(outbounds Outbounds) ([]outboundReadinessWaiter, []string) {
+	type chooserOutbound interface {
+		Chooser() peer.Chooser
+	}
+
+	var (
+		waiters    []outboundReadinessWaiter
+		unwaitable []string
+	)
+	add := func(outboundKey string, rpcType transport.Type, o interface{}) {
+		co, ok := o.(chooserOutbound)
+		if !ok {
+			return
+		}
+		chooser := co.Chooser()
+		if w, ok := chooser.(peer.ReadinessWaiter); ok && supportsReadiness(chooser) {
+			waiters = append(waiters, outboundReadinessWaiter{
+				outboundKey: outboundKey,
+				rpcType:     rpcType,
+				waiter:      w,
+			})
+			return
+		}
+		unwaitable = append(unwaitable, fmt.Sprintf("%s outbound %q", rpcType, outboundKey))
+	}
+
+	for outboundKey, outs := range outbounds {
+		add(outboundKey, transport.Unary, outs.Unary)
+		add(outboundKey, transport.Oneway, outs.Oneway)
+		add(outboundKey, transport.Streaming, outs.Stream)
+	}
+	sort.Strings(unwaitable)
+	return waiters, unwaitable
+}
+
+// supportsReadiness returns whether the given peer chooser can report its
+// readiness, rather than consider itself ready because the peer list it is
+// bound to cannot.
+func supportsReadiness(chooser peer.Chooser) bool {
+	if bc, ok := chooser.(interface {
+		ChooserList() peer.ChooserList
+	}); ok {
+		_, ok := bc.ChooserList().(peer.ReadinessWaiter)
+		return ok
+	}
+	return true
+}
+
 // PhasedStopper is a more granular alternative to the Dispatcher's all-in-one
 // Stop method. Rather than stopping the inbounds, outbounds, and transports
 // in one call, it lets the user choose when to trigger each phase of
@@ -30,12 +30,18 @@ import (
 	"time"
 
 	. "go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/api/transport/transporttest"
 	"go.uber.org/yarpc/internal/introspection"
 	"go.uber.org/yarpc/internal/observability"
+	yarpcpeer "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/roundrobin"
 	"go.uber.org/yarpc/transport/http"
 	"go.uber.org/yarpc/transport/tchannel"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/yarpc/yarpctest"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -567,6 +573,66 @@ func TestPhasedStopRaces(t *testing.T) {
 	assert.Equal(t, 3*concurrency-3, int(errs.Load()), "wrong number of errors")
 }
 
+// recordingObserver is a LifecycleObserver that records the notifications
+// it receives.
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) DispatcherStarted()  { o.events = append(o.events, "started") }
+func (o *recordingObserver) DispatcherStopping() { o.events = append(o.events, "stopping") }
+
+func TestObserveLifecycle(t *testing.T) {
+	t.Run("start and stop", func(t *testing.T) {
+		d := basicDispatcher(t)
+		var o recordingObserver
+		d.ObserveLifecycle(&o)
+		assert.Empty(t, o.events, "notified before start")
+
+		require.NoError(t, d.Start(), "starting dispatcher failed")
+		assert.Equal(t, []string{"started"}, o.events)
+
+		require.NoError(t, d.Stop(), "stopping dispatcher failed")
+		assert.Equal(t, []string{"started", "stopping"}, o.events)
+	})
+
+	t.Run("phased start and stop", func(t *testing.T) {
+		d := basicDispatcher(t)
+		var o recordingObserver
+		d.ObserveLifecycle(&o)
+
+		starter, err := d.PhasedStart()
+		require.NoError(t, err, "constructing phased starter failed")
+		require.NoError(t, starter.StartTransports(), "starting transports failed")
+		require.NoError(t, starter.StartOutbounds(), "starting outbounds failed")
+		assert.Empty(t, o.events, "notified before inbounds started")
+		require.NoError(t, starter.StartInbounds(), "starting inbounds failed")
+		assert.Equal(t, []string{"started"}, o.events)
+
+		stopper, err := d.PhasedStop()
+		require.NoError(t, err, "constructing phased stopper failed")
+		assert.Equal(t, []string{"started", "stopping"}, o.events, "not notified before inbounds stopped")
+		require.NoError(t, multierr.Combine(
+			stopper.StopInbounds(),
+			stopper.StopOutbounds(),
+			stopper.StopTransports(),
+		), "phased shutdown failed")
+		assert.Equal(t, []string{"started", "stopping"}, o.events)
+	})
+
+	t.Run("observe after start", func(t *testing.T) {
+		d := basicDispatcher(t)
+		require.NoError(t, d.Start(), "starting dispatcher failed")
+
+		var o recordingObserver
+		d.ObserveLifecycle(&o)
+		assert.Equal(t, []string{"started"}, o.events)
+
+		require.NoError(t, d.Stop(), "stopping dispatcher failed")
+		assert.Equal(t, []string{"started", "stopping"}, o.events)
+	})
+}
+
 func TestNoOutboundsForService(t *testing.T) {
 	defer func() {
 		r := recover()
@@ -754,6 +820,96 @@ func TestObservabilityMiddlewareApplicationErrorLevel(t *testing.T) {
 
 }
 
+func TestPhasedStartOutboundReadiness(t *testing.T) {
+	newDispatcher := func(readiness OutboundReadinessConfig) (*Dispatcher, *yarpctest.FakeTransport, peer.Identifier) {
+		pid := hostport.PeerIdentifier("127.0.0.1:8080")
+		trans := yarpctest.NewFakeTransport(yarpctest.InitialConnectionStatus(peer.Unavailable))
+		chooser := yarpcpeer.Bind(roundrobin.New(trans), yarpcpeer.BindPeers([]peer.Identifier{pid}))
+		readiness.Enabled = true
+		return NewDispatcher(Config{
+			Name: "test",
+			Outbounds: Outbounds{
+				"their-service": {Unary: trans.NewOutbound(chooser)},
+			},
+			OutboundReadiness: readiness,
+		}), trans, pid
+	}
+
+	startOutbounds := func(t *testing.T, d *Dispatcher) *PhasedStarter {
+		starter, err := d.PhasedStart()
+		require.NoError(t, err, "constructing phased starter failed")
+		require.NoError(t, starter.StartTransports(), "starting transports failed")
+		require.NoError(t, starter.StartOutbounds(), "starting outbounds failed")
+		return starter
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		d, trans, pid := newDispatcher(OutboundReadinessConfig{FailOnTimeout: true})
+		starter := startOutbounds(t, d)
+		defer d.Stop()
+
+		started := make(chan error)
+		go func() { started <- starter.StartInbounds() }()
+
+		select {
+		case err := <-started:
+			t.Fatalf("inbounds started before outbounds were ready: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		trans.SimulateConnect(pid)
+		select {
+		case err := <-started:
+			assert.NoError(t, err, "starting inbounds failed")
+		case <-time.After(time.Second):
+			t.Fatal("inbounds did not start after outbounds became ready")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		d, _, _ := newDispatcher(OutboundReadinessConfig{Timeout: 10 * time.Millisecond})
+		starter := startOutbounds(t, d)
+		defer d.Stop()
+
+		assert.NoError(t, starter.StartInbounds(), "inbounds must start after the timeout")
+	})
+
+	t.Run("fail on timeout", func(t *testing.T) {
+		d, _, _ := newDispatcher(OutboundReadinessConfig{
+			Timeout:       10 * time.Millisecond,
+			FailOnTimeout: true,
+		})
+		starter := startOutbounds(t, d)
+
+		err := starter.StartInbounds()
+		require.Error(t, err, "inbounds must not start before outbounds are ready")
+		assert.Contains(t, err.Error(), `Unary outbound "their-service" is not ready`)
+	})
+
+	t.Run("unwaitable outbounds", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		trans := yarpctest.NewFakeTransport()
+		d := NewDispatcher(Config{
+			Name: "test",
+			Outbounds: Outbounds{
+				"their-service": {
+					Unary: trans.NewOutbound(yarpcpeer.NewSingle(hostport.PeerIdentifier("127.0.0.1:8080"), trans)),
+				},
+			},
+			OutboundReadiness: OutboundReadinessConfig{Enabled: true},
+			Logging:           LoggingConfig{Zap: zap.New(core)},
+		})
+		starter := startOutbounds(t, d)
+		defer d.Stop()
+
+		require.NoError(t, starter.StartInbounds())
+		entries := logs.FilterMessage("cannot wait for outbounds whose peer lists do not support readiness").AllUntimed()
+		require.Len(t, entries, 1)
+		assert.Equal(t, []interface{}{`Unary outbound "their-service"`},
+			entries[0].ContextMap()["outbounds"])
+	})
+}
+
 func TestDisableObservabilityMiddleware(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -793,6 +949,90 @@ func TestDisableObservabilityMiddleware(t *testing.T) {
 	assert.Equal(t, 0, logs.Len())
 }
 
+func TestAuthorizationMiddleware(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	allowed := &transport.Request{Service: "test", Caller: "friend", Procedure: "KeyValue::get"}
+	denied := &transport.Request{Service: "test", Caller: "stranger", Procedure: "KeyValue::get"}
+
+	h := transporttest.NewMockUnaryHandler(mockCtrl)
+	h.EXPECT().Handle(ctx, allowed, nil).Return(nil)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	dispatcher := NewDispatcher(Config{
+		Name:    "test",
+		Logging: LoggingConfig{Zap: zap.New(core)},
+		Authorization: AuthorizationConfig{
+			Rules: []AuthorizationRule{
+				{Action: AuthorizationAllow, Callers: []string{"friend"}},
+			},
+		},
+		DisableAutoObservabilityMiddleware: true,
+	})
+
+	mw := dispatcher.InboundMiddleware().Unary
+	require.NotNil(t, mw)
+
+	assert.NoError(t, mw.Handle(ctx, allowed, nil, h))
+	err := mw.Handle(ctx, denied, nil, h)
+	assert.Equal(t, yarpcerrors.CodePermissionDenied, yarpcerrors.FromError(err).Code())
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Denied unauthorized inbound request.", entries[0].Message)
+}
+
+func TestAuthorizationMiddlewareDefaultDeny(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tests := []struct {
+		desc     string
+		give     AuthorizationConfig
+		wantDeny bool
+	}{
+		{desc: "unspecified"},
+		{desc: "allow", give: AuthorizationConfig{Default: AuthorizationAllow}},
+		{desc: "deny", give: AuthorizationConfig{Default: AuthorizationDeny}, wantDeny: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dispatcher := NewDispatcher(Config{
+				Name:                               "test",
+				Authorization:                      tt.give,
+				DisableAutoObservabilityMiddleware: true,
+			})
+
+			mw := dispatcher.InboundMiddleware().Unary
+			if !tt.wantDeny {
+				assert.Nil(t, mw, "authorization should not be enforced")
+				return
+			}
+
+			require.NotNil(t, mw)
+			req := &transport.Request{Service: "test", Caller: "friend", Procedure: "KeyValue::get"}
+			err := mw.Handle(context.Background(), req, nil, transporttest.NewMockUnaryHandler(mockCtrl))
+			assert.Equal(t, yarpcerrors.CodePermissionDenied, yarpcerrors.FromError(err).Code())
+		})
+	}
+}
+
+func TestAuthorizationMiddlewareInvalidRules(t *testing.T) {
+	assert.Panics(t, func() {
+		NewDispatcher(Config{
+			Name: "test",
+			Authorization: AuthorizationConfig{
+				Rules: []AuthorizationRule{
+					{Action: AuthorizationAllow, Procedures: []string{"[bad"}},
+				},
+			},
+		})
+	})
+}
+
 func TestObservabilityConfig(t *testing.T) {
 	// Validate that we can start a dispatcher with various logging and metrics
 	// configs.
@@ -52,4 +52,31 @@
 //  dispatcher.Register(json.OnewayProcedure("setValue", SetValue))
 //  dispatcher.Register(json.OnewayProcedure("runTask", RunTask))
 //
+// To register a streaming JSON procedure, define functions in the format,
+//
+// 	f(stream *json.ServerStream) error
+//
+// and use the StreamProcedure function to build procedures to register
+// against a Router.
+//
+//  dispatcher.Register(json.StreamProcedure("watch", Watch))
+//
+// Handlers exchange messages with the Send and Receive methods of the stream.
+// Each message is a single newline-terminated JSON value.
+//
+// 	var req WatchRequest
+// 	if err := stream.Receive(&req); err != nil {
+// 		return err
+// 	}
+// 	return stream.Send(&WatchResponse{...})
+//
+// To open a stream, use CallStream. This requires a stream outbound, such as
+// the gRPC outbound, for the service.
+//
+// 	stream, err := client.CallStream(ctx, "watch")
+// 	err = stream.Send(&WatchRequest{...})
+// 	var res WatchResponse
+// 	err = stream.Receive(&res)
+// 	err = stream.Close()
+//
 package json
@@ -21,8 +21,6 @@
 package json
 
 import (
-	"context"
-	"encoding/json"
 	"reflect"
 
 	encodingapi "go.uber.org/yarpc/api/encoding"
@@ -30,109 +28,32 @@ import (
 	"go.uber.org/yarpc/pkg/errors"
 )
 
-// jsonHandler adapts a user-provided high-level handler into a transport-level
-// Handler.
+// jsonStreamHandler adapts a user-provided JSON stream handler into a
+// transport-level StreamHandler.
 //
 // The wrapped function must already be in the correct format:
 //
-// 	f(ctx context.Context, body $reqBody) ($resBody, error)
-type jsonHandler struct {
-	reader  requestReader
+// 	f(stream *json.ServerStream) error
+type jsonStreamHandler struct {
 	handler reflect.Value
 }
 
-func (h jsonHandler) Handle(ctx context.Context, treq *transport.Request, rw transport.ResponseWriter) error {
-	if err := errors.ExpectEncodings(treq, Encoding); err != nil {
+func (h jsonStreamHandler) HandleStream(stream *transport.ServerStream) error {
+	meta := stream.Request().Meta
+	if err := errors.ExpectEncodings(meta.ToRequest(), Encoding); err != nil {
 		return err
 	}
 
-	ctx, call := encodingapi.NewInboundCall(ctx)
-	if err := call.ReadFromRequest(treq); err != nil {
+	ctx, call := encodingapi.NewInboundCallWithOptions(stream.Context(), encodingapi.DisableResponseHeaders())
+	if err := call.ReadFromRequestMeta(meta); err != nil {
 		return err
 	}
 
-	reqBody, err := h.reader.Read(json.NewDecoder(treq.Body))
-	if err != nil {
-		return errors.RequestBodyDecodeError(treq, err)
-	}
-
-	results := h.handler.Call([]reflect.Value{reflect.ValueOf(ctx), reqBody})
-
-	if err := call.WriteToResponse(rw); err != nil {
+	results := h.handler.Call([]reflect.Value{
+		reflect.ValueOf(&ServerStream{ctx: ctx, stream: stream}),
+	})
+	if err, _ := results[0].Interface().(error); err != nil {
 		return err
 	}
-
-	// we want to return the appErr if it exists as this is what
-	// the previous behavior was so we deprioritize this error
-	var encodeErr error
-	if result := results[0].Interface(); result != nil {
-		if err := json.NewEncoder(rw).Encode(result); err != nil {
-			encodeErr = errors.ResponseBodyEncodeError(treq, err)
-		}
-	}
-
-	if appErr, _ := results[1].Interface().(error); appErr != nil {
-		rw.SetApplicationError()
-		return appErr
-	}
-
-	return encodeErr
-}
-
-func (h jsonHandler) HandleOneway(ctx context.Context, treq *transport.Request) error {
-	if err := errors.ExpectEncodings(treq, Encoding); err != nil {
-		return err
-	}
-
-	ctx, call := encodingapi.NewInboundCall(ctx)
-	if err := call.ReadFromRequest(treq); err != nil {
-		return err
-	}
-
-	reqBody, err := h.reader.Read(json.NewDecoder(treq.Body))
-	if err != nil {
-		return errors.RequestBodyDecodeError(treq, err)
-	}
-
-	results := h.handler.Call([]reflect.Value{reflect.ValueOf(ctx), reqBody})
-
-	if err := results[0].Interface(); err != nil {
-		return err.(error)
-	}
-
 	return nil
 }
-
-// requestReader is used to parse a JSON request argument from a JSON decoder.
-type requestReader interface {
-	Read(*json.Decoder) (reflect.Value, error)
-}
-
-type structReader struct {
-	// Type of the struct (not a pointer to the struct)
-	Type reflect.Type
-}
-
-func (r structReader) Read(d *json.Decoder) (reflect.Value, error) {
-	value := reflect.New(r.Type)
-	err := d.Decode(value.Interface())
-	return value, err
-}
-
-type mapReader struct {
-	Type reflect.Type // Type of the map
-}
-
-func (r mapReader) Read(d *json.Decoder) (reflect.Value, error) {
-	value := reflect.New(r.Type)
-	err := d.Decode(value.Interface())
-	return value.Elem(), err
-}
-
-type ifaceEmptyReader struct{}
-
-func (ifaceEmptyReader) Read(d *json.Decoder) (reflect.Value, error) {
-	value := reflect.New(_interfaceEmptyType)
-	err := d.Decode(value.Interface())
-	return value.Elem(), err
-}
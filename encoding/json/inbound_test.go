@@ -26,7 +26,6 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,10 +53,7 @@ func TestHandleStructSuccess(t *testing.T) {
 		return &simpleResponse{Success: true}, nil
 	}
 
-	handler := jsonHandler{
-		reader:  structReader{reflect.TypeOf(simpleRequest{})},
-		handler: reflect.ValueOf(h),
-	}
+	handler := wrapUnaryHandler("simpleCall", h)
 
 	resw := new(transporttest.FakeResponseWriter)
 	err := handler.Handle(context.Background(), &transport.Request{
@@ -81,10 +77,7 @@ func TestHandleMapSuccess(t *testing.T) {
 		return map[string]string{"success": "true"}, nil
 	}
 
-	handler := jsonHandler{
-		reader:  mapReader{reflect.TypeOf(make(map[string]interface{}))},
-		handler: reflect.ValueOf(h),
-	}
+	handler := wrapUnaryHandler("foo", h)
 
 	resw := new(transporttest.FakeResponseWriter)
 	err := handler.Handle(context.Background(), &transport.Request{
@@ -104,7 +97,7 @@ func TestHandleInterfaceEmptySuccess(t *testing.T) {
 		return body, nil
 	}
 
-	handler := jsonHandler{reader: ifaceEmptyReader{}, handler: reflect.ValueOf(h)}
+	handler := wrapUnaryHandler("foo", h)
 
 	resw := new(transporttest.FakeResponseWriter)
 	err := handler.Handle(context.Background(), &transport.Request{
@@ -123,10 +116,7 @@ func TestHandleSuccessWithResponseHeaders(t *testing.T) {
 		return &simpleResponse{Success: true}, nil
 	}
 
-	handler := jsonHandler{
-		reader:  structReader{reflect.TypeOf(simpleRequest{})},
-		handler: reflect.ValueOf(h),
-	}
+	handler := wrapUnaryHandler("simpleCall", h)
 
 	resw := new(transporttest.FakeResponseWriter)
 	err := handler.Handle(context.Background(), &transport.Request{
@@ -148,10 +138,7 @@ func TestHandleBothResponseError(t *testing.T) {
 		return &simpleResponse{Success: true}, errors.New("bar")
 	}
 
-	handler := jsonHandler{
-		reader:  structReader{reflect.TypeOf(simpleRequest{})},
-		handler: reflect.ValueOf(h),
-	}
+	handler := wrapUnaryHandler("simpleCall", h)
 
 	resw := new(transporttest.FakeResponseWriter)
 	err := handler.Handle(context.Background(), &transport.Request{
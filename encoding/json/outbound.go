@@ -21,15 +21,13 @@
 package json
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 
 	"go.uber.org/yarpc"
 	encodingapi "go.uber.org/yarpc/api/encoding"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/pkg/encoding"
-	"go.uber.org/yarpc/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // Client makes JSON requests to a single service.
@@ -42,86 +40,58 @@ type Client interface {
 	// Returns the response or an error if the request failed.
 	Call(ctx context.Context, procedure string, reqBody interface{}, resBodyOut interface{}, opts ...yarpc.CallOption) error
 	CallOneway(ctx context.Context, procedure string, reqBody interface{}, opts ...yarpc.CallOption) (transport.Ack, error)
+
+	// CallStream opens a stream to the given procedure. Messages are sent
+	// and received with the Send and Receive methods of the returned stream.
+	//
+	// The client must have been built from a ClientConfig with a stream
+	// outbound.
+	CallStream(ctx context.Context, procedure string, opts ...yarpc.CallOption) (*ClientStream, error)
 }
 
 // New builds a new JSON client.
 func New(c transport.ClientConfig) Client {
-	return jsonClient{cc: c}
+	return jsonClient{Client: encoding.NewClient(jsonCodec{}, c), cc: c}
 }
 
 func init() {
 	yarpc.RegisterClientBuilder(New)
 }
 
+// jsonClient makes unary and oneway requests with the JSON codec.
 type jsonClient struct {
+	encoding.Client
+
 	cc transport.ClientConfig
 }
 
-func (c jsonClient) Call(ctx context.Context, procedure string, reqBody interface{}, resBodyOut interface{}, opts ...yarpc.CallOption) error {
-	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
-	treq := transport.Request{
-		Caller:    c.cc.Caller(),
-		Service:   c.cc.Service(),
-		Procedure: procedure,
-		Encoding:  Encoding,
-	}
-
-	ctx, err := call.WriteToRequest(ctx, &treq)
-	if err != nil {
-		return err
+func (c jsonClient) CallStream(ctx context.Context, procedure string, opts ...yarpc.CallOption) (*ClientStream, error) {
+	// Only OutboundConfigs know about stream outbounds.
+	oc, ok := c.cc.(*transport.OutboundConfig)
+	if !ok || oc.Outbounds.Stream == nil {
+		return nil, yarpcerrors.InternalErrorf("no stream outbounds for service %q", c.cc.Service())
 	}
 
-	encoded, err := json.Marshal(reqBody)
+	call, err := encodingapi.NewStreamOutboundCall(encoding.FromOptions(opts)...)
 	if err != nil {
-		return errors.RequestBodyEncodeError(&treq, err)
-	}
-
-	treq.Body = bytes.NewReader(encoded)
-	tres, appErr := c.cc.GetUnaryOutbound().Call(ctx, &treq)
-	if tres == nil {
-		return appErr
-	}
-
-	// we want to return the appErr if it exists as this is what
-	// the previous behavior was so we deprioritize this error
-	var decodeErr error
-	if _, err = call.ReadFromResponse(ctx, tres); err != nil {
-		decodeErr = err
-	}
-	if tres.Body != nil {
-		if err := json.NewDecoder(tres.Body).Decode(resBodyOut); err != nil && decodeErr == nil {
-			decodeErr = errors.ResponseBodyDecodeError(&treq, err)
-		}
-		if err := tres.Body.Close(); err != nil && decodeErr == nil {
-			decodeErr = err
-		}
-	}
-
-	if appErr != nil {
-		return appErr
+		return nil, err
 	}
-	return decodeErr
-}
-
-func (c jsonClient) CallOneway(ctx context.Context, procedure string, reqBody interface{}, opts ...yarpc.CallOption) (transport.Ack, error) {
-	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
-	treq := transport.Request{
-		Caller:    c.cc.Caller(),
-		Service:   c.cc.Service(),
-		Procedure: procedure,
-		Encoding:  Encoding,
+	sreq := transport.StreamRequest{
+		Meta: &transport.RequestMeta{
+			Caller:    c.cc.Caller(),
+			Service:   c.cc.Service(),
+			Procedure: procedure,
+			Encoding:  Encoding,
+		},
 	}
-
-	ctx, err := call.WriteToRequest(ctx, &treq)
+	ctx, err = call.WriteToRequestMeta(ctx, sreq.Meta)
 	if err != nil {
 		return nil, err
 	}
 
-	var buff bytes.Buffer
-	if err := json.NewEncoder(&buff).Encode(reqBody); err != nil {
-		return nil, errors.RequestBodyEncodeError(&treq, err)
+	stream, err := oc.Outbounds.Stream.CallStream(ctx, &sreq)
+	if err != nil {
+		return nil, err
 	}
-	treq.Body = &buff
-
-	return c.cc.GetOnewayOutbound().CallOneway(ctx, &treq)
+	return &ClientStream{stream: stream}, nil
 }
@@ -198,7 +198,7 @@ func TestCallOneway(t *testing.T) {
 		{
 			procedure:      "foo",
 			body:           []string{"foo", "bar"},
-			encodedRequest: `["foo","bar"]` + "\n",
+			encodedRequest: `["foo","bar"]`,
 		},
 		{
 			procedure: "baz",
@@ -210,7 +210,7 @@ func TestCallOneway(t *testing.T) {
 			procedure:      "requestHeaders",
 			headers:        map[string]string{"user-id": "42"},
 			body:           map[string]interface{}{},
-			encodedRequest: "{}\n",
+			encodedRequest: "{}",
 		},
 	}
 
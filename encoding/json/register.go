@@ -21,17 +21,17 @@
 package json
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/pkg/encoding"
 )
 
 var (
-	_ctxType            = reflect.TypeOf((*context.Context)(nil)).Elem()
 	_errorType          = reflect.TypeOf((*error)(nil)).Elem()
 	_interfaceEmptyType = reflect.TypeOf((*interface{})(nil)).Elem()
+	_serverStreamType   = reflect.TypeOf((*ServerStream)(nil))
 )
 
 // Register calls the RouteTable's Register method.
@@ -80,128 +80,104 @@ func OnewayProcedure(name string, handler interface{}) []transport.Procedure {
 	}
 }
 
+// StreamProcedure builds a Procedure from the given JSON stream handler.
+// handler must be a function with the signature,
+//
+// 	f(stream *json.ServerStream) error
+//
+// Messages are sent and received on the stream with its Send and Receive
+// methods.
+func StreamProcedure(name string, handler interface{}) []transport.Procedure {
+	return []transport.Procedure{
+		{
+			Name: name,
+			HandlerSpec: transport.NewStreamHandlerSpec(
+				wrapStreamHandler(name, handler)),
+			Encoding: Encoding,
+		},
+	}
+}
+
 // wrapUnaryHandler takes a valid JSON handler function and converts it into a
 // transport.UnaryHandler.
 func wrapUnaryHandler(name string, handler interface{}) transport.UnaryHandler {
-	reqBodyType := verifyUnarySignature(name, reflect.TypeOf(handler))
-	return newJSONHandler(reqBodyType, handler)
+	procedures := encoding.Procedure(jsonCodec{}, name, handler)
+	verifyBodyTypes(name, reflect.TypeOf(handler))
+	return procedures[0].HandlerSpec.Unary()
 }
 
 // wrapOnewayHandler takes a valid JSON handler function and converts it into a
 // transport.OnewayHandler.
 func wrapOnewayHandler(name string, handler interface{}) transport.OnewayHandler {
-	reqBodyType := verifyOnewaySignature(name, reflect.TypeOf(handler))
-	return newJSONHandler(reqBodyType, handler)
+	procedures := encoding.OnewayProcedure(jsonCodec{}, name, handler)
+	verifyBodyTypes(name, reflect.TypeOf(handler))
+	return procedures[0].HandlerSpec.Oneway()
 }
 
-func newJSONHandler(reqBodyType reflect.Type, handler interface{}) jsonHandler {
-	var r requestReader
-	if reqBodyType == _interfaceEmptyType {
-		r = ifaceEmptyReader{}
-	} else if reqBodyType.Kind() == reflect.Map {
-		r = mapReader{reqBodyType}
-	} else {
-		// struct ptr
-		r = structReader{reqBodyType.Elem()}
-	}
-
-	return jsonHandler{
-		reader:  r,
-		handler: reflect.ValueOf(handler),
-	}
+// wrapStreamHandler takes a valid JSON stream handler function and converts it
+// into a transport.StreamHandler.
+func wrapStreamHandler(name string, handler interface{}) transport.StreamHandler {
+	verifyStreamSignature(name, reflect.TypeOf(handler))
+	return jsonStreamHandler{handler: reflect.ValueOf(handler)}
 }
 
-// verifyUnarySignature verifies that the given type matches what we expect from
-// JSON unary handlers and returns the request type.
-func verifyUnarySignature(n string, t reflect.Type) reflect.Type {
-	reqBodyType := verifyInputSignature(n, t)
-
-	if t.NumOut() != 2 {
+// verifyBodyTypes verifies that the request and response bodies of the given
+// handler type, which encoding.Procedure has already verified, are types that
+// JSON handlers accept.
+func verifyBodyTypes(n string, t reflect.Type) {
+	if reqBodyType := t.In(1); !isValidReqResType(reqBodyType) {
 		panic(fmt.Sprintf(
-			"expected handler for %q to have 2 results but it had %v",
-			n, t.NumOut(),
-		))
-	}
-
-	if t.Out(1) != _errorType {
-		panic(fmt.Sprintf(
-			"handler for %q must return error as its second reuslt, not %v",
-			n, t.Out(1),
+			"the second argument of the handler for %q must be "+
+				"a struct pointer, a map[string]interface{}, or interface{}, and not: %v",
+			n, reqBodyType,
 		))
 	}
 
-	resBodyType := t.Out(0)
-
-	if !isValidReqResType(resBodyType) {
+	if t.NumOut() == 2 && !isValidReqResType(t.Out(0)) {
 		panic(fmt.Sprintf(
 			"the first result of the handler for %q must be "+
-				"a struct pointer, a map[string]interface{}, or interface{], and not: %v",
-			n, resBodyType,
-		))
-	}
-
-	return reqBodyType
-}
-
-// verifyOnewaySignature verifies that the given type matches what we expect
-// from oneway JSON handlers.
-//
-// Returns the request type.
-func verifyOnewaySignature(n string, t reflect.Type) reflect.Type {
-	reqBodyType := verifyInputSignature(n, t)
-
-	if t.NumOut() != 1 {
-		panic(fmt.Sprintf(
-			"expected handler for %q to have 1 result but it had %v",
-			n, t.NumOut(),
-		))
-	}
-
-	if t.Out(0) != _errorType {
-		panic(fmt.Sprintf(
-			"the result of the handler for %q must be of type error, and not: %v",
+				"a struct pointer, a map[string]interface{}, or interface{}, and not: %v",
 			n, t.Out(0),
 		))
 	}
-
-	return reqBodyType
 }
 
-// verifyInputSignature verifies that the given input argument types match
-// what we expect from JSON handlers and returns the request body type.
-func verifyInputSignature(n string, t reflect.Type) reflect.Type {
+// verifyStreamSignature verifies that the given type matches what we expect
+// from JSON stream handlers.
+func verifyStreamSignature(n string, t reflect.Type) {
 	if t.Kind() != reflect.Func {
 		panic(fmt.Sprintf(
 			"handler for %q is not a function but a %v", n, t.Kind(),
 		))
 	}
 
-	if t.NumIn() != 2 {
+	if t.NumIn() != 1 {
 		panic(fmt.Sprintf(
-			"expected handler for %q to have 2 arguments but it had %v",
+			"expected handler for %q to have 1 argument but it had %v",
 			n, t.NumIn(),
 		))
 	}
 
-	if t.In(0) != _ctxType {
+	if t.In(0) != _serverStreamType {
 		panic(fmt.Sprintf(
-			"the first argument of the handler for %q must be of type "+
-				"context.Context, and not: %v", n, t.In(0),
+			"the argument of the handler for %q must be of type "+
+				"*json.ServerStream, and not: %v", n, t.In(0),
 		))
-
 	}
 
-	reqBodyType := t.In(1)
-
-	if !isValidReqResType(reqBodyType) {
+	if t.NumOut() != 1 {
 		panic(fmt.Sprintf(
-			"the second argument of the handler for %q must be "+
-				"a struct pointer, a map[string]interface{}, or interface{}, and not: %v",
-			n, reqBodyType,
+			"expected handler for %q to have 1 result but it had %v",
+			n, t.NumOut(),
 		))
 	}
 
-	return reqBodyType
+	if t.Out(0) != _errorType {
+		panic(fmt.Sprintf(
+			"the result of the handler for %q must be of type error, and not: %v",
+			n, t.Out(0),
+		))
+	}
 }
 
 // isValidReqResType checks if the given type is a pointer to a struct, a
@@ -219,3 +219,51 @@ func TestWrapOnewayHandlerValid(t *testing.T) {
 		wrapOnewayHandler(tt.Name, tt.Func)
 	}
 }
+
+func TestWrapStreamHandlerInvalid(t *testing.T) {
+	tests := []struct {
+		Name string
+		Func interface{}
+	}{
+		{"empty", func() {}},
+		{"not-a-function", 0},
+		{
+			"wrong-arg",
+			func(context.Context) error {
+				return nil
+			},
+		},
+		{
+			"too-many-args",
+			func(context.Context, *ServerStream) error {
+				return nil
+			},
+		},
+		{
+			"non-pointer-stream",
+			func(ServerStream) error {
+				return nil
+			},
+		},
+		{
+			"no-response",
+			func(*ServerStream) {},
+		},
+		{
+			"wrong-response",
+			func(*ServerStream) int {
+				return 0
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Panics(t, assert.PanicTestFunc(func() {
+			wrapStreamHandler(tt.Name, tt.Func)
+		}), tt.Name)
+	}
+}
+
+func TestWrapStreamHandlerValid(t *testing.T) {
+	wrapStreamHandler("foo", func(*ServerStream) error { return nil })
+}
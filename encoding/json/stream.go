@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// ClientStream is a JSON-specific client stream.
+type ClientStream struct {
+	stream *transport.ClientStream
+}
+
+// Context returns the context of the stream.
+func (c *ClientStream) Context() context.Context {
+	return c.stream.Context()
+}
+
+// Receive receives the next message from the server and decodes it into
+// resBodyOut, which must be a value that can be filled with json.Unmarshal.
+//
+// Returns io.EOF when the server has closed the stream.
+func (c *ClientStream) Receive(resBodyOut interface{}, options ...yarpc.StreamOption) error {
+	return readFromStream(context.Background(), c.stream, resBodyOut)
+}
+
+// Send encodes the given message as JSON and sends it to the server.
+func (c *ClientStream) Send(reqBody interface{}, options ...yarpc.StreamOption) error {
+	return writeToStream(context.Background(), c.stream, reqBody)
+}
+
+// Close closes the stream.
+func (c *ClientStream) Close(options ...yarpc.StreamOption) error {
+	return c.stream.Close(context.Background())
+}
+
+// ServerStream is a JSON-specific server stream.
+type ServerStream struct {
+	ctx    context.Context
+	stream *transport.ServerStream
+}
+
+// Context returns the context of the stream.
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// Receive receives the next message from the client and decodes it into
+// reqBodyOut, which must be a value that can be filled with json.Unmarshal.
+//
+// Returns io.EOF when the client has closed the stream.
+func (s *ServerStream) Receive(reqBodyOut interface{}, options ...yarpc.StreamOption) error {
+	return readFromStream(context.Background(), s.stream, reqBodyOut)
+}
+
+// Send encodes the given message as JSON and sends it to the client.
+func (s *ServerStream) Send(resBody interface{}, options ...yarpc.StreamOption) error {
+	return writeToStream(context.Background(), s.stream, resBody)
+}
+
+// readFromStream receives a message from the stream and decodes it into out.
+//
+// Each stream message holds exactly one newline-terminated JSON value.
+func readFromStream(ctx context.Context, stream transport.Stream, out interface{}) error {
+	msg, err := stream.ReceiveMessage(ctx)
+	if err != nil {
+		return err
+	}
+	if msg.Body == nil {
+		return json.Unmarshal(nil, out)
+	}
+	defer msg.Body.Close()
+	return json.NewDecoder(msg.Body).Decode(out)
+}
+
+// writeToStream encodes the body as a newline-terminated JSON value and sends
+// it as a single message on the stream.
+func writeToStream(ctx context.Context, stream transport.Stream, body interface{}) error {
+	var buff bytes.Buffer
+	if err := json.NewEncoder(&buff).Encode(body); err != nil {
+		return err
+	}
+	return stream.SendMessage(ctx, &transport.StreamMessage{
+		Body: ioutil.NopCloser(&buff),
+	})
+}
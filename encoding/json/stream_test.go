@@ -0,0 +1,161 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package json
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/internal/clientconfig"
+)
+
+type streamMessage struct {
+	Message string `json:"message"`
+}
+
+// fakeStreamOutbound is a stream outbound that opens streams with a function.
+type fakeStreamOutbound struct {
+	transport.StreamOutbound
+
+	callStream func(context.Context, *transport.StreamRequest) (*transport.ClientStream, error)
+}
+
+func (o fakeStreamOutbound) CallStream(ctx context.Context, req *transport.StreamRequest) (*transport.ClientStream, error) {
+	return o.callStream(ctx, req)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	procedures := StreamProcedure("echo", func(stream *ServerStream) error {
+		for {
+			var req streamMessage
+			if err := stream.Receive(&req); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := stream.Send(&streamMessage{Message: "echo " + req.Message}); err != nil {
+				return err
+			}
+		}
+	})
+	require.Len(t, procedures, 1)
+	assert.Equal(t, transport.Streaming, procedures[0].HandlerSpec.Type())
+
+	serverErr := make(chan error, 1)
+	outbound := fakeStreamOutbound{
+		callStream: func(ctx context.Context, req *transport.StreamRequest) (*transport.ClientStream, error) {
+			assert.Equal(t, "caller", req.Meta.Caller)
+			assert.Equal(t, "service", req.Meta.Service)
+			assert.Equal(t, "echo", req.Meta.Procedure)
+			assert.Equal(t, Encoding, req.Meta.Encoding)
+
+			clientEnd, serverEnd := transporttest.NewStreamPipe(ctx, req)
+			serverStream, err := transport.NewServerStream(serverEnd)
+			if err != nil {
+				return nil, err
+			}
+			go func() {
+				err := procedures[0].HandlerSpec.Stream().HandleStream(serverStream)
+				serverEnd.Close(ctx)
+				serverErr <- err
+			}()
+			return transport.NewClientStream(clientEnd)
+		},
+	}
+
+	client := New(&transport.OutboundConfig{
+		CallerName: "caller",
+		Outbounds: transport.Outbounds{
+			ServiceName: "service",
+			Stream:      outbound,
+		},
+	})
+	stream, err := client.CallStream(ctx, "echo")
+	require.NoError(t, err)
+
+	for _, msg := range []string{"foo", "bar"} {
+		require.NoError(t, stream.Send(&streamMessage{Message: msg}))
+		var res streamMessage
+		require.NoError(t, stream.Receive(&res))
+		assert.Equal(t, "echo "+msg, res.Message)
+	}
+
+	require.NoError(t, stream.Close())
+	var res streamMessage
+	assert.Equal(t, io.EOF, stream.Receive(&res))
+	assert.NoError(t, <-serverErr)
+}
+
+func TestStreamHandlerError(t *testing.T) {
+	wantErr := errors.New("great sadness")
+	handler := wrapStreamHandler("fail", func(*ServerStream) error { return wantErr })
+
+	_, serverEnd := transporttest.NewStreamPipe(context.Background(), &transport.StreamRequest{
+		Meta: &transport.RequestMeta{
+			Caller:    "caller",
+			Service:   "service",
+			Procedure: "fail",
+			Encoding:  Encoding,
+		},
+	})
+	stream, err := transport.NewServerStream(serverEnd)
+	require.NoError(t, err)
+	assert.Equal(t, wantErr, handler.HandleStream(stream))
+}
+
+func TestStreamHandlerWrongEncoding(t *testing.T) {
+	handler := wrapStreamHandler("foo", func(*ServerStream) error {
+		t.Fatal("handler must not be called")
+		return nil
+	})
+
+	_, serverEnd := transporttest.NewStreamPipe(context.Background(), &transport.StreamRequest{
+		Meta: &transport.RequestMeta{
+			Caller:    "caller",
+			Service:   "service",
+			Procedure: "foo",
+			Encoding:  "raw",
+		},
+	})
+	stream, err := transport.NewServerStream(serverEnd)
+	require.NoError(t, err)
+	assert.Error(t, handler.HandleStream(stream))
+}
+
+func TestCallStreamWithoutStreamOutbound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := New(clientconfig.MultiOutbound("caller", "service",
+		transport.Outbounds{
+			Unary: transporttest.NewMockUnaryOutbound(mockCtrl),
+		}))
+	_, err := client.CallStream(context.Background(), "foo")
+	assert.Error(t, err)
+}
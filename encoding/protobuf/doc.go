@@ -54,6 +54,13 @@
 // are created for every RPC method: one that will handle the standard Protobuf
 // binary encoding, and one that will handle the JSON encoding.
 //
+// The JSON wire format may be customized with JSONOptions, such as
+// JSONOrigName or JSONEmitDefaults, passed as ClientOptions to clients and as
+// BuildProceduresOptions to BuildBarYARPCProcedures.
+//
+//   barClient := foo.NewBarYARPCClient(clientConfig, protobuf.UseJSON, protobuf.JSONOrigName(true))
+//   dispatcher.Register(foo.BuildBarYARPCProcedures(barServer, protobuf.JSONOrigName(true)))
+//
 // If coupled with an HTTP Inbound, Protobuf procedures can be called using
 // curl. Given the following Protobuf definition:
 //
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package protobuf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	golangproto "github.com/golang/protobuf/proto"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const _typeURLPrefix = "type.googleapis.com/"
+
+// NewErrorDetail returns a yarpcerrors.Detail holding the given protobuf
+// message, like a google.rpc.BadRequest or google.rpc.RetryInfo, to be
+// attached to an error with yarpcerrors.Status.WithDetails.
+//
+//  detail, err := protobuf.NewErrorDetail(&errdetails.RetryInfo{...})
+//  if err != nil {
+//    return nil, err
+//  }
+//  return nil, yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "try again later").WithDetails(detail)
+//
+// The message type must be registered with either the gogo/protobuf or the
+// golang/protobuf registry, as generated code does.
+func NewErrorDetail(message proto.Message) (yarpcerrors.Detail, error) {
+	name := messageName(message)
+	if name == "" {
+		return yarpcerrors.Detail{}, fmt.Errorf("protobuf message type %T is not registered", message)
+	}
+	value, err := proto.Marshal(message)
+	if err != nil {
+		return yarpcerrors.Detail{}, err
+	}
+	return yarpcerrors.Detail{TypeURL: _typeURLPrefix + name, Value: value}, nil
+}
+
+// GetErrorDetails returns the protobuf messages held in the details of the
+// given error, if it is a yarpcerrors.Status.
+//
+//  for _, detail := range protobuf.GetErrorDetails(err) {
+//    switch d := detail.(type) {
+//    case *errdetails.RetryInfo:
+//      ...
+//    }
+//  }
+//
+// Details whose type is not a registered protobuf message, or that cannot be
+// decoded, are returned as errors in their place.
+func GetErrorDetails(err error) []interface{} {
+	if !yarpcerrors.IsStatus(err) {
+		return nil
+	}
+	details := yarpcerrors.FromError(err).Details()
+	if len(details) == 0 {
+		return nil
+	}
+
+	messages := make([]interface{}, len(details))
+	for i, d := range details {
+		message, err := decodeErrorDetail(d)
+		if err != nil {
+			messages[i] = err
+			continue
+		}
+		messages[i] = message
+	}
+	return messages
+}
+
+func decodeErrorDetail(d yarpcerrors.Detail) (proto.Message, error) {
+	name := d.TypeURL[strings.LastIndex(d.TypeURL, "/")+1:]
+	t := messageType(name)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("error detail has unknown protobuf message type %q", d.TypeURL)
+	}
+	message, ok := reflect.New(t.Elem()).Interface().(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("error detail type %q is not a protobuf message", d.TypeURL)
+	}
+	if err := proto.Unmarshal(d.Value, message); err != nil {
+		return nil, fmt.Errorf("failed to decode error detail of type %q: %v", d.TypeURL, err)
+	}
+	return message, nil
+}
+
+// messageName returns the name of the message type from the gogo/protobuf
+// registry, falling back to the golang/protobuf registry.
+func messageName(message proto.Message) string {
+	if name := proto.MessageName(message); name != "" {
+		return name
+	}
+	return golangproto.MessageName(message)
+}
+
+// messageType returns the type of the named message from the gogo/protobuf
+// registry, falling back to the golang/protobuf registry.
+func messageType(name string) reflect.Type {
+	if t := proto.MessageType(name); t != nil {
+		return t
+	}
+	return golangproto.MessageType(name)
+}
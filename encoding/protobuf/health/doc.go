@@ -0,0 +1,39 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package health implements the gRPC health checking protocol for a
+// Dispatcher, so that load balancers and orchestrators can probe services
+// served by a gRPC inbound.
+//
+// The health Server follows the lifecycle of its Dispatcher: services are
+// reported SERVING only once the Dispatcher has started, and NOT_SERVING as
+// soon as it begins to stop.
+//
+// 	server := health.NewServer(dispatcher)
+// 	dispatcher.Register(server.Procedures())
+//
+// The status of individual services may be overridden while the Dispatcher
+// is running.
+//
+// 	server.SetServingStatus("keyvalue.KeyValue", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+//
+// For more information on gRPC health checking, see
+// https://github.com/grpc/grpc/blob/master/doc/health-checking.md
+package health
@@ -0,0 +1,280 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/pkg/procedure"
+	"go.uber.org/yarpc/yarpcerrors"
+	hpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// ServiceName is the fully qualified name of the gRPC health service.
+	ServiceName = "grpc.health.v1.Health"
+
+	_checkMethodName = "Check"
+	_watchMethodName = "Watch"
+)
+
+var _emptyRequest = &hpb.HealthCheckRequest{}
+
+var _ yarpc.LifecycleObserver = (*Server)(nil)
+
+// Server implements the grpc.health.v1.Health service for a Dispatcher.
+//
+// The Server reports NOT_SERVING for every service until the Dispatcher has
+// started, and again as soon as the Dispatcher begins to stop. In between,
+// services report SERVING unless their status is overridden with
+// SetServingStatus.
+//
+// Watch streams end after reporting NOT_SERVING once the Dispatcher begins to
+// stop, so that they do not hold up a graceful shutdown of its inbounds.
+//
+// 	server := health.NewServer(dispatcher)
+// 	dispatcher.Register(server.Procedures())
+type Server struct {
+	router transport.Router
+
+	mu        sync.Mutex
+	serving   bool
+	stopping  bool
+	overrides map[string]hpb.HealthCheckResponse_ServingStatus
+
+	// Watch streams are notified of every change in status over these
+	// channels, and send a response if the status of their service changed.
+	watchers map[chan struct{}]struct{}
+}
+
+// NewServer builds a new health Server for the given Dispatcher. The Server
+// tracks the lifecycle of the Dispatcher, and knows about every service with
+// procedures registered on it.
+func NewServer(d *yarpc.Dispatcher) *Server {
+	s := &Server{
+		router:    d.Router(),
+		overrides: make(map[string]hpb.HealthCheckResponse_ServingStatus),
+		watchers:  make(map[chan struct{}]struct{}),
+	}
+	d.ObserveLifecycle(s)
+	return s
+}
+
+// Procedures returns the procedures of the health service, to be registered
+// on the Dispatcher.
+func (s *Server) Procedures() []transport.Procedure {
+	return protobuf.BuildProcedures(
+		protobuf.BuildProceduresParams{
+			ServiceName: ServiceName,
+			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{
+				{
+					MethodName: _checkMethodName,
+					Handler: protobuf.NewUnaryHandler(
+						protobuf.UnaryHandlerParams{
+							Handle:     s.check,
+							NewRequest: newHealthCheckRequest,
+						},
+					),
+				},
+			},
+			StreamHandlerParams: []protobuf.BuildProceduresStreamHandlerParams{
+				{
+					MethodName: _watchMethodName,
+					Handler: protobuf.NewStreamHandler(
+						protobuf.StreamHandlerParams{
+							Handle: s.watch,
+						},
+					),
+				},
+			},
+		},
+	)
+}
+
+// SetServingStatus overrides the status reported for the given service while
+// the Dispatcher is running. The empty service name refers to the health of
+// the Dispatcher as a whole.
+//
+// Overrides do not apply before the Dispatcher has started or once it has
+// begun to stop; the service is reported NOT_SERVING then.
+func (s *Server) SetServingStatus(service string, status hpb.HealthCheckResponse_ServingStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overrides[service] = status
+	s.notify()
+}
+
+// ClearServingStatus removes the override for the given service, if any.
+func (s *Server) ClearServingStatus(service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.overrides, service)
+	s.notify()
+}
+
+// DispatcherStarted marks all services as serving. It is called by the
+// Dispatcher and should not be called directly.
+func (s *Server) DispatcherStarted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serving = true
+	s.stopping = false
+	s.notify()
+}
+
+// DispatcherStopping marks all services as not serving. It is called by the
+// Dispatcher and should not be called directly.
+func (s *Server) DispatcherStopping() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serving = false
+	s.stopping = true
+	s.notify()
+}
+
+// notify wakes up all Watch streams. It must be called with the lock held.
+func (s *Server) notify() {
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A notification is already pending for this watcher.
+		}
+	}
+}
+
+// status returns the status of the given service, and whether the service is
+// known to the Server.
+func (s *Server) status(service string) (hpb.HealthCheckResponse_ServingStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.statusLocked(service)
+}
+
+// watchStatus returns the status of the given service, and whether the
+// Dispatcher is stopping.
+func (s *Server) watchStatus(service string) (status hpb.HealthCheckResponse_ServingStatus, stopping bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, _ = s.statusLocked(service)
+	return status, s.stopping
+}
+
+// statusLocked is status with the lock held.
+func (s *Server) statusLocked(service string) (hpb.HealthCheckResponse_ServingStatus, bool) {
+	override, ok := s.overrides[service]
+	if !ok && service != "" && !s.hasProcedures(service) {
+		return hpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+	switch {
+	case !s.serving:
+		return hpb.HealthCheckResponse_NOT_SERVING, true
+	case ok:
+		return override, true
+	default:
+		return hpb.HealthCheckResponse_SERVING, true
+	}
+}
+
+// hasProcedures returns whether any procedure of the given service is
+// registered on the Dispatcher.
+func (s *Server) hasProcedures(service string) bool {
+	for _, p := range s.router.Procedures() {
+		if name, _ := procedure.FromName(p.Name); name == service {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) check(ctx context.Context, message proto.Message) (proto.Message, error) {
+	request, ok := message.(*hpb.HealthCheckRequest)
+	if !ok {
+		return nil, protobuf.CastError(_emptyRequest, message)
+	}
+	status, ok := s.status(request.Service)
+	if !ok {
+		return nil, yarpcerrors.NotFoundErrorf("unknown service %q", request.Service)
+	}
+	return &hpb.HealthCheckResponse{Status: status}, nil
+}
+
+func (s *Server) watch(stream *protobuf.ServerStream) error {
+	message, err := stream.Receive(newHealthCheckRequest)
+	if err != nil {
+		return err
+	}
+	request, ok := message.(*hpb.HealthCheckRequest)
+	if !ok {
+		return protobuf.CastError(_emptyRequest, message)
+	}
+
+	changed := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.watchers[changed] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, changed)
+		s.mu.Unlock()
+	}()
+
+	// Unknown services are reported as SERVICE_UNKNOWN rather than failing
+	// the stream, since they may become known later.
+	status, stopping := s.watchStatus(request.Service)
+	if err := stream.Send(&hpb.HealthCheckResponse{Status: status}); err != nil {
+		return err
+	}
+	// The stream ends once the Dispatcher is stopping, since inbounds wait
+	// for open streams before they stop.
+	for !stopping {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changed:
+		}
+		var newStatus hpb.HealthCheckResponse_ServingStatus
+		newStatus, stopping = s.watchStatus(request.Service)
+		if newStatus == status {
+			continue
+		}
+		status = newStatus
+		if err := stream.Send(&hpb.HealthCheckResponse{Status: status}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newHealthCheckRequest() proto.Message {
+	return &hpb.HealthCheckRequest{}
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/encoding/raw"
+	"go.uber.org/yarpc/internal/testtime"
+	"go.uber.org/yarpc/transport/grpc"
+	"go.uber.org/yarpc/yarpcerrors"
+	ggrpc "google.golang.org/grpc"
+	hpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newTestServer(t *testing.T) (*yarpc.Dispatcher, *Server) {
+	d := yarpc.NewDispatcher(yarpc.Config{Name: "test"})
+	d.Register(raw.Procedure("keyvalue.KeyValue::Get", func(ctx context.Context, body []byte) ([]byte, error) {
+		return body, nil
+	}))
+	server := NewServer(d)
+	d.Register(server.Procedures())
+	return d, server
+}
+
+func check(server *Server, service string) (hpb.HealthCheckResponse_ServingStatus, error) {
+	response, err := server.check(context.Background(), &hpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return hpb.HealthCheckResponse_UNKNOWN, err
+	}
+	return response.(*hpb.HealthCheckResponse).Status, nil
+}
+
+func assertStatus(t *testing.T, server *Server, service string, want hpb.HealthCheckResponse_ServingStatus) {
+	status, err := check(server, service)
+	if assert.NoError(t, err, "check %q failed", service) {
+		assert.Equal(t, want, status, "wrong status for %q", service)
+	}
+}
+
+func TestProcedures(t *testing.T) {
+	_, server := newTestServer(t)
+	types := make(map[string]transport.Type)
+	for _, p := range server.Procedures() {
+		if p.Encoding == protobuf.Encoding {
+			types[p.Name] = p.HandlerSpec.Type()
+		}
+	}
+	assert.Equal(t, map[string]transport.Type{
+		"grpc.health.v1.Health::Check": transport.Unary,
+		"grpc.health.v1.Health::Watch": transport.Streaming,
+	}, types)
+}
+
+func TestCheck(t *testing.T) {
+	d, server := newTestServer(t)
+
+	// Not serving until the dispatcher has started.
+	assertStatus(t, server, "", hpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, server, "keyvalue.KeyValue", hpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, server, ServiceName, hpb.HealthCheckResponse_NOT_SERVING)
+
+	_, err := check(server, "unknown.Service")
+	assert.True(t, yarpcerrors.IsNotFound(err), "expected a not found error, got %v", err)
+
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+	assertStatus(t, server, "", hpb.HealthCheckResponse_SERVING)
+	assertStatus(t, server, "keyvalue.KeyValue", hpb.HealthCheckResponse_SERVING)
+
+	server.SetServingStatus("keyvalue.KeyValue", hpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, server, "", hpb.HealthCheckResponse_SERVING)
+	assertStatus(t, server, "keyvalue.KeyValue", hpb.HealthCheckResponse_NOT_SERVING)
+
+	server.ClearServingStatus("keyvalue.KeyValue")
+	assertStatus(t, server, "keyvalue.KeyValue", hpb.HealthCheckResponse_SERVING)
+
+	// Services become known once their status is set.
+	server.SetServingStatus("unknown.Service", hpb.HealthCheckResponse_SERVING)
+	assertStatus(t, server, "unknown.Service", hpb.HealthCheckResponse_SERVING)
+
+	// Overrides do not apply once the dispatcher is stopping.
+	require.NoError(t, d.Stop(), "stopping dispatcher failed")
+	assertStatus(t, server, "", hpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, server, "unknown.Service", hpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestCheckPhasedStop(t *testing.T) {
+	d, server := newTestServer(t)
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+	assertStatus(t, server, "", hpb.HealthCheckResponse_SERVING)
+
+	stopper, err := d.PhasedStop()
+	require.NoError(t, err, "constructing phased stopper failed")
+
+	// The inbounds are still running, but health checks already fail.
+	assertStatus(t, server, "", hpb.HealthCheckResponse_NOT_SERVING)
+	require.NoError(t, stopper.StopInbounds(), "stopping inbounds failed")
+	require.NoError(t, stopper.StopOutbounds(), "stopping outbounds failed")
+	require.NoError(t, stopper.StopTransports(), "stopping transports failed")
+}
+
+type readCloser struct{ io.Reader }
+
+func (readCloser) Close() error { return nil }
+
+// watch starts a Watch stream for the given service, returning the client
+// end of the stream and a channel that receives the result of the handler.
+func watch(ctx context.Context, t *testing.T, server *Server, service string) (*transporttest.StreamPipe, <-chan error) {
+	var spec transport.HandlerSpec
+	for _, p := range server.Procedures() {
+		if p.Encoding == protobuf.Encoding && p.Name == "grpc.health.v1.Health::Watch" {
+			spec = p.HandlerSpec
+		}
+	}
+	require.Equal(t, transport.Streaming, spec.Type())
+
+	req := &transport.StreamRequest{
+		Meta: &transport.RequestMeta{
+			Caller:    "prober",
+			Service:   "test",
+			Procedure: "grpc.health.v1.Health::Watch",
+			Encoding:  protobuf.Encoding,
+		},
+	}
+	clientEnd, serverEnd := transporttest.NewStreamPipe(ctx, req)
+
+	serverStream, err := transport.NewServerStream(serverEnd)
+	require.NoError(t, err)
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- spec.Stream().HandleStream(serverStream) }()
+
+	body, err := proto.Marshal(&hpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	require.NoError(t, clientEnd.SendMessage(ctx, &transport.StreamMessage{
+		Body: readCloser{bytes.NewReader(body)},
+	}))
+	require.NoError(t, clientEnd.Close(ctx))
+	return clientEnd, serverErr
+}
+
+func receiveStatus(t *testing.T, stream *transporttest.StreamPipe) hpb.HealthCheckResponse_ServingStatus {
+	msg, err := stream.ReceiveMessage(stream.Context())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(msg.Body)
+	require.NoError(t, err)
+	var response hpb.HealthCheckResponse
+	require.NoError(t, proto.Unmarshal(buf.Bytes(), &response))
+	return response.Status
+}
+
+func TestWatch(t *testing.T) {
+	d, server := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, serverErr := watch(ctx, t, server, "keyvalue.KeyValue")
+
+	assert.Equal(t, hpb.HealthCheckResponse_NOT_SERVING, receiveStatus(t, stream))
+
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+	assert.Equal(t, hpb.HealthCheckResponse_SERVING, receiveStatus(t, stream))
+
+	// Changes that do not affect the status of the service are not sent.
+	server.SetServingStatus("keyvalue.KeyValue", hpb.HealthCheckResponse_SERVING)
+	server.SetServingStatus("", hpb.HealthCheckResponse_NOT_SERVING)
+	server.SetServingStatus("keyvalue.KeyValue", hpb.HealthCheckResponse_NOT_SERVING)
+	assert.Equal(t, hpb.HealthCheckResponse_NOT_SERVING, receiveStatus(t, stream))
+
+	server.ClearServingStatus("keyvalue.KeyValue")
+	assert.Equal(t, hpb.HealthCheckResponse_SERVING, receiveStatus(t, stream))
+
+	// The stream ends once the dispatcher is stopping.
+	require.NoError(t, d.Stop(), "stopping dispatcher failed")
+	assert.Equal(t, hpb.HealthCheckResponse_NOT_SERVING, receiveStatus(t, stream))
+	assert.NoError(t, <-serverErr)
+	cancel()
+}
+
+func TestWatchGracefulStop(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "listening failed")
+	inbound := grpc.NewTransport().NewInbound(listener)
+	d := yarpc.NewDispatcher(yarpc.Config{
+		Name:     "test",
+		Inbounds: yarpc.Inbounds{inbound},
+	})
+	d.Register(raw.Procedure("keyvalue.KeyValue::Get", func(ctx context.Context, body []byte) ([]byte, error) {
+		return body, nil
+	}))
+	server := NewServer(d)
+	d.Register(server.Procedures())
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+
+	outbound := grpc.NewTransport().NewSingleOutbound(inbound.Addr().String())
+	clientDispatcher := yarpc.NewDispatcher(yarpc.Config{
+		Name: "prober",
+		Outbounds: yarpc.Outbounds{
+			"test": {Unary: outbound, Stream: outbound},
+		},
+	})
+	require.NoError(t, clientDispatcher.Start(), "starting client dispatcher failed")
+	defer clientDispatcher.Stop()
+
+	client := protobuf.NewStreamClient(protobuf.ClientParams{
+		ServiceName:  ServiceName,
+		ClientConfig: clientDispatcher.ClientConfig("test"),
+	})
+	stream, err := client.CallStream(context.Background(), _watchMethodName)
+	require.NoError(t, err, "opening Watch stream failed")
+	require.NoError(t, stream.Send(&hpb.HealthCheckRequest{Service: "keyvalue.KeyValue"}))
+
+	receive := func() (hpb.HealthCheckResponse_ServingStatus, error) {
+		message, err := stream.Receive(newHealthCheckResponse)
+		if err != nil {
+			return hpb.HealthCheckResponse_UNKNOWN, err
+		}
+		return message.(*hpb.HealthCheckResponse).Status, nil
+	}
+	status, err := receive()
+	require.NoError(t, err)
+	assert.Equal(t, hpb.HealthCheckResponse_SERVING, status)
+
+	// The inbound waits for open streams to end before it stops.
+	stopped := make(chan error, 1)
+	go func() { stopped <- d.Stop() }()
+
+	status, err = receive()
+	require.NoError(t, err)
+	assert.Equal(t, hpb.HealthCheckResponse_NOT_SERVING, status)
+	_, err = receive()
+	assert.Equal(t, io.EOF, err, "expected the stream to end")
+
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err, "stopping dispatcher failed")
+	case <-time.After(5 * testtime.Second):
+		t.Fatal("dispatcher did not stop while a Watch stream was open")
+	}
+}
+
+func TestPlainGRPCClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "listening failed")
+	d := yarpc.NewDispatcher(yarpc.Config{
+		Name:     "test",
+		Inbounds: yarpc.Inbounds{grpc.NewTransport().NewInbound(listener)},
+	})
+	d.Register(raw.Procedure("keyvalue.KeyValue::Get", func(ctx context.Context, body []byte) ([]byte, error) {
+		return body, nil
+	}))
+	server := NewServer(d)
+	d.Register(server.Procedures())
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+	defer d.Stop()
+
+	// Like grpc_health_probe, the client sends none of the YARPC headers.
+	conn, err := ggrpc.Dial(listener.Addr().String(), ggrpc.WithInsecure())
+	require.NoError(t, err, "dialing failed")
+	defer conn.Close()
+	client := hpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testtime.Second)
+	defer cancel()
+
+	response, err := client.Check(ctx, &hpb.HealthCheckRequest{})
+	require.NoError(t, err, "check failed")
+	assert.Equal(t, hpb.HealthCheckResponse_SERVING, response.Status)
+
+	server.SetServingStatus("keyvalue.KeyValue", hpb.HealthCheckResponse_NOT_SERVING)
+	stream, err := client.Watch(ctx, &hpb.HealthCheckRequest{Service: "keyvalue.KeyValue"})
+	require.NoError(t, err, "opening Watch stream failed")
+	response, err = stream.Recv()
+	require.NoError(t, err, "receiving from Watch stream failed")
+	assert.Equal(t, hpb.HealthCheckResponse_NOT_SERVING, response.Status)
+}
+
+func newHealthCheckResponse() proto.Message {
+	return &hpb.HealthCheckResponse{}
+}
+
+func TestWatchUnknownService(t *testing.T) {
+	d, server := newTestServer(t)
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+	defer d.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, serverErr := watch(ctx, t, server, "unknown.Service")
+	assert.Equal(t, hpb.HealthCheckResponse_SERVICE_UNKNOWN, receiveStatus(t, stream))
+
+	server.SetServingStatus("unknown.Service", hpb.HealthCheckResponse_SERVING)
+	assert.Equal(t, hpb.HealthCheckResponse_SERVING, receiveStatus(t, stream))
+
+	cancel()
+	assert.NoError(t, <-serverErr)
+}
@@ -32,17 +32,25 @@ import (
 type unaryHandler struct {
 	handle     func(context.Context, proto.Message) (proto.Message, error)
 	newRequest func() proto.Message
+	codec      *codec
 }
 
 func newUnaryHandler(
 	handle func(context.Context, proto.Message) (proto.Message, error),
 	newRequest func() proto.Message,
 ) *unaryHandler {
-	return &unaryHandler{handle, newRequest}
+	return &unaryHandler{handle, newRequest, _defaultCodec}
+}
+
+// withCodec returns a copy of the handler that uses the given codec.
+func (u *unaryHandler) withCodec(codec *codec) *unaryHandler {
+	h := *u
+	h.codec = codec
+	return &h
 }
 
 func (u *unaryHandler) Handle(ctx context.Context, transportRequest *transport.Request, responseWriter transport.ResponseWriter) error {
-	ctx, call, request, err := getProtoRequest(ctx, transportRequest, u.newRequest)
+	ctx, call, request, err := getProtoRequest(ctx, transportRequest, u.codec, u.newRequest)
 	if err != nil {
 		return err
 	}
@@ -55,7 +63,7 @@ func (u *unaryHandler) Handle(ctx context.Context, transportRequest *transport.R
 	var responseData []byte
 	var responseCleanup func()
 	if response != nil {
-		responseData, responseCleanup, err = marshal(transportRequest.Encoding, response)
+		responseData, responseCleanup, err = u.codec.marshal(transportRequest.Encoding, response)
 		if responseCleanup != nil {
 			defer responseCleanup()
 		}
@@ -76,17 +84,25 @@ func (u *unaryHandler) Handle(ctx context.Context, transportRequest *transport.R
 type onewayHandler struct {
 	handleOneway func(context.Context, proto.Message) error
 	newRequest   func() proto.Message
+	codec        *codec
 }
 
 func newOnewayHandler(
 	handleOneway func(context.Context, proto.Message) error,
 	newRequest func() proto.Message,
 ) *onewayHandler {
-	return &onewayHandler{handleOneway, newRequest}
+	return &onewayHandler{handleOneway, newRequest, _defaultCodec}
+}
+
+// withCodec returns a copy of the handler that uses the given codec.
+func (o *onewayHandler) withCodec(codec *codec) *onewayHandler {
+	h := *o
+	h.codec = codec
+	return &h
 }
 
 func (o *onewayHandler) HandleOneway(ctx context.Context, transportRequest *transport.Request) error {
-	ctx, _, request, err := getProtoRequest(ctx, transportRequest, o.newRequest)
+	ctx, _, request, err := getProtoRequest(ctx, transportRequest, o.codec, o.newRequest)
 	if err != nil {
 		return err
 	}
@@ -95,10 +111,18 @@ func (o *onewayHandler) HandleOneway(ctx context.Context, transportRequest *tran
 
 type streamHandler struct {
 	handle func(*ServerStream) error
+	codec  *codec
 }
 
 func newStreamHandler(handle func(*ServerStream) error) *streamHandler {
-	return &streamHandler{handle}
+	return &streamHandler{handle, _defaultCodec}
+}
+
+// withCodec returns a copy of the handler that uses the given codec.
+func (s *streamHandler) withCodec(codec *codec) *streamHandler {
+	h := *s
+	h.codec = codec
+	return &h
 }
 
 func (s *streamHandler) HandleStream(stream *transport.ServerStream) error {
@@ -109,11 +133,12 @@ func (s *streamHandler) HandleStream(stream *transport.ServerStream) error {
 	protoStream := &ServerStream{
 		ctx:    ctx,
 		stream: stream,
+		codec:  s.codec,
 	}
 	return s.handle(protoStream)
 }
 
-func getProtoRequest(ctx context.Context, transportRequest *transport.Request, newRequest func() proto.Message) (context.Context, *apiencoding.InboundCall, proto.Message, error) {
+func getProtoRequest(ctx context.Context, transportRequest *transport.Request, codec *codec, newRequest func() proto.Message) (context.Context, *apiencoding.InboundCall, proto.Message, error) {
 	if err := errors.ExpectEncodings(transportRequest, Encoding, JSONEncoding); err != nil {
 		return nil, nil, nil, err
 	}
@@ -122,7 +147,7 @@ func getProtoRequest(ctx context.Context, transportRequest *transport.Request, n
 		return nil, nil, nil, err
 	}
 	request := newRequest()
-	if err := unmarshal(transportRequest.Encoding, transportRequest.Body, request); err != nil {
+	if err := codec.unmarshal(transportRequest.Encoding, transportRequest.Body, request); err != nil {
 		return nil, nil, nil, errors.RequestBodyDecodeError(transportRequest, err)
 	}
 	return ctx, call, request, nil
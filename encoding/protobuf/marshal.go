@@ -33,16 +33,43 @@ import (
 )
 
 var (
-	_jsonMarshaler   = &jsonpb.Marshaler{}
-	_jsonUnmarshaler = &jsonpb.Unmarshaler{AllowUnknownFields: true}
-	_bufferPool      = sync.Pool{
+	_defaultCodec = &codec{
+		jsonMarshaler:   &jsonpb.Marshaler{},
+		jsonUnmarshaler: &jsonpb.Unmarshaler{AllowUnknownFields: true},
+	}
+	_bufferPool = sync.Pool{
 		New: func() interface{} {
 			return proto.NewBuffer(make([]byte, 1024))
 		},
 	}
 )
 
-func unmarshal(encoding transport.Encoding, reader io.Reader, message proto.Message) error {
+// codec marshals and unmarshals messages for the Encoding and JSONEncoding
+// encodings.
+type codec struct {
+	jsonMarshaler   *jsonpb.Marshaler
+	jsonUnmarshaler *jsonpb.Unmarshaler
+}
+
+// newCodec builds a codec with the given JSON options, returning the default
+// codec if there are none.
+func newCodec(options []JSONOption) *codec {
+	if len(options) == 0 {
+		return _defaultCodec
+	}
+	marshaler := *_defaultCodec.jsonMarshaler
+	unmarshaler := *_defaultCodec.jsonUnmarshaler
+	c := &codec{
+		jsonMarshaler:   &marshaler,
+		jsonUnmarshaler: &unmarshaler,
+	}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+func (c *codec) unmarshal(encoding transport.Encoding, reader io.Reader, message proto.Message) error {
 	buf := bufferpool.Get()
 	defer bufferpool.Put(buf)
 	if _, err := buf.ReadFrom(reader); err != nil {
@@ -56,7 +83,7 @@ func unmarshal(encoding transport.Encoding, reader io.Reader, message proto.Mess
 	case Encoding:
 		return unmarshalProto(body, message)
 	case JSONEncoding:
-		return unmarshalJSON(body, message)
+		return c.unmarshalJSON(body, message)
 	default:
 		return yarpcerrors.Newf(yarpcerrors.CodeInternal, "encoding.Expect should have handled encoding %q but did not", encoding)
 	}
@@ -66,16 +93,16 @@ func unmarshalProto(body []byte, message proto.Message) error {
 	return proto.Unmarshal(body, message)
 }
 
-func unmarshalJSON(body []byte, message proto.Message) error {
-	return _jsonUnmarshaler.Unmarshal(bytes.NewReader(body), message)
+func (c *codec) unmarshalJSON(body []byte, message proto.Message) error {
+	return c.jsonUnmarshaler.Unmarshal(bytes.NewReader(body), message)
 }
 
-func marshal(encoding transport.Encoding, message proto.Message) ([]byte, func(), error) {
+func (c *codec) marshal(encoding transport.Encoding, message proto.Message) ([]byte, func(), error) {
 	switch encoding {
 	case Encoding:
 		return marshalProto(message)
 	case JSONEncoding:
-		return marshalJSON(message)
+		return c.marshalJSON(message)
 	default:
 		return nil, nil, yarpcerrors.Newf(yarpcerrors.CodeInternal, "encoding.Expect should have handled encoding %q but did not", encoding)
 	}
@@ -91,10 +118,10 @@ func marshalProto(message proto.Message) ([]byte, func(), error) {
 	return protoBuffer.Bytes(), cleanup, nil
 }
 
-func marshalJSON(message proto.Message) ([]byte, func(), error) {
+func (c *codec) marshalJSON(message proto.Message) ([]byte, func(), error) {
 	buf := bufferpool.Get()
 	cleanup := func() { bufferpool.Put(buf) }
-	if err := _jsonMarshaler.Marshal(buf, message); err != nil {
+	if err := c.jsonMarshaler.Marshal(buf, message); err != nil {
 		cleanup()
 		return nil, nil, err
 	}
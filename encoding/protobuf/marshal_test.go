@@ -22,15 +22,109 @@ package protobuf
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
 func TestUnhandledEncoding(t *testing.T) {
-	assert.Equal(t, yarpcerrors.CodeInternal, yarpcerrors.FromError(unmarshal(transport.Encoding("foo"), bytes.NewReader([]byte("foo")), nil)).Code())
-	_, _, err := marshal(transport.Encoding("foo"), nil)
+	assert.Equal(t, yarpcerrors.CodeInternal, yarpcerrors.FromError(_defaultCodec.unmarshal(transport.Encoding("foo"), bytes.NewReader([]byte("foo")), nil)).Code())
+	_, _, err := _defaultCodec.marshal(transport.Encoding("foo"), nil)
 	assert.Equal(t, yarpcerrors.CodeInternal, yarpcerrors.FromError(err).Code())
 }
+
+func TestJSONOptions(t *testing.T) {
+	message := &types.Field{Kind: types.Field_TYPE_STRING, JsonName: "foo"}
+
+	tests := []struct {
+		desc    string
+		options []JSONOption
+		want    string
+	}{
+		{
+			desc: "defaults",
+			want: `{"kind":"TYPE_STRING","jsonName":"foo"}`,
+		},
+		{
+			desc:    "orig name",
+			options: []JSONOption{JSONOrigName(true)},
+			want:    `{"kind":"TYPE_STRING","json_name":"foo"}`,
+		},
+		{
+			desc:    "enums as ints",
+			options: []JSONOption{JSONEnumsAsInts(true)},
+			want:    `{"kind":9,"jsonName":"foo"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			data, cleanup, err := newCodec(tt.options).marshal(JSONEncoding, message)
+			require.NoError(t, err)
+			defer cleanup()
+			assert.JSONEq(t, tt.want, string(data))
+		})
+	}
+
+	t.Run("emit defaults", func(t *testing.T) {
+		data, cleanup, err := newCodec([]JSONOption{JSONEmitDefaults(true)}).marshal(JSONEncoding, message)
+		require.NoError(t, err)
+		defer cleanup()
+		assert.Contains(t, string(data), `"number":0`)
+		assert.Contains(t, string(data), `"cardinality":"CARDINALITY_UNKNOWN"`)
+	})
+}
+
+func TestJSONAllowUnknownFields(t *testing.T) {
+	body := []byte(`{"jsonName":"foo","unknownField":1}`)
+
+	var field types.Field
+	assert.NoError(t, newCodec(nil).unmarshal(JSONEncoding, bytes.NewReader(body), &field))
+	assert.Equal(t, "foo", field.JsonName)
+
+	assert.Error(t, newCodec([]JSONOption{JSONAllowUnknownFields(false)}).unmarshal(JSONEncoding, bytes.NewReader(body), &types.Field{}))
+}
+
+func TestBuildProceduresJSONOptions(t *testing.T) {
+	handler := NewUnaryHandler(UnaryHandlerParams{
+		Handle: func(context.Context, proto.Message) (proto.Message, error) {
+			return &types.Field{JsonName: "foo"}, nil
+		},
+		NewRequest: func() proto.Message { return &types.Field{} },
+	})
+	params := BuildProceduresParams{
+		ServiceName: "Service",
+		UnaryHandlerParams: []BuildProceduresUnaryHandlerParams{
+			{MethodName: "Method", Handler: handler},
+		},
+	}
+
+	handle := func(procedures []transport.Procedure) string {
+		var rw transporttest.FakeResponseWriter
+		for _, p := range procedures {
+			if p.Encoding != JSONEncoding {
+				continue
+			}
+			require.NoError(t, p.HandlerSpec.Unary().Handle(context.Background(), &transport.Request{
+				Caller:    "caller",
+				Service:   "service",
+				Procedure: p.Name,
+				Encoding:  JSONEncoding,
+				Body:      bytes.NewReader([]byte(`{}`)),
+			}, &rw))
+		}
+		return rw.Body.String()
+	}
+
+	assert.JSONEq(t, `{"jsonName":"foo"}`, handle(BuildProcedures(params)))
+
+	params.Options = []BuildProceduresOption{JSONOrigName(true)}
+	assert.JSONEq(t, `{"json_name":"foo"}`, handle(BuildProcedures(params)))
+}
@@ -38,6 +38,8 @@ type client struct {
 	serviceName    string
 	outboundConfig *transport.OutboundConfig
 	encoding       transport.Encoding
+	jsonOptions    []JSONOption
+	codec          *codec
 }
 
 func newClient(serviceName string, clientConfig transport.ClientConfig, options ...ClientOption) *client {
@@ -50,6 +52,7 @@ func newClient(serviceName string, clientConfig transport.ClientConfig, options
 	for _, option := range options {
 		option.apply(client)
 	}
+	client.codec = newCodec(client.jsonOptions)
 	return client
 }
 
@@ -103,7 +106,7 @@ func (c *client) Call(
 	var response proto.Message
 	if transportResponse.Body != nil {
 		response = newResponse()
-		if err := unmarshal(transportRequest.Encoding, transportResponse.Body, response); err != nil {
+		if err := c.codec.unmarshal(transportRequest.Encoding, transportResponse.Body, response); err != nil {
 			return nil, errors.ResponseBodyDecodeError(transportRequest, err)
 		}
 	}
@@ -146,7 +149,7 @@ func (c *client) buildTransportRequest(ctx context.Context, requestMethodName st
 		return nil, nil, nil, nil, yarpcerrors.Newf(yarpcerrors.CodeInternal, "can only use encodings %q or %q, but %q was specified", Encoding, JSONEncoding, transportRequest.Encoding)
 	}
 	if request != nil {
-		requestData, cleanup, err := marshal(transportRequest.Encoding, request)
+		requestData, cleanup, err := c.codec.marshal(transportRequest.Encoding, request)
 		if err != nil {
 			return nil, nil, nil, cleanup, errors.RequestBodyEncodeError(transportRequest, err)
 		}
@@ -190,5 +193,5 @@ func (c *client) CallStream(
 	if err != nil {
 		return nil, err
 	}
-	return &ClientStream{stream: stream}, nil
+	return &ClientStream{stream: stream, codec: c.codec}, nil
 }
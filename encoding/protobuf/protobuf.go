@@ -45,6 +45,69 @@ const (
 // UseJSON says to use the json encoding for client/server communication.
 var UseJSON ClientOption = useJSON{}
 
+// JSONOption customizes how messages are marshaled to and unmarshaled from
+// JSON for the JSONEncoding.
+//
+// JSONOptions may be passed to clients as ClientOptions, and to
+// BuildProcedures or the generated Build...YARPCProcedures functions as
+// BuildProceduresOptions. They have no effect on the Encoding encoding.
+type JSONOption func(*codec)
+
+var (
+	_ ClientOption          = JSONOption(nil)
+	_ BuildProceduresOption = JSONOption(nil)
+)
+
+func (o JSONOption) apply(client *client) {
+	client.jsonOptions = append(client.jsonOptions, o)
+}
+
+func (o JSONOption) applyBuildProcedures(options *buildProceduresOptions) {
+	options.jsonOptions = append(options.jsonOptions, o)
+}
+
+// JSONEmitDefaults specifies whether fields with zero values are included
+// in JSON messages.
+//
+// Defaults to false.
+func JSONEmitDefaults(emit bool) JSONOption {
+	return func(c *codec) {
+		c.jsonMarshaler.EmitDefaults = emit
+	}
+}
+
+// JSONOrigName specifies whether JSON messages use the field names from the
+// .proto file instead of their lowerCamelCase JSON names.
+//
+// Defaults to false.
+func JSONOrigName(orig bool) JSONOption {
+	return func(c *codec) {
+		c.jsonMarshaler.OrigName = orig
+	}
+}
+
+// JSONEnumsAsInts specifies whether enum values are written to JSON messages
+// as integers instead of their names.
+//
+// Defaults to false.
+func JSONEnumsAsInts(asInts bool) JSONOption {
+	return func(c *codec) {
+		c.jsonMarshaler.EnumsAsInts = asInts
+	}
+}
+
+// JSONAllowUnknownFields specifies whether JSON messages may contain fields
+// that are not known to the message type, such as fields added in a newer
+// version of the .proto file. Unknown fields are ignored when allowed and
+// fail the request otherwise.
+//
+// Defaults to true.
+func JSONAllowUnknownFields(allow bool) JSONOption {
+	return func(c *codec) {
+		c.jsonUnmarshaler.AllowUnknownFields = allow
+	}
+}
+
 // ***all below functions should only be called by generated code***
 
 // BuildProceduresParams contains the parameters for BuildProcedures.
@@ -53,6 +116,16 @@ type BuildProceduresParams struct {
 	UnaryHandlerParams  []BuildProceduresUnaryHandlerParams
 	OnewayHandlerParams []BuildProceduresOnewayHandlerParams
 	StreamHandlerParams []BuildProceduresStreamHandlerParams
+	Options             []BuildProceduresOption
+}
+
+// BuildProceduresOption is an option for BuildProcedures.
+type BuildProceduresOption interface {
+	applyBuildProcedures(*buildProceduresOptions)
+}
+
+type buildProceduresOptions struct {
+	jsonOptions []JSONOption
 }
 
 // BuildProceduresUnaryHandlerParams contains the parameters for a UnaryHandler for BuildProcedures.
@@ -75,48 +148,66 @@ type BuildProceduresStreamHandlerParams struct {
 
 // BuildProcedures builds the transport.Procedures.
 func BuildProcedures(params BuildProceduresParams) []transport.Procedure {
+	var options buildProceduresOptions
+	for _, opt := range params.Options {
+		opt.applyBuildProcedures(&options)
+	}
+	codec := newCodec(options.jsonOptions)
+
 	procedures := make([]transport.Procedure, 0, 2*(len(params.UnaryHandlerParams)+len(params.OnewayHandlerParams)))
 	for _, unaryHandlerParams := range params.UnaryHandlerParams {
+		handler := unaryHandlerParams.Handler
+		if h, ok := handler.(*unaryHandler); ok {
+			handler = h.withCodec(codec)
+		}
 		procedures = append(
 			procedures,
 			transport.Procedure{
 				Name:        procedure.ToName(params.ServiceName, unaryHandlerParams.MethodName),
-				HandlerSpec: transport.NewUnaryHandlerSpec(unaryHandlerParams.Handler),
+				HandlerSpec: transport.NewUnaryHandlerSpec(handler),
 				Encoding:    Encoding,
 			},
 			transport.Procedure{
 				Name:        procedure.ToName(params.ServiceName, unaryHandlerParams.MethodName),
-				HandlerSpec: transport.NewUnaryHandlerSpec(unaryHandlerParams.Handler),
+				HandlerSpec: transport.NewUnaryHandlerSpec(handler),
 				Encoding:    JSONEncoding,
 			},
 		)
 	}
 	for _, onewayHandlerParams := range params.OnewayHandlerParams {
+		handler := onewayHandlerParams.Handler
+		if h, ok := handler.(*onewayHandler); ok {
+			handler = h.withCodec(codec)
+		}
 		procedures = append(
 			procedures,
 			transport.Procedure{
 				Name:        procedure.ToName(params.ServiceName, onewayHandlerParams.MethodName),
-				HandlerSpec: transport.NewOnewayHandlerSpec(onewayHandlerParams.Handler),
+				HandlerSpec: transport.NewOnewayHandlerSpec(handler),
 				Encoding:    Encoding,
 			},
 			transport.Procedure{
 				Name:        procedure.ToName(params.ServiceName, onewayHandlerParams.MethodName),
-				HandlerSpec: transport.NewOnewayHandlerSpec(onewayHandlerParams.Handler),
+				HandlerSpec: transport.NewOnewayHandlerSpec(handler),
 				Encoding:    JSONEncoding,
 			},
 		)
 	}
 	for _, streamHandlerParams := range params.StreamHandlerParams {
+		handler := streamHandlerParams.Handler
+		if h, ok := handler.(*streamHandler); ok {
+			handler = h.withCodec(codec)
+		}
 		procedures = append(
 			procedures,
 			transport.Procedure{
 				Name:        procedure.ToName(params.ServiceName, streamHandlerParams.MethodName),
-				HandlerSpec: transport.NewStreamHandlerSpec(streamHandlerParams.Handler),
+				HandlerSpec: transport.NewStreamHandlerSpec(handler),
 				Encoding:    Encoding,
 			},
 			transport.Procedure{
 				Name:        procedure.ToName(params.ServiceName, streamHandlerParams.MethodName),
-				HandlerSpec: transport.NewStreamHandlerSpec(streamHandlerParams.Handler),
+				HandlerSpec: transport.NewStreamHandlerSpec(handler),
 				Encoding:    JSONEncoding,
 			},
 		)
@@ -247,6 +338,7 @@ func uniqueLowercaseStrings(s []string) []string {
 // ClientStream is a protobuf-specific client stream.
 type ClientStream struct {
 	stream *transport.ClientStream
+	codec  *codec
 }
 
 // Context returns the context of the stream.
@@ -256,12 +348,12 @@ func (c *ClientStream) Context() context.Context {
 
 // Receive will receive a protobuf message from the client stream.
 func (c *ClientStream) Receive(newMessage func() proto.Message, options ...yarpc.StreamOption) (proto.Message, error) {
-	return readFromStream(context.Background(), c.stream, newMessage)
+	return readFromStream(context.Background(), c.stream, c.codec, newMessage)
 }
 
 // Send will send a protobuf message to the client stream.
 func (c *ClientStream) Send(message proto.Message, options ...yarpc.StreamOption) error {
-	return writeToStream(context.Background(), c.stream, message)
+	return writeToStream(context.Background(), c.stream, c.codec, message)
 }
 
 // Close will close the protobuf stream.
@@ -273,6 +365,7 @@ func (c *ClientStream) Close(options ...yarpc.StreamOption) error {
 type ServerStream struct {
 	ctx    context.Context
 	stream *transport.ServerStream
+	codec  *codec
 }
 
 // Context returns the context of the stream.
@@ -282,10 +375,10 @@ func (s *ServerStream) Context() context.Context {
 
 // Receive will receive a protobuf message from the server stream.
 func (s *ServerStream) Receive(newMessage func() proto.Message, options ...yarpc.StreamOption) (proto.Message, error) {
-	return readFromStream(context.Background(), s.stream, newMessage)
+	return readFromStream(context.Background(), s.stream, s.codec, newMessage)
 }
 
 // Send will send a protobuf message to the server stream.
 func (s *ServerStream) Send(message proto.Message, options ...yarpc.StreamOption) error {
-	return writeToStream(context.Background(), s.stream, message)
+	return writeToStream(context.Background(), s.stream, s.codec, message)
 }
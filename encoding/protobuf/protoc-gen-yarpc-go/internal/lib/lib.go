@@ -136,11 +136,12 @@ type {{$service.GetName}}Service{{$method.GetName}}YARPCServer interface {
 {{end}}
 
 // Build{{$service.GetName}}YARPCProcedures prepares an implementation of the {{$service.GetName}} service for YARPC registration.
-func Build{{$service.GetName}}YARPCProcedures(server {{$service.GetName}}YARPCServer) []transport.Procedure {
+func Build{{$service.GetName}}YARPCProcedures(server {{$service.GetName}}YARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_{{$service.GetName}}YARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName: "{{trimPrefixPeriod $service.FQSN}}",
+			Options: options,
 			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{
 			{{range $method := unaryMethods $service}}{
 					MethodName: "{{$method.GetName}}",
@@ -25,6 +25,26 @@
 // The `ServerReflectionInfo` structs should be generated and populated from
 // the `protoc-gen-yarpc-go` plugin for each service.
 //
+// Server implements the grpc.reflection.v1alpha.ServerReflection service from
+// these ServerMeta values, so that tools like grpcurl can introspect services
+// served by a gRPC inbound.
+//
+// 	server, err := reflection.NewServer([]reflection.ServerMeta{...})
+// 	if err != nil {
+// 		return err
+// 	}
+// 	dispatcher.Register(server.Procedures())
+//
+// In Fx applications, the ServerMeta values of all services are provided by
+// the generated NewFx...YARPCProcedures functions to the "yarpcfx" value
+// group.
+//
+// 	type params struct {
+// 		fx.In
+//
+// 		Metas []reflection.ServerMeta `group:"yarpcfx"`
+// 	}
+//
 // For more information on gRPC server reflection, see
 // https://github.com/grpc/grpc/blob/master/doc/server-reflection.md
 package reflection
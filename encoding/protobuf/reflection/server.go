@@ -0,0 +1,337 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package reflection
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+const (
+	// ServiceName is the fully qualified name of the gRPC server reflection
+	// service.
+	ServiceName = "grpc.reflection.v1alpha.ServerReflection"
+
+	_methodName = "ServerReflectionInfo"
+)
+
+var _emptyRequest = &rpb.ServerReflectionRequest{}
+
+// file is a file descriptor known to the Server.
+type file struct {
+	// Serialized FileDescriptorProto, as sent in responses.
+	raw          []byte
+	dependencies []string
+}
+
+// extension identifies an extension field.
+type extension struct {
+	extendee string
+	number   int32
+}
+
+// Server implements the grpc.reflection.v1alpha.ServerReflection service for
+// the services it is built with, so that tools like grpcurl may list them
+// and fetch their descriptors.
+//
+// It is registered on a Dispatcher with its Procedures, and is served by the
+// gRPC inbound.
+//
+// 	server, err := reflection.NewServer(metas)
+// 	if err != nil {
+// 		return err
+// 	}
+// 	dispatcher.Register(server.Procedures())
+type Server struct {
+	services []string
+
+	// Files by name, the names of the files declaring each symbol, and the
+	// names of the files declaring each extension.
+	files      map[string]file
+	symbols    map[string]string
+	extensions map[extension]string
+	// Extension numbers of each extended type.
+	extensionNumbers map[string][]int32
+}
+
+// NewServer builds a Server that describes the given services, using the
+// ServerMeta generated for them by protoc-gen-yarpc-go. The Server also
+// describes itself.
+//
+// Returns an error if any of the file descriptors are invalid.
+func NewServer(metas []ServerMeta) (*Server, error) {
+	selfDescriptor, _ := _emptyRequest.Descriptor()
+	metas = append([]ServerMeta{{
+		ServiceName:     ServiceName,
+		FileDescriptors: [][]byte{selfDescriptor},
+	}}, metas...)
+
+	s := &Server{
+		files:            make(map[string]file),
+		symbols:          make(map[string]string),
+		extensions:       make(map[extension]string),
+		extensionNumbers: make(map[string][]int32),
+	}
+	services := make(map[string]struct{})
+	for _, meta := range metas {
+		if _, ok := services[meta.ServiceName]; !ok {
+			services[meta.ServiceName] = struct{}{}
+			s.services = append(s.services, meta.ServiceName)
+		}
+		for _, compressed := range meta.FileDescriptors {
+			if err := s.addFile(compressed); err != nil {
+				return nil, fmt.Errorf("invalid file descriptor for service %q: %v", meta.ServiceName, err)
+			}
+		}
+	}
+	sort.Strings(s.services)
+	return s, nil
+}
+
+// addFile decompresses and indexes a gzipped FileDescriptorProto.
+func (s *Server) addFile(compressed []byte) error {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return err
+	}
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var fd descriptor.FileDescriptorProto
+	if err := proto.Unmarshal(raw, &fd); err != nil {
+		return err
+	}
+
+	name := fd.GetName()
+	if _, ok := s.files[name]; ok {
+		// Services often share dependencies.
+		return nil
+	}
+	s.files[name] = file{raw: raw, dependencies: fd.Dependency}
+
+	prefix := fd.GetPackage()
+	for _, service := range fd.Service {
+		serviceName := qualify(prefix, service.GetName())
+		s.symbols[serviceName] = name
+		for _, method := range service.Method {
+			s.symbols[qualify(serviceName, method.GetName())] = name
+		}
+	}
+	for _, message := range fd.MessageType {
+		s.addMessage(name, prefix, message)
+	}
+	for _, enum := range fd.EnumType {
+		s.symbols[qualify(prefix, enum.GetName())] = name
+	}
+	s.addExtensions(name, prefix, fd.Extension)
+	return nil
+}
+
+// addMessage indexes a message and the types nested in it.
+func (s *Server) addMessage(fileName, prefix string, message *descriptor.DescriptorProto) {
+	messageName := qualify(prefix, message.GetName())
+	s.symbols[messageName] = fileName
+	for _, nested := range message.NestedType {
+		s.addMessage(fileName, messageName, nested)
+	}
+	for _, enum := range message.EnumType {
+		s.symbols[qualify(messageName, enum.GetName())] = fileName
+	}
+	s.addExtensions(fileName, messageName, message.Extension)
+}
+
+// addExtensions indexes extension fields declared in a file or message.
+func (s *Server) addExtensions(fileName, prefix string, fields []*descriptor.FieldDescriptorProto) {
+	for _, field := range fields {
+		s.symbols[qualify(prefix, field.GetName())] = fileName
+
+		extendee := strings.TrimPrefix(field.GetExtendee(), ".")
+		s.extensions[extension{extendee: extendee, number: field.GetNumber()}] = fileName
+		s.extensionNumbers[extendee] = append(s.extensionNumbers[extendee], field.GetNumber())
+	}
+}
+
+// Procedures returns the procedures of the reflection service, to be
+// registered on a Dispatcher.
+func (s *Server) Procedures() []transport.Procedure {
+	return protobuf.BuildProcedures(
+		protobuf.BuildProceduresParams{
+			ServiceName: ServiceName,
+			StreamHandlerParams: []protobuf.BuildProceduresStreamHandlerParams{
+				{
+					MethodName: _methodName,
+					Handler: protobuf.NewStreamHandler(
+						protobuf.StreamHandlerParams{
+							Handle: s.serverReflectionInfo,
+						},
+					),
+				},
+			},
+		},
+	)
+}
+
+// serverReflectionInfo answers each request on the stream until the client
+// closes it.
+func (s *Server) serverReflectionInfo(stream *protobuf.ServerStream) error {
+	// Files already sent on this stream need not be sent again.
+	sent := make(map[string]struct{})
+	for {
+		message, err := stream.Receive(newServerReflectionRequest)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		request, ok := message.(*rpb.ServerReflectionRequest)
+		if !ok {
+			return protobuf.CastError(_emptyRequest, message)
+		}
+		if err := stream.Send(s.respond(request, sent)); err != nil {
+			return err
+		}
+	}
+}
+
+func newServerReflectionRequest() proto.Message {
+	return &rpb.ServerReflectionRequest{}
+}
+
+// respond builds the response to a single request.
+func (s *Server) respond(request *rpb.ServerReflectionRequest, sent map[string]struct{}) *rpb.ServerReflectionResponse {
+	response := &rpb.ServerReflectionResponse{
+		ValidHost:       request.Host,
+		OriginalRequest: request,
+	}
+
+	switch req := request.MessageRequest.(type) {
+	case *rpb.ServerReflectionRequest_FileByFilename:
+		if _, ok := s.files[req.FileByFilename]; !ok {
+			response.MessageResponse = errorResponse(codes.NotFound, "unknown file %q", req.FileByFilename)
+			break
+		}
+		response.MessageResponse = s.fileDescriptorResponse(req.FileByFilename, sent)
+
+	case *rpb.ServerReflectionRequest_FileContainingSymbol:
+		name, ok := s.symbols[req.FileContainingSymbol]
+		if !ok {
+			response.MessageResponse = errorResponse(codes.NotFound, "unknown symbol %q", req.FileContainingSymbol)
+			break
+		}
+		response.MessageResponse = s.fileDescriptorResponse(name, sent)
+
+	case *rpb.ServerReflectionRequest_FileContainingExtension:
+		ext := extension{
+			extendee: req.FileContainingExtension.GetContainingType(),
+			number:   req.FileContainingExtension.GetExtensionNumber(),
+		}
+		name, ok := s.extensions[ext]
+		if !ok {
+			response.MessageResponse = errorResponse(codes.NotFound, "unknown extension %d of %q", ext.number, ext.extendee)
+			break
+		}
+		response.MessageResponse = s.fileDescriptorResponse(name, sent)
+
+	case *rpb.ServerReflectionRequest_AllExtensionNumbersOfType:
+		typeName := req.AllExtensionNumbersOfType
+		if _, ok := s.symbols[typeName]; !ok {
+			response.MessageResponse = errorResponse(codes.NotFound, "unknown type %q", typeName)
+			break
+		}
+		numbers := append([]int32(nil), s.extensionNumbers[typeName]...)
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+		response.MessageResponse = &rpb.ServerReflectionResponse_AllExtensionNumbersResponse{
+			AllExtensionNumbersResponse: &rpb.ExtensionNumberResponse{
+				BaseTypeName:    typeName,
+				ExtensionNumber: numbers,
+			},
+		}
+
+	case *rpb.ServerReflectionRequest_ListServices:
+		services := make([]*rpb.ServiceResponse, len(s.services))
+		for i, name := range s.services {
+			services[i] = &rpb.ServiceResponse{Name: name}
+		}
+		response.MessageResponse = &rpb.ServerReflectionResponse_ListServicesResponse{
+			ListServicesResponse: &rpb.ListServiceResponse{Service: services},
+		}
+
+	default:
+		response.MessageResponse = errorResponse(codes.InvalidArgument, "invalid request %v", request.MessageRequest)
+	}
+	return response
+}
+
+// fileDescriptorResponse returns the named file with its transitive
+// dependencies, omitting those already sent on the stream.
+func (s *Server) fileDescriptorResponse(name string, sent map[string]struct{}) *rpb.ServerReflectionResponse_FileDescriptorResponse {
+	var raw [][]byte
+	queue := []string{name}
+	for len(queue) > 0 {
+		name, queue = queue[0], queue[1:]
+		f, ok := s.files[name]
+		if !ok {
+			continue
+		}
+		if _, ok := sent[name]; ok && len(raw) > 0 {
+			continue
+		}
+		// The requested file is always sent, even if it was sent before.
+		sent[name] = struct{}{}
+		raw = append(raw, f.raw)
+		queue = append(queue, f.dependencies...)
+	}
+	return &rpb.ServerReflectionResponse_FileDescriptorResponse{
+		FileDescriptorResponse: &rpb.FileDescriptorResponse{FileDescriptorProto: raw},
+	}
+}
+
+func errorResponse(code codes.Code, format string, args ...interface{}) *rpb.ServerReflectionResponse_ErrorResponse {
+	return &rpb.ServerReflectionResponse_ErrorResponse{
+		ErrorResponse: &rpb.ErrorResponse{
+			ErrorCode:    int32(code),
+			ErrorMessage: fmt.Sprintf(format, args...),
+		},
+	}
+}
+
+// qualify returns the fully qualified name of a symbol in a package or
+// message.
+func qualify(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package reflection
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/internal/testtime"
+	"go.uber.org/yarpc/transport/grpc"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+var (
+	_barFile = &descriptor.FileDescriptorProto{
+		Name:    proto.String("bar.proto"),
+		Package: proto.String("bar"),
+		MessageType: []*descriptor.DescriptorProto{
+			{Name: proto.String("Bar")},
+		},
+	}
+	_fooFile = &descriptor.FileDescriptorProto{
+		Name:       proto.String("foo.proto"),
+		Package:    proto.String("foo"),
+		Dependency: []string{"bar.proto"},
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: proto.String("Request"),
+				NestedType: []*descriptor.DescriptorProto{
+					{Name: proto.String("Inner")},
+				},
+			},
+		},
+		EnumType: []*descriptor.EnumDescriptorProto{
+			{Name: proto.String("Kind")},
+		},
+		Service: []*descriptor.ServiceDescriptorProto{
+			{
+				Name: proto.String("Foo"),
+				Method: []*descriptor.MethodDescriptorProto{
+					{Name: proto.String("Get")},
+				},
+			},
+		},
+		Extension: []*descriptor.FieldDescriptorProto{
+			{
+				Name:     proto.String("baz"),
+				Number:   proto.Int32(100),
+				Extendee: proto.String(".bar.Bar"),
+			},
+		},
+	}
+)
+
+func compress(t *testing.T, fd *descriptor.FileDescriptorProto) []byte {
+	raw, err := proto.Marshal(fd)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func newTestServer(t *testing.T) *Server {
+	server, err := NewServer([]ServerMeta{{
+		ServiceName:     "foo.Foo",
+		FileDescriptors: [][]byte{compress(t, _fooFile), compress(t, _barFile)},
+	}})
+	require.NoError(t, err)
+	return server
+}
+
+// fileNames returns the names of the files in a response.
+func fileNames(t *testing.T, response *rpb.ServerReflectionResponse) []string {
+	res := response.GetFileDescriptorResponse()
+	require.NotNil(t, res, "expected a file descriptor response, got %v", response)
+	var names []string
+	for _, raw := range res.FileDescriptorProto {
+		var fd descriptor.FileDescriptorProto
+		require.NoError(t, proto.Unmarshal(raw, &fd))
+		names = append(names, fd.GetName())
+	}
+	return names
+}
+
+func TestNewServerInvalidDescriptor(t *testing.T) {
+	_, err := NewServer([]ServerMeta{{
+		ServiceName:     "foo.Foo",
+		FileDescriptors: [][]byte{[]byte("not gzipped")},
+	}})
+	assert.Error(t, err)
+}
+
+func TestListServices(t *testing.T) {
+	response := newTestServer(t).respond(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	}, make(map[string]struct{}))
+
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	assert.Equal(t, []string{"foo.Foo", ServiceName}, services)
+}
+
+func TestFileContainingSymbol(t *testing.T) {
+	server := newTestServer(t)
+	tests := []struct {
+		symbol    string
+		wantFiles []string
+	}{
+		{symbol: "foo.Foo", wantFiles: []string{"foo.proto", "bar.proto"}},
+		{symbol: "foo.Foo.Get", wantFiles: []string{"foo.proto", "bar.proto"}},
+		{symbol: "foo.Request", wantFiles: []string{"foo.proto", "bar.proto"}},
+		{symbol: "foo.Request.Inner", wantFiles: []string{"foo.proto", "bar.proto"}},
+		{symbol: "foo.Kind", wantFiles: []string{"foo.proto", "bar.proto"}},
+		{symbol: "bar.Bar", wantFiles: []string{"bar.proto"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			response := server.respond(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: tt.symbol},
+			}, make(map[string]struct{}))
+			assert.Equal(t, tt.wantFiles, fileNames(t, response))
+		})
+	}
+}
+
+func TestDescribesItself(t *testing.T) {
+	response := newTestServer(t).respond(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: ServiceName},
+	}, make(map[string]struct{}))
+
+	files := response.GetFileDescriptorResponse().GetFileDescriptorProto()
+	require.Len(t, files, 1)
+	var fd descriptor.FileDescriptorProto
+	require.NoError(t, proto.Unmarshal(files[0], &fd))
+	assert.Equal(t, "grpc.reflection.v1alpha", fd.GetPackage())
+}
+
+func TestFilesSentOnce(t *testing.T) {
+	server := newTestServer(t)
+	sent := make(map[string]struct{})
+
+	response := server.respond(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: "foo.proto"},
+	}, sent)
+	assert.Equal(t, []string{"foo.proto", "bar.proto"}, fileNames(t, response))
+
+	// Requested files are sent again but dependencies are not.
+	response = server.respond(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: "foo.proto"},
+	}, sent)
+	assert.Equal(t, []string{"foo.proto"}, fileNames(t, response))
+}
+
+func TestExtensions(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.respond(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingExtension{
+			FileContainingExtension: &rpb.ExtensionRequest{ContainingType: "bar.Bar", ExtensionNumber: 100},
+		},
+	}, make(map[string]struct{}))
+	assert.Equal(t, []string{"foo.proto", "bar.proto"}, fileNames(t, response))
+
+	response = server.respond(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_AllExtensionNumbersOfType{AllExtensionNumbersOfType: "bar.Bar"},
+	}, make(map[string]struct{}))
+	assert.Equal(t, &rpb.ExtensionNumberResponse{
+		BaseTypeName:    "bar.Bar",
+		ExtensionNumber: []int32{100},
+	}, response.GetAllExtensionNumbersResponse())
+}
+
+func TestNotFound(t *testing.T) {
+	server := newTestServer(t)
+	requests := []*rpb.ServerReflectionRequest{
+		{MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: "baz.proto"}},
+		{MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "baz.Baz"}},
+		{MessageRequest: &rpb.ServerReflectionRequest_FileContainingExtension{
+			FileContainingExtension: &rpb.ExtensionRequest{ContainingType: "bar.Bar", ExtensionNumber: 101},
+		}},
+		{MessageRequest: &rpb.ServerReflectionRequest_AllExtensionNumbersOfType{AllExtensionNumbersOfType: "baz.Baz"}},
+	}
+
+	for _, request := range requests {
+		response := server.respond(request, make(map[string]struct{}))
+		assert.Equal(t, int32(codes.NotFound), response.GetErrorResponse().GetErrorCode(), "request %v", request)
+	}
+}
+
+func TestServerReflectionInfo(t *testing.T) {
+	procedures := newTestServer(t).Procedures()
+	require.NotEmpty(t, procedures)
+	var spec transport.HandlerSpec
+	for _, p := range procedures {
+		if p.Encoding == protobuf.Encoding {
+			assert.Equal(t, "grpc.reflection.v1alpha.ServerReflection::ServerReflectionInfo", p.Name)
+			spec = p.HandlerSpec
+		}
+	}
+	require.Equal(t, transport.Streaming, spec.Type())
+
+	ctx := context.Background()
+	req := &transport.StreamRequest{
+		Meta: &transport.RequestMeta{
+			Caller:    "grpcurl",
+			Service:   "service",
+			Procedure: "grpc.reflection.v1alpha.ServerReflection::ServerReflectionInfo",
+			Encoding:  protobuf.Encoding,
+		},
+	}
+	clientEnd, serverEnd := transporttest.NewStreamPipe(ctx, req)
+
+	serverStream, err := transport.NewServerStream(serverEnd)
+	require.NoError(t, err)
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- spec.Stream().HandleStream(serverStream) }()
+
+	body, err := proto.Marshal(&rpb.ServerReflectionRequest{
+		Host:           "localhost",
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+	require.NoError(t, clientEnd.SendMessage(ctx, &transport.StreamMessage{
+		Body: readCloser{bytes.NewReader(body)},
+	}))
+
+	msg, err := clientEnd.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(msg.Body)
+	require.NoError(t, err)
+	var response rpb.ServerReflectionResponse
+	require.NoError(t, proto.Unmarshal(buf.Bytes(), &response))
+	assert.Equal(t, "localhost", response.ValidHost)
+	assert.Len(t, response.GetListServicesResponse().GetService(), 2)
+
+	require.NoError(t, clientEnd.Close(ctx))
+	assert.NoError(t, <-serverErr)
+}
+
+func TestPlainGRPCClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "listening failed")
+	d := yarpc.NewDispatcher(yarpc.Config{
+		Name:     "test",
+		Inbounds: yarpc.Inbounds{grpc.NewTransport().NewInbound(listener)},
+	})
+	d.Register(newTestServer(t).Procedures())
+	require.NoError(t, d.Start(), "starting dispatcher failed")
+	defer d.Stop()
+
+	// Like grpcurl, the client sends none of the YARPC headers.
+	conn, err := ggrpc.Dial(listener.Addr().String(), ggrpc.WithInsecure())
+	require.NoError(t, err, "dialing failed")
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testtime.Second)
+	defer cancel()
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err, "opening reflection stream failed")
+
+	require.NoError(t, stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	}), "sending list services request failed")
+	response, err := stream.Recv()
+	require.NoError(t, err, "receiving list services response failed")
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	assert.Equal(t, []string{"foo.Foo", ServiceName}, services)
+
+	require.NoError(t, stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "foo.Foo"},
+	}), "sending file containing symbol request failed")
+	response, err = stream.Recv()
+	require.NoError(t, err, "receiving file containing symbol response failed")
+	assert.Equal(t, []string{"foo.proto", "bar.proto"}, fileNames(t, response))
+
+	require.NoError(t, stream.CloseSend(), "closing reflection stream failed")
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+type readCloser struct{ io.Reader }
+
+func (readCloser) Close() error { return nil }
@@ -31,6 +31,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
 	"go.uber.org/yarpc/internal/examples/protobuf/example"
 	"go.uber.org/yarpc/internal/examples/protobuf/examplepb"
 	"go.uber.org/yarpc/internal/examples/protobuf/exampleutil"
@@ -84,6 +85,24 @@ func testIntegration(
 	err = setValueGRPC(clients.KeyValueGRPCClient, clients.ContextWrapper, "foo", "bar")
 	assert.Equal(t, status.Error(codes.Unknown, "foo-bar: baz"), err)
 
+	detailMessage := &examplepb.GetValueRequest{Key: "foo"}
+	detail, err := protobuf.NewErrorDetail(detailMessage)
+	assert.NoError(t, err)
+	detailErr := yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, "bad key").WithDetails(detail)
+	keyValueYARPCServer.SetNextError(detailErr)
+	err = setValue(clients.KeyValueYARPCClient, "foo", "bar")
+	assert.Equal(t, detailErr, err)
+	assert.Equal(t, []interface{}{detailMessage}, protobuf.GetErrorDetails(err))
+	keyValueYARPCServer.SetNextError(detailErr)
+	err = setValueGRPC(clients.KeyValueGRPCClient, clients.ContextWrapper, "foo", "bar")
+	if grpcStatus, ok := status.FromError(err); assert.True(t, ok) {
+		assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+		if details := grpcStatus.Proto().GetDetails(); assert.Len(t, details, 1) {
+			assert.Equal(t, detail.TypeURL, details[0].TypeUrl)
+			assert.Equal(t, detail.Value, details[0].Value)
+		}
+	}
+
 	assert.NoError(t, setValue(clients.KeyValueYARPCClient, "foo", ""))
 
 	_, err = getValue(clients.KeyValueYARPCClient, "foo")
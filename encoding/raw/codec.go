@@ -0,0 +1,55 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package raw
+
+import (
+	"fmt"
+
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/pkg/encoding"
+)
+
+var _ encoding.Codec = rawCodec{}
+
+// rawCodec is the encoding.Codec for the raw encoding. It marshals []byte
+// values and unmarshals into *[]byte values as-is.
+type rawCodec struct{}
+
+func (rawCodec) Name() transport.Encoding {
+	return Encoding
+}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	body, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw encoding can only marshal []byte, not %T", v)
+	}
+	return body, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	body, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw encoding can only unmarshal into *[]byte, not %T", v)
+	}
+	*body = data
+	return nil
+}
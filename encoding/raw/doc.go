@@ -42,4 +42,27 @@
 // 	}
 //
 // 	dispatcher.Register(raw.OnewayProcedure("RunTask", RunTask))
+//
+// Use the StreamProcedure function to build streaming procedures. Handlers
+// receive and send messages as byte slices.
+//
+// 	func Relay(stream *raw.ServerStream) error {
+// 		for {
+// 			body, err := stream.Receive()
+// 			if err == io.EOF {
+// 				return nil
+// 			}
+// 			// ...
+// 		}
+// 	}
+//
+// 	dispatcher.Register(raw.StreamProcedure("relay", Relay))
+//
+// To open a stream, use CallStream. This requires a stream outbound for the
+// service.
+//
+// 	stream, err := client.CallStream(ctx, "relay", yarpc.WithHeader("k", "v"))
+// 	err = stream.Send([]byte{1, 2, 3})
+// 	body, err := stream.Receive()
+// 	err = stream.Close()
 package raw
@@ -21,67 +21,24 @@
 package raw
 
 import (
-	"context"
-	"io/ioutil"
-
 	encodingapi "go.uber.org/yarpc/api/encoding"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/pkg/errors"
 )
 
-// rawUnaryHandler adapts a Handler into a transport.UnaryHandler
-type rawUnaryHandler struct{ UnaryHandler }
-
-// rawOnewayHandler adapts a Handler into a transport.OnewayHandler
-type rawOnewayHandler struct{ OnewayHandler }
-
-func (r rawUnaryHandler) Handle(ctx context.Context, treq *transport.Request, rw transport.ResponseWriter) error {
-	if err := errors.ExpectEncodings(treq, Encoding); err != nil {
-		return err
-	}
-
-	ctx, call := encodingapi.NewInboundCall(ctx)
-	if err := call.ReadFromRequest(treq); err != nil {
-		return err
-	}
-
-	reqBody, err := ioutil.ReadAll(treq.Body)
-	if err != nil {
-		return err
-	}
-
-	resBody, appErr := r.UnaryHandler(ctx, reqBody)
-	if err := call.WriteToResponse(rw); err != nil {
-		return err
-	}
-
-	// we want to return the appErr if it exists as this is what
-	// the previous behavior was so we deprioritize this error
-	var writeErr error
-	if len(resBody) > 0 {
-		_, writeErr = rw.Write(resBody)
-	}
-	if appErr != nil {
-		rw.SetApplicationError()
-		return appErr
-	}
-	return writeErr
-}
-
-func (r rawOnewayHandler) HandleOneway(ctx context.Context, treq *transport.Request) error {
-	if err := errors.ExpectEncodings(treq, Encoding); err != nil {
-		return err
-	}
+// rawStreamHandler adapts a StreamHandler into a transport.StreamHandler
+type rawStreamHandler struct{ StreamHandler }
 
-	ctx, call := encodingapi.NewInboundCall(ctx)
-	if err := call.ReadFromRequest(treq); err != nil {
+func (r rawStreamHandler) HandleStream(stream *transport.ServerStream) error {
+	meta := stream.Request().Meta
+	if err := errors.ExpectEncodings(meta.ToRequest(), Encoding); err != nil {
 		return err
 	}
 
-	reqBody, err := ioutil.ReadAll(treq.Body)
-	if err != nil {
+	ctx, call := encodingapi.NewInboundCallWithOptions(stream.Context(), encodingapi.DisableResponseHeaders())
+	if err := call.ReadFromRequestMeta(meta); err != nil {
 		return err
 	}
 
-	return r.OnewayHandler(ctx, reqBody)
+	return r.StreamHandler(&ServerStream{ctx: ctx, stream: stream})
 }
@@ -112,7 +112,7 @@ func TestRawHandler(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		handler := rawUnaryHandler{tt.handler}
+		handler := Procedure(tt.procedure, tt.handler)[0].HandlerSpec.Unary()
 		resw := new(transporttest.FakeResponseWriter)
 
 		writer, chunkReader := testreader.ChunkReader()
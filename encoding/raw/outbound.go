@@ -21,14 +21,13 @@
 package raw
 
 import (
-	"bytes"
 	"context"
-	"io/ioutil"
 
 	"go.uber.org/yarpc"
 	encodingapi "go.uber.org/yarpc/api/encoding"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/pkg/encoding"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // Client makes Raw requests to a single service.
@@ -38,75 +37,64 @@ type Client interface {
 
 	// CallOneway performs a oneway outbound Raw request.
 	CallOneway(ctx context.Context, procedure string, body []byte, opts ...yarpc.CallOption) (transport.Ack, error)
+
+	// CallStream opens a stream to the given procedure. The client must
+	// have been built from a ClientConfig with a stream outbound.
+	CallStream(ctx context.Context, procedure string, opts ...yarpc.CallOption) (*ClientStream, error)
 }
 
 // New builds a new Raw client.
 func New(c transport.ClientConfig) Client {
-	return rawClient{cc: c}
+	return rawClient{client: encoding.NewClient(rawCodec{}, c), cc: c}
 }
 
 func init() {
 	yarpc.RegisterClientBuilder(New)
 }
 
+// rawClient makes unary and oneway requests with the raw codec.
 type rawClient struct {
-	cc transport.ClientConfig
+	client encoding.Client
+	cc     transport.ClientConfig
 }
 
 func (c rawClient) Call(ctx context.Context, procedure string, body []byte, opts ...yarpc.CallOption) ([]byte, error) {
-	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
-	treq := transport.Request{
-		Caller:    c.cc.Caller(),
-		Service:   c.cc.Service(),
-		Procedure: procedure,
-		Encoding:  Encoding,
-		Body:      bytes.NewReader(body),
-	}
+	var resBody []byte
+	err := c.client.Call(ctx, procedure, body, &resBody, opts...)
+	return resBody, err
+}
 
-	ctx, err := call.WriteToRequest(ctx, &treq)
-	if err != nil {
-		return nil, err
-	}
+func (c rawClient) CallOneway(ctx context.Context, procedure string, body []byte, opts ...yarpc.CallOption) (transport.Ack, error) {
+	return c.client.CallOneway(ctx, procedure, body, opts...)
+}
 
-	tres, appErr := c.cc.GetUnaryOutbound().Call(ctx, &treq)
-	if tres == nil {
-		return nil, appErr
-	}
-	if tres.Body != nil {
-		defer tres.Body.Close()
+func (c rawClient) CallStream(ctx context.Context, procedure string, opts ...yarpc.CallOption) (*ClientStream, error) {
+	// Only OutboundConfigs know about stream outbounds.
+	oc, ok := c.cc.(*transport.OutboundConfig)
+	if !ok || oc.Outbounds.Stream == nil {
+		return nil, yarpcerrors.InternalErrorf("no stream outbounds for service %q", c.cc.Service())
 	}
 
-	if _, err = call.ReadFromResponse(ctx, tres); err != nil {
+	call, err := encodingapi.NewStreamOutboundCall(encoding.FromOptions(opts)...)
+	if err != nil {
 		return nil, err
 	}
-
-	// we want to return the appErr if it exists as this is what
-	// the previous behavior was so we deprioritize this error
-	var resBody []byte
-	var readErr error
-	if tres.Body != nil {
-		resBody, readErr = ioutil.ReadAll(tres.Body)
+	sreq := transport.StreamRequest{
+		Meta: &transport.RequestMeta{
+			Caller:    c.cc.Caller(),
+			Service:   c.cc.Service(),
+			Procedure: procedure,
+			Encoding:  Encoding,
+		},
 	}
-	if appErr != nil {
-		return resBody, appErr
-	}
-	return resBody, readErr
-}
-
-func (c rawClient) CallOneway(ctx context.Context, procedure string, body []byte, opts ...yarpc.CallOption) (transport.Ack, error) {
-	call := encodingapi.NewOutboundCall(encoding.FromOptions(opts)...)
-	treq := transport.Request{
-		Caller:    c.cc.Caller(),
-		Service:   c.cc.Service(),
-		Procedure: procedure,
-		Encoding:  Encoding,
-		Body:      bytes.NewReader(body),
+	ctx, err = call.WriteToRequestMeta(ctx, sreq.Meta)
+	if err != nil {
+		return nil, err
 	}
 
-	ctx, err := call.WriteToRequest(ctx, &treq)
+	stream, err := oc.Outbounds.Stream.CallStream(ctx, &sreq)
 	if err != nil {
 		return nil, err
 	}
-
-	return c.cc.GetOnewayOutbound().CallOneway(ctx, &treq)
+	return &ClientStream{stream: stream}, nil
 }
@@ -24,6 +24,7 @@ import (
 	"context"
 
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/pkg/encoding"
 )
 
 // Register calls the RouteTable's Register method.
@@ -41,12 +42,7 @@ type UnaryHandler func(context.Context, []byte) ([]byte, error)
 
 // Procedure builds a Procedure from the given raw handler.
 func Procedure(name string, handler UnaryHandler) []transport.Procedure {
-	return []transport.Procedure{
-		{
-			Name:        name,
-			HandlerSpec: transport.NewUnaryHandlerSpec(rawUnaryHandler{handler}),
-		},
-	}
+	return encoding.Procedure(rawCodec{}, name, handler)
 }
 
 // OnewayHandler implements a single, onweway procedure
@@ -54,10 +50,18 @@ type OnewayHandler func(context.Context, []byte) error
 
 // OnewayProcedure builds a Procedure from the given raw handler
 func OnewayProcedure(name string, handler OnewayHandler) []transport.Procedure {
+	return encoding.OnewayProcedure(rawCodec{}, name, handler)
+}
+
+// StreamHandler implements a single, streaming procedure.
+type StreamHandler func(*ServerStream) error
+
+// StreamProcedure builds a Procedure from the given raw stream handler.
+func StreamProcedure(name string, handler StreamHandler) []transport.Procedure {
 	return []transport.Procedure{
 		{
 			Name:        name,
-			HandlerSpec: transport.NewOnewayHandlerSpec(rawOnewayHandler{handler}),
+			HandlerSpec: transport.NewStreamHandlerSpec(rawStreamHandler{handler}),
 		},
 	}
 }
@@ -103,6 +103,22 @@
 // 	var h handler
 // 	yarpc.Injectclients(dispatcher, &h)
 //
+// Error Codes for Exceptions
+//
+// Exceptions thrown by handlers are sent to callers as application errors, and
+// generated clients return them as the same typed exceptions. To let callers
+// and observability middleware tell what kind of failure an exception
+// represents, annotate it with the YARPC error code it corresponds to.
+//
+// 	exception KeyDoesNotExist {
+// 		1: optional string key
+// 	} (rpc.code = "NOT_FOUND")
+//
+// The generated server sends the code along with the exception, and the code
+// is available to outbound middleware as the ApplicationErrorCode of the
+// transport.Response. Failed calls with a code are counted in metrics as
+// caller or server failures by their code, like other YARPC errors.
+//
 // Calling Existing Apache Thrift Services
 //
 // You can call existing Apache Thrift services with YARPC by passing in the
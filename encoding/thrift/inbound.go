@@ -30,6 +30,7 @@ import (
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/internal/bufferpool"
 	"go.uber.org/yarpc/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // thriftUnaryHandler wraps a Thrift Handler into a transport.UnaryHandler
@@ -74,6 +75,9 @@ func (t thriftUnaryHandler) Handle(ctx context.Context, treq *transport.Request,
 
 	if res.IsApplicationError {
 		rw.SetApplicationError()
+		if setter, ok := rw.(transport.ApplicationErrorCodeSetter); ok && res.ApplicationErrorCode != yarpcerrors.CodeOK {
+			setter.SetApplicationErrorCode(res.ApplicationErrorCode)
+		}
 	}
 
 	if err := call.WriteToResponse(rw); err != nil {
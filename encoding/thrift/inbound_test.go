@@ -34,6 +34,7 @@ import (
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/api/transport/transporttest"
 	"go.uber.org/yarpc/internal/testtime"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 func TestDecodeRequest(t *testing.T) {
@@ -112,6 +113,33 @@ func TestDecodeRequestApplicationError(t *testing.T) {
 	assert.NoError(t, err, "unexpected error")
 }
 
+func TestDecodeRequestApplicationErrorCode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	proto := thrifttest.NewMockEnvelopeAgnosticProtocol(mockCtrl)
+	proto.EXPECT().DecodeRequest(wire.Call, gomock.Any()).Return(
+		wire.NewValueStruct(wire.Struct{}), protocol.NoEnvelopeResponder, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testtime.Second)
+	defer cancel()
+
+	handler := func(ctx context.Context, w wire.Value) (Response, error) {
+		return Response{
+			Body:                 fakeEnveloper(wire.Reply),
+			IsApplicationError:   true,
+			ApplicationErrorCode: yarpcerrors.CodeNotFound,
+		}, nil
+	}
+	h := thriftUnaryHandler{Protocol: proto, UnaryHandler: handler}
+
+	rw := new(transporttest.FakeResponseWriter)
+	err := h.Handle(ctx, request(), rw)
+	assert.NoError(t, err, "unexpected error")
+	assert.True(t, rw.IsApplicationError, "application error bit unset")
+	assert.Equal(t, yarpcerrors.CodeNotFound, rw.ApplicationErrorCode, "application error code unset")
+}
+
 func TestDecodeRequestEncodingError(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -20,11 +20,19 @@
 
 package thrift
 
-import "go.uber.org/thriftrw/envelope"
+import (
+	"go.uber.org/thriftrw/envelope"
+	"go.uber.org/yarpc/yarpcerrors"
+)
 
 // Response contains the raw response from a generated Thrift handler.
 type Response struct {
 	Body envelope.Enveloper
 
 	IsApplicationError bool
+
+	// ApplicationErrorCode is the code of the exception in an application
+	// error, as specified by the rpc.code annotation on the exception type,
+	// or CodeOK if the exception has no code.
+	ApplicationErrorCode yarpcerrors.Code
 }
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/thriftrw/plugin/api"
+)
+
+// _codeAnnotation is the annotation on Thrift exceptions that specifies the
+// YARPC error code of the exception.
+//
+//   exception KeyDoesNotExist {
+//     1: optional string key
+//   } (rpc.code = "NOT_FOUND")
+const _codeAnnotation = "rpc.code"
+
+// _codeConstants maps the values of the rpc.code annotation to the names of
+// the yarpcerrors constants for those codes.
+var _codeConstants = map[string]string{
+	"CANCELLED":           "CodeCancelled",
+	"UNKNOWN":             "CodeUnknown",
+	"INVALID_ARGUMENT":    "CodeInvalidArgument",
+	"DEADLINE_EXCEEDED":   "CodeDeadlineExceeded",
+	"NOT_FOUND":           "CodeNotFound",
+	"ALREADY_EXISTS":      "CodeAlreadyExists",
+	"PERMISSION_DENIED":   "CodePermissionDenied",
+	"RESOURCE_EXHAUSTED":  "CodeResourceExhausted",
+	"FAILED_PRECONDITION": "CodeFailedPrecondition",
+	"ABORTED":             "CodeAborted",
+	"OUT_OF_RANGE":        "CodeOutOfRange",
+	"UNIMPLEMENTED":       "CodeUnimplemented",
+	"INTERNAL":            "CodeInternal",
+	"UNAVAILABLE":         "CodeUnavailable",
+	"DATA_LOSS":           "CodeDataLoss",
+	"UNAUTHENTICATED":     "CodeUnauthenticated",
+}
+
+// exceptionCode is an exception thrown by a function that specifies a YARPC
+// error code.
+type exceptionCode struct {
+	// Type of the exception.
+	Type *api.Type
+
+	// Name of the yarpcerrors constant for the code, like CodeNotFound.
+	Code string
+}
+
+// exceptionCodes returns the exceptions thrown by the given function that
+// specify a YARPC error code with the rpc.code annotation.
+//
+// Codes may be written as in "NOT_FOUND" or "not-found".
+func exceptionCodes(f *api.Function) ([]exceptionCode, error) {
+	var codes []exceptionCode
+	for _, e := range f.Exceptions {
+		ref := e.Type.ReferenceType
+		if e.Type.PointerType != nil {
+			ref = e.Type.PointerType.ReferenceType
+		}
+		if ref == nil {
+			continue
+		}
+
+		value, ok := ref.Annotations[_codeAnnotation]
+		if !ok {
+			continue
+		}
+		constant, ok := _codeConstants[strings.ToUpper(strings.Replace(value, "-", "_", -1))]
+		if !ok {
+			return nil, fmt.Errorf(
+				"exception %q thrown by %q has unknown %s %q", ref.Name, f.ThriftName, _codeAnnotation, value)
+		}
+		codes = append(codes, exceptionCode{Type: e.Type, Code: constant})
+	}
+	return codes, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/thriftrw/plugin/api"
+)
+
+func TestExceptionCodes(t *testing.T) {
+	exception := func(name, code string) *api.Argument {
+		ref := &api.TypeReference{Name: name, ImportPath: "example.com/foo"}
+		if code != "" {
+			ref.Annotations = map[string]string{"rpc.code": code}
+		}
+		return &api.Argument{
+			Name: name,
+			Type: &api.Type{PointerType: &api.Type{ReferenceType: ref}},
+		}
+	}
+
+	notFound := exception("NotFound", "NOT_FOUND")
+	badRequest := exception("BadRequest", "invalid-argument")
+	f := &api.Function{
+		ThriftName: "get",
+		Exceptions: []*api.Argument{
+			notFound,
+			exception("Unannotated", ""),
+			badRequest,
+		},
+	}
+
+	codes, err := exceptionCodes(f)
+	require.NoError(t, err)
+	assert.Equal(t, []exceptionCode{
+		{Type: notFound.Type, Code: "CodeNotFound"},
+		{Type: badRequest.Type, Code: "CodeInvalidArgument"},
+	}, codes)
+
+	codes, err = exceptionCodes(&api.Function{ThriftName: "noExceptions"})
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+
+	_, err = exceptionCodes(&api.Function{
+		ThriftName: "get",
+		Exceptions: []*api.Argument{exception("Teapot", "IM_A_TEAPOT")},
+	})
+	assert.EqualError(t, err, `exception "Teapot" thrown by "get" has unknown rpc.code "IM_A_TEAPOT"`)
+}
@@ -2,7 +2,7 @@ include "./common.thrift"
 
 exception KeyDoesNotExist {
     1: optional string key
-}
+} (rpc.code = "NOT_FOUND")
 
 exception IntegerMismatchError {
     1: required i64 expectedValue
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/yarpc/encoding/thrift"
 	"go.uber.org/yarpc/encoding/thrift/thriftrw-plugin-yarpc/internal/tests/atomic"
 	"go.uber.org/yarpc/encoding/thrift/thriftrw-plugin-yarpc/internal/tests/common/baseserviceserver"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // Interface is the server-side interface for the ReadOnlyStore service.
@@ -73,12 +74,17 @@ func (h handler) Integer(ctx context.Context, body wire.Value) (thrift.Response,
 	success, err := h.impl.Integer(ctx, args.Key)
 
 	hadError := err != nil
+	appErr := err
 	result, err := atomic.ReadOnlyStore_Integer_Helper.WrapResponse(success, err)
 
 	var response thrift.Response
 	if err == nil {
 		response.IsApplicationError = hadError
 		response.Body = result
+		switch appErr.(type) {
+		case *atomic.KeyDoesNotExist:
+			response.ApplicationErrorCode = yarpcerrors.CodeNotFound
+		}
 	}
 	return response, err
 }
@@ -142,13 +142,18 @@ func (h handler) <.Name>(ctx <$context>.Context, body <$wire>.Value) (<$thrift>.
 		err := h.impl.<.Name>(ctx, <range .Arguments>args.<.Name>,<end>)
 	<end>
 
-	hadError := err != nil
+	<$codes := exceptionCodes .>hadError := err != nil<if $codes>
+	appErr := err<end>
 	result, err := <$prefix>Helper.WrapResponse(<if .ReturnType>success,<end> err)
 
 	var response <$thrift>.Response
 	if err == nil {
 		response.IsApplicationError = hadError
-		response.Body = result
+		response.Body = result<if $codes>
+		switch appErr.(type) {<range $codes>
+		case <formatType .Type>:
+			response.ApplicationErrorCode = <import "go.uber.org/yarpc/yarpcerrors">.<.Code><end>
+		}<end>
 	}
 	return response, err
 }
@@ -134,4 +134,5 @@ type genFunc func(*templateData, map[string][]byte) error
 // Default options for the template
 var templateOptions = []plugin.TemplateOption{
 	plugin.TemplateFunc("lower", strings.ToLower),
+	plugin.TemplateFunc("exceptionCodes", exceptionCodes),
 }
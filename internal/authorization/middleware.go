@@ -0,0 +1,206 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package authorization provides inbound middleware that restricts which
+// callers may invoke which procedures.
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/zap"
+)
+
+// Action is the outcome of a Rule.
+type Action int
+
+const (
+	// Deny rejects requests with a CodePermissionDenied error.
+	Deny Action = iota
+
+	// Allow lets requests through to the handler.
+	Allow
+)
+
+// String returns "allow" or "deny".
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	default:
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+}
+
+// Rule matches requests by caller, service, procedure and encoding.
+//
+// Callers, Services and Procedures are lists of glob patterns in the syntax
+// accepted by path.Match. Encodings are matched exactly. An empty list
+// matches all requests.
+type Rule struct {
+	Action     Action
+	Callers    []string
+	Services   []string
+	Procedures []string
+	Encodings  []transport.Encoding
+}
+
+// Validate checks that all patterns in the rule are well-formed.
+func (r *Rule) Validate() error {
+	for _, patterns := range [][]string{r.Callers, r.Services, r.Procedures} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %v", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Rule) matches(req *transport.Request) bool {
+	return matchAny(r.Callers, req.Caller) &&
+		matchAny(r.Services, req.Service) &&
+		matchAny(r.Procedures, req.Procedure) &&
+		matchEncoding(r.Encodings, req.Encoding)
+}
+
+func matchAny(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		// Patterns were validated when the middleware was built so the error
+		// may be ignored here.
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchEncoding(encodings []transport.Encoding, e transport.Encoding) bool {
+	if len(encodings) == 0 {
+		return true
+	}
+	for _, enc := range encodings {
+		if enc == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures the authorization middleware.
+type Config struct {
+	// Logger to which denied requests will be logged.
+	Logger *zap.Logger
+
+	// Rules are evaluated in order. The first matching rule decides
+	// whether a request is allowed.
+	Rules []Rule
+
+	// Action taken for requests that match none of the rules.
+	Default Action
+}
+
+// Middleware is authorization middleware for all inbound RPC types.
+type Middleware struct {
+	log           *zap.Logger
+	rules         []Rule
+	defaultAction Action
+}
+
+// NewMiddleware constructs an authorization middleware with the provided
+// configuration. An error is returned if any of the rules is invalid.
+func NewMiddleware(cfg Config) (*Middleware, error) {
+	for i, r := range cfg.Rules {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid authorization rule %d: %v", i, err)
+		}
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Middleware{
+		log:           logger,
+		rules:         cfg.Rules,
+		defaultAction: cfg.Default,
+	}, nil
+}
+
+// authorize returns a CodePermissionDenied error if the request is not
+// allowed by the configured rules.
+func (m *Middleware) authorize(req *transport.Request) error {
+	action, rule := m.defaultAction, zap.String("rule", "default")
+	for i := range m.rules {
+		if m.rules[i].matches(req) {
+			action, rule = m.rules[i].Action, zap.Int("rule", i)
+			break
+		}
+	}
+
+	if action == Allow {
+		return nil
+	}
+
+	m.log.Warn("Denied unauthorized inbound request.",
+		zap.String("source", req.Caller),
+		zap.String("dest", req.Service),
+		zap.String("procedure", req.Procedure),
+		zap.String("encoding", string(req.Encoding)),
+		rule,
+	)
+	return yarpcerrors.Newf(yarpcerrors.CodePermissionDenied,
+		"caller %q is not authorized to call procedure %q of service %q",
+		req.Caller, req.Procedure, req.Service)
+}
+
+// Handle implements middleware.UnaryInbound.
+func (m *Middleware) Handle(ctx context.Context, req *transport.Request, w transport.ResponseWriter, h transport.UnaryHandler) error {
+	if err := m.authorize(req); err != nil {
+		return err
+	}
+	return h.Handle(ctx, req, w)
+}
+
+// HandleOneway implements middleware.OnewayInbound.
+func (m *Middleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	if err := m.authorize(req); err != nil {
+		return err
+	}
+	return h.HandleOneway(ctx, req)
+}
+
+// HandleStream implements middleware.StreamInbound.
+func (m *Middleware) HandleStream(serverStream *transport.ServerStream, h transport.StreamHandler) error {
+	if err := m.authorize(serverStream.Request().Meta.ToRequest()); err != nil {
+		return err
+	}
+	return h.HandleStream(serverStream)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewMiddlewareInvalidRule(t *testing.T) {
+	_, err := NewMiddleware(Config{
+		Rules: []Rule{
+			{Action: Allow, Callers: []string{"foo"}},
+			{Action: Deny, Procedures: []string{"[foo"}},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid authorization rule 1")
+	assert.Contains(t, err.Error(), `invalid pattern "[foo"`)
+}
+
+func TestAuthorize(t *testing.T) {
+	rules := []Rule{
+		{Action: Deny, Callers: []string{"blocked"}},
+		{Action: Allow, Callers: []string{"frontend", "batch-*"}, Procedures: []string{"KeyValue::get*"}},
+		{Action: Allow, Services: []string{"admin"}, Encodings: []transport.Encoding{"thrift"}},
+	}
+
+	tests := []struct {
+		desc          string
+		defaultAction Action
+		req           transport.Request
+		wantDenied    bool
+		wantRule      zapcore.Field
+	}{
+		{
+			desc:     "allowed by glob",
+			req:      transport.Request{Caller: "batch-1", Service: "kv", Procedure: "KeyValue::getValue"},
+			wantRule: zap.Int("rule", 1),
+		},
+		{
+			desc:       "denied by first matching rule",
+			req:        transport.Request{Caller: "blocked", Service: "kv", Procedure: "KeyValue::getValue"},
+			wantDenied: true,
+			wantRule:   zap.Int("rule", 0),
+		},
+		{
+			desc:       "procedure does not match",
+			req:        transport.Request{Caller: "frontend", Service: "kv", Procedure: "KeyValue::setValue"},
+			wantDenied: true,
+			wantRule:   zap.String("rule", "default"),
+		},
+		{
+			desc:     "encoding matches",
+			req:      transport.Request{Caller: "anyone", Service: "admin", Procedure: "Admin::reset", Encoding: "thrift"},
+			wantRule: zap.Int("rule", 2),
+		},
+		{
+			desc:       "encoding does not match",
+			req:        transport.Request{Caller: "anyone", Service: "admin", Procedure: "Admin::reset", Encoding: "json"},
+			wantDenied: true,
+			wantRule:   zap.String("rule", "default"),
+		},
+		{
+			desc:          "allowed by default",
+			defaultAction: Allow,
+			req:           transport.Request{Caller: "anyone", Service: "kv", Procedure: "KeyValue::setValue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			mw, err := NewMiddleware(Config{
+				Logger:  zap.New(core),
+				Rules:   rules,
+				Default: tt.defaultAction,
+			})
+			require.NoError(t, err)
+
+			err = mw.authorize(&tt.req)
+			if !tt.wantDenied {
+				assert.NoError(t, err)
+				assert.Equal(t, 0, logs.Len(), "expected no logs for allowed requests")
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, yarpcerrors.CodePermissionDenied, yarpcerrors.FromError(err).Code())
+
+			entries := logs.TakeAll()
+			require.Len(t, entries, 1)
+			assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+			assert.Equal(t, "Denied unauthorized inbound request.", entries[0].Message)
+			assert.Contains(t, entries[0].Context, tt.wantRule)
+			assert.Contains(t, entries[0].Context, zap.String("source", tt.req.Caller))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mw, err := NewMiddleware(Config{
+		Rules: []Rule{{Action: Allow, Callers: []string{"good"}}},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	good := &transport.Request{Caller: "good", Service: "svc", Procedure: "proc"}
+	bad := &transport.Request{Caller: "bad", Service: "svc", Procedure: "proc"}
+
+	t.Run("unary", func(t *testing.T) {
+		h := transporttest.NewMockUnaryHandler(mockCtrl)
+		h.EXPECT().Handle(ctx, good, nil).Return(nil)
+
+		assert.NoError(t, mw.Handle(ctx, good, nil, h))
+		assert.Equal(t, yarpcerrors.CodePermissionDenied,
+			yarpcerrors.FromError(mw.Handle(ctx, bad, nil, h)).Code())
+	})
+
+	t.Run("oneway", func(t *testing.T) {
+		h := transporttest.NewMockOnewayHandler(mockCtrl)
+		h.EXPECT().HandleOneway(ctx, good).Return(nil)
+
+		assert.NoError(t, mw.HandleOneway(ctx, good, h))
+		assert.Equal(t, yarpcerrors.CodePermissionDenied,
+			yarpcerrors.FromError(mw.HandleOneway(ctx, bad, h)).Code())
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		newServerStream := func(req *transport.Request) *transport.ServerStream {
+			stream := transporttest.NewMockStream(mockCtrl)
+			stream.EXPECT().Request().Return(&transport.StreamRequest{Meta: req.ToRequestMeta()}).AnyTimes()
+			stream.EXPECT().Context().Return(ctx).AnyTimes()
+			ss, err := transport.NewServerStream(stream)
+			require.NoError(t, err)
+			return ss
+		}
+
+		goodStream := newServerStream(good)
+		h := transporttest.NewMockStreamHandler(mockCtrl)
+		h.EXPECT().HandleStream(goodStream).Return(nil)
+
+		assert.NoError(t, mw.HandleStream(goodStream, h))
+		assert.Equal(t, yarpcerrors.CodePermissionDenied,
+			yarpcerrors.FromError(mw.HandleStream(newServerStream(bad), h)).Code())
+	})
+}
@@ -62,11 +62,12 @@ type EchoYARPCServer interface {
 }
 
 // BuildEchoYARPCProcedures prepares an implementation of the Echo service for YARPC registration.
-func BuildEchoYARPCProcedures(server EchoYARPCServer) []transport.Procedure {
+func BuildEchoYARPCProcedures(server EchoYARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_EchoYARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName: "uber.yarpc.internal.crossdock.Echo",
+			Options:     options,
 			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{
 				{
 					MethodName: "Echo",
@@ -235,11 +236,12 @@ type OnewayYARPCServer interface {
 }
 
 // BuildOnewayYARPCProcedures prepares an implementation of the Oneway service for YARPC registration.
-func BuildOnewayYARPCProcedures(server OnewayYARPCServer) []transport.Procedure {
+func BuildOnewayYARPCProcedures(server OnewayYARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_OnewayYARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName:        "uber.yarpc.internal.crossdock.Oneway",
+			Options:            options,
 			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{},
 			OnewayHandlerParams: []protobuf.BuildProceduresOnewayHandlerParams{
 				{
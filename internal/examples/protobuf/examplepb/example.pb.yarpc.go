@@ -64,11 +64,12 @@ type KeyValueYARPCServer interface {
 }
 
 // BuildKeyValueYARPCProcedures prepares an implementation of the KeyValue service for YARPC registration.
-func BuildKeyValueYARPCProcedures(server KeyValueYARPCServer) []transport.Procedure {
+func BuildKeyValueYARPCProcedures(server KeyValueYARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_KeyValueYARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName: "uber.yarpc.internal.examples.protobuf.example.KeyValue",
+			Options:     options,
 			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{
 				{
 					MethodName: "GetValue",
@@ -284,11 +285,12 @@ type SinkYARPCServer interface {
 }
 
 // BuildSinkYARPCProcedures prepares an implementation of the Sink service for YARPC registration.
-func BuildSinkYARPCProcedures(server SinkYARPCServer) []transport.Procedure {
+func BuildSinkYARPCProcedures(server SinkYARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_SinkYARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName:        "uber.yarpc.internal.examples.protobuf.example.Sink",
+			Options:            options,
 			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{},
 			OnewayHandlerParams: []protobuf.BuildProceduresOnewayHandlerParams{
 				{
@@ -490,11 +492,12 @@ type FooServiceEchoBothYARPCServer interface {
 }
 
 // BuildFooYARPCProcedures prepares an implementation of the Foo service for YARPC registration.
-func BuildFooYARPCProcedures(server FooYARPCServer) []transport.Procedure {
+func BuildFooYARPCProcedures(server FooYARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_FooYARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName:         "uber.yarpc.internal.examples.protobuf.example.Foo",
+			Options:             options,
 			UnaryHandlerParams:  []protobuf.BuildProceduresUnaryHandlerParams{},
 			OnewayHandlerParams: []protobuf.BuildProceduresOnewayHandlerParams{},
 			StreamHandlerParams: []protobuf.BuildProceduresStreamHandlerParams{
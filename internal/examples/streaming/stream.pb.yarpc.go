@@ -108,11 +108,12 @@ type HelloServiceHelloThereYARPCServer interface {
 }
 
 // BuildHelloYARPCProcedures prepares an implementation of the Hello service for YARPC registration.
-func BuildHelloYARPCProcedures(server HelloYARPCServer) []transport.Procedure {
+func BuildHelloYARPCProcedures(server HelloYARPCServer, options ...protobuf.BuildProceduresOption) []transport.Procedure {
 	handler := &_HelloYARPCHandler{server}
 	return protobuf.BuildProcedures(
 		protobuf.BuildProceduresParams{
 			ServiceName: "uber.yarpc.internal.examples.streaming.Hello",
+			Options:     options,
 			UnaryHandlerParams: []protobuf.BuildProceduresUnaryHandlerParams{
 				{
 					MethodName: "HelloUnary",
@@ -32,6 +32,12 @@ type ChooserStatus struct {
 	Peers []PeerStatus `json:"peers"`
 }
 
+// IntrospectablePeer is a peer that describes its own state, for peers whose
+// state is more than their status, like peers ejected as outliers.
+type IntrospectablePeer interface {
+	Introspect() PeerStatus
+}
+
 // PeerStatus is a collection of basic peers info.
 type PeerStatus struct {
 	Identifier string `json:"identifier"`
@@ -32,6 +32,7 @@ import (
 
 const (
 	_error              = "error"
+	_errorCode          = "errorCode"
 	_successfulInbound  = "Handled inbound request."
 	_successfulOutbound = "Made outbound call."
 	_errorInbound       = "Error handling inbound request."
@@ -45,7 +46,7 @@ const (
 type call struct {
 	edge    *edge
 	extract ContextExtractor
-	fields  [5]zapcore.Field
+	fields  [6]zapcore.Field
 
 	started   time.Time
 	ctx       context.Context
@@ -57,16 +58,19 @@ type call struct {
 }
 
 func (c call) End(err error) {
-	c.EndWithAppError(err, false)
+	c.EndWithAppError(err, false, yarpcerrors.CodeOK)
 }
 
-func (c call) EndWithAppError(err error, isApplicationError bool) {
+// EndWithAppError ends the call, which may have failed with an application
+// error. The code of the application error is CodeOK if the handler didn't
+// specify one.
+func (c call) EndWithAppError(err error, isApplicationError bool, appErrCode yarpcerrors.Code) {
 	elapsed := _timeNow().Sub(c.started)
-	c.endLogs(elapsed, err, isApplicationError)
-	c.endStats(elapsed, err, isApplicationError)
+	c.endLogs(elapsed, err, isApplicationError, appErrCode)
+	c.endStats(elapsed, err, isApplicationError, appErrCode)
 }
 
-func (c call) endLogs(elapsed time.Duration, err error, isApplicationError bool) {
+func (c call) endLogs(elapsed time.Duration, err error, isApplicationError bool, appErrCode yarpcerrors.Code) {
 	var ce *zapcore.CheckedEntry
 	if err == nil && !isApplicationError {
 		msg := _successfulInbound
@@ -98,13 +102,16 @@ func (c call) endLogs(elapsed time.Duration, err error, isApplicationError bool)
 	fields = append(fields, c.extract(c.ctx))
 	if isApplicationError {
 		fields = append(fields, zap.String(_error, "application_error"))
+		if appErrCode != yarpcerrors.CodeOK {
+			fields = append(fields, zap.String(_errorCode, appErrCode.String()))
+		}
 	} else {
 		fields = append(fields, zap.Error(err))
 	}
 	ce.Write(fields...)
 }
 
-func (c call) endStats(elapsed time.Duration, err error, isApplicationError bool) {
+func (c call) endStats(elapsed time.Duration, err error, isApplicationError bool, appErrCode yarpcerrors.Code) {
 	// TODO: We need a much better way to distinguish between caller and server
 	// errors. See T855583.
 	c.edge.calls.Inc()
@@ -113,7 +120,13 @@ func (c call) endStats(elapsed time.Duration, err error, isApplicationError bool
 		c.edge.latencies.Observe(elapsed)
 		return
 	}
-	// For now, assume that all application errors are the caller's fault.
+	// Application errors with a code are classified by their code, like
+	// other errors. For now, assume that all other application errors are
+	// the caller's fault.
+	if isApplicationError && appErrCode != yarpcerrors.CodeOK {
+		c.observeErrorCode(elapsed, appErrCode)
+		return
+	}
 	if isApplicationError {
 		c.edge.callerErrLatencies.Observe(elapsed)
 		if counter, err := c.edge.callerFailures.Get(_error, "application_error"); err == nil {
@@ -130,7 +143,12 @@ func (c call) endStats(elapsed time.Duration, err error, isApplicationError bool
 		return
 	}
 
-	errCode := yarpcerrors.FromError(err).Code()
+	c.observeErrorCode(elapsed, yarpcerrors.FromError(err).Code())
+}
+
+// observeErrorCode records a failed call as the fault of the caller or the
+// server, depending on its error code.
+func (c call) observeErrorCode(elapsed time.Duration, errCode yarpcerrors.Code) {
 	switch errCode {
 	case yarpcerrors.CodeCancelled,
 		yarpcerrors.CodeInvalidArgument,
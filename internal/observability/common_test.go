@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type fakeAck struct{}
@@ -34,11 +35,16 @@ func (a fakeAck) String() string { return "" }
 type fakeHandler struct {
 	err            error
 	applicationErr bool
+	// Code of the application error, if any.
+	applicationErrCode yarpcerrors.Code
 }
 
 func (h fakeHandler) Handle(_ context.Context, _ *transport.Request, rw transport.ResponseWriter) error {
 	if h.applicationErr {
 		rw.SetApplicationError()
+		if h.applicationErrCode != yarpcerrors.CodeOK {
+			rw.(transport.ApplicationErrorCodeSetter).SetApplicationErrorCode(h.applicationErrCode)
+		}
 		return nil
 	}
 	return h.err
@@ -55,15 +61,19 @@ func (h fakeHandler) HandleStream(*transport.ServerStream) error {
 type fakeOutbound struct {
 	transport.Outbound
 
-	err            error
-	applicationErr bool
+	err                error
+	applicationErr     bool
+	applicationErrCode yarpcerrors.Code
 }
 
 func (o fakeOutbound) Call(context.Context, *transport.Request) (*transport.Response, error) {
 	if o.err != nil {
 		return nil, o.err
 	}
-	return &transport.Response{ApplicationError: o.applicationErr}, nil
+	return &transport.Response{
+		ApplicationError:     o.applicationErr,
+		ApplicationErrorCode: o.applicationErrCode,
+	}, nil
 }
 
 func (o fakeOutbound) CallOneway(context.Context, *transport.Request) (transport.Ack, error) {
@@ -26,6 +26,7 @@ import (
 
 	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -35,16 +36,18 @@ var _writerPool = sync.Pool{New: func() interface{} {
 }}
 
 // writer wraps a transport.ResponseWriter so the observing middleware can
-// detect application errors.
+// detect application errors and their codes.
 type writer struct {
 	transport.ResponseWriter
 
-	isApplicationError bool
+	isApplicationError   bool
+	applicationErrorCode yarpcerrors.Code
 }
 
 func newWriter(rw transport.ResponseWriter) *writer {
 	w := _writerPool.Get().(*writer)
 	w.isApplicationError = false
+	w.applicationErrorCode = yarpcerrors.CodeOK
 	w.ResponseWriter = rw
 	return w
 }
@@ -54,6 +57,13 @@ func (w *writer) SetApplicationError() {
 	w.ResponseWriter.SetApplicationError()
 }
 
+func (w *writer) SetApplicationErrorCode(code yarpcerrors.Code) {
+	w.applicationErrorCode = code
+	if setter, ok := w.ResponseWriter.(transport.ApplicationErrorCodeSetter); ok {
+		setter.SetApplicationErrorCode(code)
+	}
+}
+
 func (w *writer) free() {
 	_writerPool.Put(w)
 }
@@ -115,7 +125,7 @@ func (m *Middleware) Handle(ctx context.Context, req *transport.Request, w trans
 	call := m.graph.begin(ctx, transport.Unary, _directionInbound, req)
 	wrappedWriter := newWriter(w)
 	err := h.Handle(ctx, req, wrappedWriter)
-	call.EndWithAppError(err, wrappedWriter.isApplicationError)
+	call.EndWithAppError(err, wrappedWriter.isApplicationError, wrappedWriter.applicationErrorCode)
 	wrappedWriter.free()
 	return err
 }
@@ -126,10 +136,12 @@ func (m *Middleware) Call(ctx context.Context, req *transport.Request, out trans
 	res, err := out.Call(ctx, req)
 
 	isApplicationError := false
+	appErrCode := yarpcerrors.CodeOK
 	if res != nil {
 		isApplicationError = res.ApplicationError
+		appErrCode = res.ApplicationErrorCode
 	}
-	call.EndWithAppError(err, isApplicationError)
+	call.EndWithAppError(err, isApplicationError, appErrCode)
 	return res, err
 }
 
@@ -166,11 +166,11 @@ func TestMiddlewareLogging(t *testing.T) {
 	}
 
 	newHandler := func(t test) fakeHandler {
-		return fakeHandler{err: t.err, applicationErr: t.applicationErr}
+		return fakeHandler{err: t.err, applicationErr: t.applicationErr, applicationErrCode: t.applicationErrCode}
 	}
 
 	newOutbound := func(t test) fakeOutbound {
-		return fakeOutbound{err: t.err, applicationErr: t.applicationErr}
+		return fakeOutbound{err: t.err, applicationErr: t.applicationErr, applicationErrCode: t.applicationErrCode}
 	}
 
 	infoLevel := zapcore.InfoLevel
@@ -349,8 +349,9 @@ func TestMiddlewareMetrics(t *testing.T) {
 
 	type test struct {
 		desc               string
-		err                error // downstream error
-		applicationErr     bool  // downstream application error
+		err                error            // downstream error
+		applicationErr     bool             // downstream application error
+		applicationErrCode yarpcerrors.Code // downstream application error code
 		wantCalls          int
 		wantSuccesses      int
 		wantCallerFailures map[string]int
@@ -390,6 +391,35 @@ func TestMiddlewareMetrics(t *testing.T) {
 				"unknown_internal_yarpc": 1,
 			},
 		},
+		{
+			desc:           "application error",
+			applicationErr: true,
+			wantCalls:      1,
+			wantSuccesses:  0,
+			wantCallerFailures: map[string]int{
+				"application_error": 1,
+			},
+		},
+		{
+			desc:               "application error with server code",
+			applicationErr:     true,
+			applicationErrCode: yarpcerrors.CodeUnavailable,
+			wantCalls:          1,
+			wantSuccesses:      0,
+			wantServerFailures: map[string]int{
+				yarpcerrors.CodeUnavailable.String(): 1,
+			},
+		},
+		{
+			desc:               "application error with caller code",
+			applicationErr:     true,
+			applicationErrCode: yarpcerrors.CodeNotFound,
+			wantCalls:          1,
+			wantSuccesses:      0,
+			wantCallerFailures: map[string]int{
+				yarpcerrors.CodeNotFound.String(): 1,
+			},
+		},
 		{
 			desc:          "custom error code error",
 			err:           yarpcerrors.Newf(yarpcerrors.Code(1000), "test"),
@@ -402,11 +432,11 @@ func TestMiddlewareMetrics(t *testing.T) {
 	}
 
 	newHandler := func(t test) fakeHandler {
-		return fakeHandler{err: t.err, applicationErr: t.applicationErr}
+		return fakeHandler{err: t.err, applicationErr: t.applicationErr, applicationErrCode: t.applicationErrCode}
 	}
 
 	newOutbound := func(t test) fakeOutbound {
-		return fakeOutbound{err: t.err, applicationErr: t.applicationErr}
+		return fakeOutbound{err: t.err, applicationErr: t.applicationErr, applicationErrCode: t.applicationErrCode}
 	}
 
 	for _, tt := range tests {
@@ -547,7 +577,7 @@ func TestMiddlewareSuccessSnapshot(t *testing.T) {
 			Body:            strings.NewReader("body"),
 		},
 		&transporttest.FakeResponseWriter{},
-		fakeHandler{nil, false},
+		fakeHandler{err: nil, applicationErr: false},
 	)
 	assert.NoError(t, err, "Unexpected transport error.")
 
@@ -613,7 +643,7 @@ func TestMiddlewareFailureSnapshot(t *testing.T) {
 			Body:            strings.NewReader("body"),
 		},
 		&transporttest.FakeResponseWriter{},
-		fakeHandler{fmt.Errorf("yuno"), false},
+		fakeHandler{err: fmt.Errorf("yuno"), applicationErr: false},
 	)
 	assert.Error(t, err, "Expected transport error.")
 
@@ -0,0 +1,86 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package peerdiff computes the peer list updates that transition a peer list
+// from one set of peers to another, for use by peer list updaters.
+package peerdiff
+
+import (
+	"sort"
+
+	"go.uber.org/yarpc/api/peer"
+)
+
+// Diff returns the updates that transform the peers in old to the peers in
+// new. Both maps are keyed by peer identifier.
+//
+// Peers present in both sets whose weights or zones differ, as reported by
+// peer.WeightedIdentifier and peer.ZonedIdentifier, are reported as changes.
+// Additions, removals and changes are sorted by identifier.
+func Diff(old, new map[string]peer.Identifier) peer.ListUpdates {
+	var updates peer.ListUpdates
+	for id, pid := range new {
+		oldPID, ok := old[id]
+		switch {
+		case !ok:
+			updates.Additions = append(updates.Additions, pid)
+		case !sameAttributes(oldPID, pid):
+			updates.Changes = append(updates.Changes, pid)
+		}
+	}
+	for id, pid := range old {
+		if _, ok := new[id]; !ok {
+			updates.Removals = append(updates.Removals, pid)
+		}
+	}
+	Sort(updates.Additions)
+	Sort(updates.Removals)
+	Sort(updates.Changes)
+	return updates
+}
+
+// sameAttributes returns whether two identifiers for the same peer have the
+// same weight and zone. Identifiers are not compared with ==, which panics
+// for identifiers that are not comparable.
+func sameAttributes(a, b peer.Identifier) bool {
+	return peer.WeightOf(a) == peer.WeightOf(b) && zoneOf(a) == zoneOf(b)
+}
+
+// zoneOf returns the zone of the peer with the given identifier, or an empty
+// string if it has none.
+func zoneOf(pid peer.Identifier) string {
+	if z, ok := pid.(peer.ZonedIdentifier); ok {
+		return z.Zone()
+	}
+	return ""
+}
+
+// Sort sorts peer identifiers in place by identifier.
+func Sort(pids []peer.Identifier) {
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i].Identifier() < pids[j].Identifier()
+	})
+}
+
+// IsEmpty returns whether the updates have no additions, removals or
+// changes.
+func IsEmpty(updates peer.ListUpdates) bool {
+	return len(updates.Additions) == 0 && len(updates.Removals) == 0 && len(updates.Changes) == 0
+}
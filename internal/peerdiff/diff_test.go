@@ -0,0 +1,128 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+func peers(pids ...peer.Identifier) map[string]peer.Identifier {
+	m := make(map[string]peer.Identifier, len(pids))
+	for _, pid := range pids {
+		m[pid.Identifier()] = pid
+	}
+	return m
+}
+
+// sliceIdentifier is an identifier that can't be compared with ==.
+type sliceIdentifier struct {
+	addrs []string
+}
+
+func (s *sliceIdentifier) Identifier() string { return s.addrs[0] }
+
+type uncomparableIdentifier struct {
+	addrs []string
+	zone  string
+}
+
+func (u uncomparableIdentifier) Identifier() string { return u.addrs[0] }
+func (u uncomparableIdentifier) Zone() string       { return u.zone }
+
+func TestDiff(t *testing.T) {
+	var (
+		a        = hostport.PeerIdentifier("a:80")
+		b        = hostport.PeerIdentifier("b:80")
+		c        = hostport.PeerIdentifier("c:80")
+		weightyB = hostport.IdentifyWithAttributes("b:80", hostport.PeerAttributes{Weight: 2})
+	)
+
+	tests := []struct {
+		desc      string
+		old, new  map[string]peer.Identifier
+		want      peer.ListUpdates
+		wantEmpty bool
+	}{
+		{
+			desc:      "empty",
+			wantEmpty: true,
+		},
+		{
+			desc: "additions",
+			new:  peers(c, a),
+			want: peer.ListUpdates{Additions: []peer.Identifier{a, c}},
+		},
+		{
+			desc: "removals",
+			old:  peers(c, a),
+			want: peer.ListUpdates{Removals: []peer.Identifier{a, c}},
+		},
+		{
+			desc:      "unchanged",
+			old:       peers(a, b),
+			new:       peers(b, a),
+			wantEmpty: true,
+		},
+		{
+			desc: "changed attributes",
+			old:  peers(a, b),
+			new:  peers(a, weightyB, c),
+			want: peer.ListUpdates{
+				Additions: []peer.Identifier{c},
+				Changes:   []peer.Identifier{weightyB},
+			},
+		},
+		{
+			desc:      "rebuilt pointer identifiers",
+			old:       peers(&sliceIdentifier{addrs: []string{"a:80"}}),
+			new:       peers(&sliceIdentifier{addrs: []string{"a:80"}}),
+			wantEmpty: true,
+		},
+		{
+			desc:      "uncomparable identifiers",
+			old:       peers(uncomparableIdentifier{addrs: []string{"a:80"}, zone: "west"}),
+			new:       peers(uncomparableIdentifier{addrs: []string{"a:80"}, zone: "west"}),
+			wantEmpty: true,
+		},
+		{
+			desc: "changed zone of uncomparable identifier",
+			old:  peers(uncomparableIdentifier{addrs: []string{"a:80"}, zone: "west"}),
+			new:  peers(uncomparableIdentifier{addrs: []string{"a:80"}, zone: "east"}),
+			want: peer.ListUpdates{
+				Changes: []peer.Identifier{uncomparableIdentifier{addrs: []string{"a:80"}, zone: "east"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			updates := Diff(tt.old, tt.new)
+			assert.Equal(t, tt.wantEmpty, IsEmpty(updates))
+			if !tt.wantEmpty {
+				assert.Equal(t, tt.want, updates)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package readiness helps peer lists and choosers composed of other peer
+// lists wait until they are ready.
+package readiness
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+	"go.uber.org/yarpc/api/peer"
+)
+
+// WaitForAny blocks until any of the given waiters meets the readiness
+// criteria, or returns the errors of all waiters if none does before the
+// context finishes. Without waiters, it blocks until the context finishes.
+func WaitForAny(ctx context.Context, waiters []peer.ReadinessWaiter, r peer.Readiness) error {
+	if len(waiters) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// Stop the remaining waiters once one is ready.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error, len(waiters))
+	for _, w := range waiters {
+		go func(w peer.ReadinessWaiter) {
+			results <- w.WaitUntilReady(ctx, r)
+		}(w)
+	}
+
+	var errs error
+	for range waiters {
+		err := <-results
+		if err == nil {
+			return nil
+		}
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
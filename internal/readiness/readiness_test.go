@@ -0,0 +1,71 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package readiness
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/peer"
+)
+
+// fakeWaiter is ready once ready is closed.
+type fakeWaiter struct {
+	ready chan struct{}
+}
+
+func newFakeWaiter() *fakeWaiter {
+	return &fakeWaiter{ready: make(chan struct{})}
+}
+
+func (w *fakeWaiter) WaitUntilReady(ctx context.Context, _ peer.Readiness) error {
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		return errors.New("not ready")
+	}
+}
+
+func TestWaitForAny(t *testing.T) {
+	first, second := newFakeWaiter(), newFakeWaiter()
+	close(second.ready)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, WaitForAny(ctx, []peer.ReadinessWaiter{first, second}, peer.Readiness{}))
+}
+
+func TestWaitForAnyTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := WaitForAny(ctx, []peer.ReadinessWaiter{newFakeWaiter(), newFakeWaiter()}, peer.Readiness{})
+	assert.EqualError(t, err, "not ready; not ready")
+}
+
+func TestWaitForAnyWithoutWaiters(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, WaitForAny(ctx, nil, peer.Readiness{}))
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yarpcerrors
+
+import (
+	"encoding/base64"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	"go.uber.org/yarpc/yarpcerrors"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+)
+
+// DetailsToProto converts the details of a Status to protobuf Any messages,
+// as carried by google.rpc.Status.
+func DetailsToProto(details []yarpcerrors.Detail) []*any.Any {
+	if len(details) == 0 {
+		return nil
+	}
+	anys := make([]*any.Any, len(details))
+	for i, d := range details {
+		anys[i] = &any.Any{TypeUrl: d.TypeURL, Value: d.Value}
+	}
+	return anys
+}
+
+// DetailsFromProto converts protobuf Any messages, as carried by
+// google.rpc.Status, to the details of a Status.
+func DetailsFromProto(anys []*any.Any) []yarpcerrors.Detail {
+	if len(anys) == 0 {
+		return nil
+	}
+	details := make([]yarpcerrors.Detail, len(anys))
+	for i, a := range anys {
+		details[i] = yarpcerrors.Detail{TypeURL: a.GetTypeUrl(), Value: a.GetValue()}
+	}
+	return details
+}
+
+// EncodeDetails encodes the details of a Status for transports that carry
+// them in a header, as a base64-encoded google.rpc.Status holding only the
+// details.
+//
+// Returns an empty string if there are no details.
+func EncodeDetails(details []yarpcerrors.Detail) (string, error) {
+	if len(details) == 0 {
+		return "", nil
+	}
+	b, err := proto.Marshal(&spb.Status{Details: DetailsToProto(details)})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// DecodeDetails decodes details encoded by EncodeDetails.
+func DecodeDetails(s string) ([]yarpcerrors.Detail, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var status spb.Status
+	if err := proto.Unmarshal(b, &status); err != nil {
+		return nil, err
+	}
+	return DetailsFromProto(status.Details), nil
+}
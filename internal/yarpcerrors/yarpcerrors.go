@@ -37,7 +37,9 @@ func NewWithNamef(code yarpcerrors.Code, name string, format string, args ...int
 }
 
 // AnnotateWithInfo will take an error and add info to it's error message while
-// keeping the same status code.
+// keeping the same status code, details and retry hint.
 func AnnotateWithInfo(status *yarpcerrors.Status, format string, args ...interface{}) *yarpcerrors.Status {
-	return yarpcerrors.Newf(status.Code(), "%s: %s", fmt.Sprintf(format, args...), status.Message())
+	return yarpcerrors.Newf(status.Code(), "%s: %s", fmt.Sprintf(format, args...), status.Message()).
+		WithDetails(status.Details()...).
+		WithRetryAfter(status.RetryAfter())
 }
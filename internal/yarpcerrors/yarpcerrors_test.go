@@ -23,8 +23,10 @@ package yarpcerrors
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
@@ -51,6 +53,18 @@ func TestAnnotateWithError(t *testing.T) {
 			},
 			wantErr: yarpcerrors.FailedPreconditionErrorf("mytest arg1: test"),
 		},
+		{
+			name:       "with details",
+			giveErr:    yarpcerrors.Newf(yarpcerrors.CodeAborted, "test").WithDetails(yarpcerrors.Detail{TypeURL: "foo", Value: []byte("bar")}),
+			giveFormat: "mytest",
+			wantErr:    yarpcerrors.Newf(yarpcerrors.CodeAborted, "mytest: test").WithDetails(yarpcerrors.Detail{TypeURL: "foo", Value: []byte("bar")}),
+		},
+		{
+			name:       "with retry after",
+			giveErr:    yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "test").WithRetryAfter(time.Second),
+			giveFormat: "mytest",
+			wantErr:    yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "mytest: test").WithRetryAfter(time.Second),
+		},
 		{
 			name:       "unannotated",
 			giveErr:    errors.New("test"),
@@ -65,3 +79,40 @@ func TestAnnotateWithError(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeDetails(t *testing.T) {
+	details := []yarpcerrors.Detail{
+		{TypeURL: "type.googleapis.com/google.rpc.RetryInfo", Value: []byte{0x0a, 0x02, 0x08, 0x01}},
+		{TypeURL: "example.com/quota", Value: []byte(`{"limit":10}`)},
+	}
+
+	encoded, err := EncodeDetails(details)
+	require.NoError(t, err)
+	decoded, err := DecodeDetails(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, details, decoded)
+
+	encoded, err = EncodeDetails(nil)
+	require.NoError(t, err)
+	assert.Empty(t, encoded)
+	decoded, err = DecodeDetails("")
+	require.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	_, err = DecodeDetails("not base64!")
+	assert.Error(t, err)
+	_, err = DecodeDetails("bm90IGEgcHJvdG8=") // "not a proto"
+	assert.Error(t, err)
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, "1500", FormatRetryAfter(1500*time.Millisecond))
+	assert.Equal(t, "1", FormatRetryAfter(time.Millisecond+time.Microsecond))
+	assert.Empty(t, FormatRetryAfter(0))
+	assert.Empty(t, FormatRetryAfter(time.Microsecond))
+
+	assert.Equal(t, 1500*time.Millisecond, ParseRetryAfter("1500"))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("-5"))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("soon"))
+}
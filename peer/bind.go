@@ -66,6 +66,15 @@ func (c *BoundChooser) Choose(ctx context.Context, treq *transport.Request) (pee
 	return c.chooserList.Choose(ctx, treq)
 }
 
+// WaitUntilReady waits until the bound peer list is ready, if the peer list
+// supports readiness. Peer lists that do not are considered ready.
+func (c *BoundChooser) WaitUntilReady(ctx context.Context, r peer.Readiness) error {
+	if w, ok := c.chooserList.(peer.ReadinessWaiter); ok {
+		return w.WaitUntilReady(ctx, r)
+	}
+	return nil
+}
+
 // Start starts the peer list and the peer list updater.
 func (c *BoundChooser) Start() error {
 	return c.once.Start(c.start)
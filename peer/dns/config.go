@@ -0,0 +1,137 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+)
+
+// Configuration describes how to build a DNS peer list updater.
+type Configuration struct {
+	// Name to resolve.
+	Name string `config:"name,interpolate"`
+
+	// Type of records to resolve: "A" (the default, which also resolves
+	// AAAA records) or "SRV".
+	Record string `config:"record"`
+
+	// Port on which peers accept requests. Required for A records and not
+	// allowed for SRV records, which carry their own ports.
+	Port int `config:"port,interpolate"`
+
+	// Maximum and minimum time between resolutions. Records are re-resolved
+	// when their TTL expires, within these bounds.
+	Interval    time.Duration `config:"interval"`
+	MinInterval time.Duration `config:"minInterval"`
+
+	// Maximum time a single resolution may take.
+	Timeout time.Duration `config:"timeout"`
+
+	// Addresses of the DNS servers to query, in order. Defaults to
+	// resolving names with the system resolver.
+	Servers []string `config:"servers"`
+
+	// Backoff strategy for retrying failed resolutions.
+	Backoff yarpcconfig.Backoff `config:"backoff"`
+}
+
+// Spec returns a configuration specification for the DNS peer list updater,
+// making it possible to discover peers through DNS with transports that use
+// outbound peer list configuration (like HTTP).
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerListUpdater(dns.Spec())
+//
+// This enables the dns peer list updater:
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: http://host/rpc
+//          round-robin:
+//            dns:
+//              name: otherservice.example.com
+//              port: 8080
+//
+// SRV records carry their own ports:
+//
+//  round-robin:
+//    dns:
+//      name: _http._tcp.otherservice.example.com
+//      record: SRV
+func Spec() yarpcconfig.PeerListUpdaterSpec {
+	return yarpcconfig.PeerListUpdaterSpec{
+		Name:                 "dns",
+		BuildPeerListUpdater: buildPeerListUpdater,
+	}
+}
+
+func buildPeerListUpdater(c Configuration, kit *yarpcconfig.Kit) (peer.Binder, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("DNS peer list updater config requires a name")
+	}
+
+	var opts []Option
+	switch strings.ToUpper(c.Record) {
+	case "", "A":
+		if c.Port <= 0 {
+			return nil, fmt.Errorf("DNS peer list updater config requires a port to resolve A records for %q", c.Name)
+		}
+		opts = append(opts, Port(c.Port))
+	case "SRV":
+		if c.Port != 0 {
+			return nil, fmt.Errorf("DNS peer list updater config does not accept a port for SRV records, got %d", c.Port)
+		}
+		opts = append(opts, SRV())
+	default:
+		return nil, fmt.Errorf(`DNS peer list updater config has unsupported record type %q: expected "A" or "SRV"`, c.Record)
+	}
+
+	if c.Interval < 0 || c.MinInterval < 0 || c.Timeout < 0 {
+		return nil, fmt.Errorf("DNS peer list updater config intervals and timeout must not be negative")
+	}
+	if c.Interval > 0 {
+		opts = append(opts, Interval(c.Interval))
+	}
+	if c.MinInterval > 0 {
+		opts = append(opts, MinInterval(c.MinInterval))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, Timeout(c.Timeout))
+	}
+	if len(c.Servers) > 0 {
+		opts = append(opts, WithResolver(NewResolver(c.Servers...)))
+	}
+
+	strategy, err := c.Backoff.Strategy()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, Backoff(strategy))
+
+	return NewBinder(c.Name, opts...), nil
+}
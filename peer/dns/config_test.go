@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Configuration
+		wantErr string
+	}{
+		{
+			name: "A records",
+			cfg:  Configuration{Name: "myservice", Port: 8080},
+		},
+		{
+			name: "all options",
+			cfg: Configuration{
+				Name:        "myservice",
+				Record:      "a",
+				Port:        8080,
+				Interval:    time.Minute,
+				MinInterval: time.Second,
+				Timeout:     time.Second,
+				Servers:     []string{"127.0.0.1:53"},
+				Backoff: yarpcconfig.Backoff{
+					Exponential: yarpcconfig.ExponentialBackoff{First: time.Second, Max: time.Minute},
+				},
+			},
+		},
+		{
+			name: "SRV records",
+			cfg:  Configuration{Name: "_http._tcp.myservice", Record: "SRV"},
+		},
+		{
+			name:    "missing name",
+			cfg:     Configuration{Port: 8080},
+			wantErr: "DNS peer list updater config requires a name",
+		},
+		{
+			name:    "missing port",
+			cfg:     Configuration{Name: "myservice"},
+			wantErr: `DNS peer list updater config requires a port to resolve A records for "myservice"`,
+		},
+		{
+			name:    "port with SRV records",
+			cfg:     Configuration{Name: "_http._tcp.myservice", Record: "SRV", Port: 8080},
+			wantErr: "DNS peer list updater config does not accept a port for SRV records, got 8080",
+		},
+		{
+			name:    "unsupported record type",
+			cfg:     Configuration{Name: "myservice", Record: "MX"},
+			wantErr: `DNS peer list updater config has unsupported record type "MX": expected "A" or "SRV"`,
+		},
+		{
+			name:    "negative interval",
+			cfg:     Configuration{Name: "myservice", Port: 8080, Interval: -time.Second},
+			wantErr: "DNS peer list updater config intervals and timeout must not be negative",
+		},
+	}
+
+	s := Spec()
+	assert.Equal(t, "dns", s.Name)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build := s.BuildPeerListUpdater.(func(Configuration, *yarpcconfig.Kit) (peer.Binder, error))
+			binder, err := build(tt.cfg, nil)
+
+			if tt.wantErr != "" {
+				require.Error(t, err, "must not construct a peer list updater")
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, binder(newFakeList()))
+		})
+	}
+}
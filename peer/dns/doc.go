@@ -0,0 +1,36 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package dns provides a peer list updater that discovers peers by resolving
+// DNS A/AAAA or SRV records and periodically re-resolves them to keep the
+// peer list current.
+//
+// To bind a peer list to DNS,
+//
+// 	list := roundrobin.New(transport)
+// 	chooser := peer.Bind(list, dns.NewBinder("myservice.example.com", dns.Port(8080)))
+//
+// The updater re-resolves the name when the TTL of the records expires,
+// bounded by the MinInterval and Interval options. If a resolution fails or
+// returns no records, the peer list retains the last known good set of peers
+// and the resolution is retried with backoff.
+//
+// See Spec for using the DNS updater with yarpcconfig.
+package dns
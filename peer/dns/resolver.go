@@ -0,0 +1,301 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const _maxUDPLength = 512
+
+// Resolver looks up DNS records.
+//
+// The returned TTL is the time for which the result may be cached. A zero TTL
+// means that the TTL is unknown.
+type Resolver interface {
+	// LookupIP returns the IPv4 and IPv6 addresses of the given host.
+	LookupIP(ctx context.Context, host string) (ips []net.IP, ttl time.Duration, err error)
+
+	// LookupSRV returns the SRV records for the given name, for example,
+	// "_http._tcp.myservice.example.com".
+	LookupSRV(ctx context.Context, name string) (srvs []*net.SRV, ttl time.Duration, err error)
+}
+
+// NewResolver builds a Resolver that sends queries directly to the given DNS
+// servers, in order, until one of them answers. Servers are addresses in the
+// form "host:port". Names are always treated as fully qualified; search
+// domains are not applied.
+//
+// If no servers are given, names are resolved with net.DefaultResolver,
+// which follows the system configuration, including /etc/hosts and search
+// domains. It does not report TTLs, so records are re-resolved at the
+// updater's Interval.
+func NewResolver(servers ...string) Resolver {
+	if len(servers) == 0 {
+		return systemResolver{net.DefaultResolver}
+	}
+	return &resolver{servers: servers}
+}
+
+// systemResolver looks up records with a net.Resolver.
+type systemResolver struct {
+	r *net.Resolver
+}
+
+func (s systemResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	addrs, err := s.r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, 0, nil
+}
+
+func (s systemResolver) LookupSRV(ctx context.Context, name string) ([]*net.SRV, time.Duration, error) {
+	_, srvs, err := s.r.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return srvs, 0, nil
+}
+
+type resolver struct {
+	servers []string
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+func (r *resolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	var (
+		ips  []net.IP
+		ttls []uint32
+		errs error
+	)
+	// Hosts commonly have only IPv4 or only IPv6 addresses, so the lookup
+	// succeeds as long as either query does.
+	for _, t := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answers, err := r.query(ctx, host, t)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		for _, a := range answers {
+			switch body := a.Body.(type) {
+			case *dnsmessage.AResource:
+				ips = append(ips, net.IP(body.A[:]))
+			case *dnsmessage.AAAAResource:
+				ips = append(ips, net.IP(body.AAAA[:]))
+			default:
+				// CNAME records leading to the addresses.
+			}
+			ttls = append(ttls, a.Header.TTL)
+		}
+	}
+	if len(ips) == 0 && errs != nil {
+		return nil, 0, errs
+	}
+	return ips, minTTL(ttls), nil
+}
+
+func (r *resolver) LookupSRV(ctx context.Context, name string) ([]*net.SRV, time.Duration, error) {
+	answers, err := r.query(ctx, name, dnsmessage.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		srvs []*net.SRV
+		ttls []uint32
+	)
+	for _, a := range answers {
+		ttls = append(ttls, a.Header.TTL)
+		if body, ok := a.Body.(*dnsmessage.SRVResource); ok {
+			srvs = append(srvs, &net.SRV{
+				Target:   body.Target.String(),
+				Port:     body.Port,
+				Priority: body.Priority,
+				Weight:   body.Weight,
+			})
+		}
+	}
+	return srvs, minTTL(ttls), nil
+}
+
+// query sends a query to each server in turn and returns the answers from
+// the first successful response.
+func (r *resolver) query(ctx context.Context, name string, t dnsmessage.Type) ([]dnsmessage.Resource, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS name %q: %v", name, err)
+	}
+
+	var errs error
+	for _, server := range r.servers {
+		answers, err := r.exchange(ctx, server, dnsmessage.Question{
+			Name:  n,
+			Type:  t,
+			Class: dnsmessage.ClassINET,
+		})
+		if err == nil {
+			return answers, nil
+		}
+		errs = multierr.Append(errs, err)
+	}
+	return nil, errs
+}
+
+// exchange sends a single question to the given server over UDP, falling
+// back to TCP if the response is truncated.
+func (r *resolver) exchange(ctx context.Context, server string, q dnsmessage.Question) ([]dnsmessage.Resource, error) {
+	req := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: r.newID(), RecursionDesired: true},
+		Questions: []dnsmessage.Question{q},
+	}
+	b, err := req.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := exchangeUDP(ctx, server, b)
+	if err == nil && res.Header.Truncated {
+		res, err = exchangeTCP(ctx, server, b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DNS query for %v %v to %q failed: %v", q.Type, q.Name, server, err)
+	}
+
+	if res.Header.ID != req.Header.ID {
+		return nil, fmt.Errorf("DNS query for %v %v to %q failed: mismatched response ID", q.Type, q.Name, server)
+	}
+	if res.Header.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("DNS query for %v %v to %q failed: %v", q.Type, q.Name, server, res.Header.RCode)
+	}
+	return res.Answers, nil
+}
+
+func exchangeUDP(ctx context.Context, server string, req []byte) (*dnsmessage.Message, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, _maxUDPLength)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var res dnsmessage.Message
+	if err := res.Unpack(buf[:n]); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func exchangeTCP(ctx context.Context, server string, req []byte) (*dnsmessage.Message, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	// Messages over TCP are prefixed with a two byte length.
+	framed := make([]byte, 2+len(req))
+	binary.BigEndian.PutUint16(framed, uint16(len(req)))
+	copy(framed[2:], req)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+
+	var res dnsmessage.Message
+	if err := res.Unpack(buf); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *resolver) newID() uint16 {
+	r.randMu.Lock()
+	defer r.randMu.Unlock()
+	if r.rand == nil {
+		r.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return uint16(r.rand.Uint32())
+}
+
+func minTTL(ttls []uint32) time.Duration {
+	if len(ttls) == 0 {
+		return 0
+	}
+	min := ttls[0]
+	for _, ttl := range ttls[1:] {
+		if ttl < min {
+			min = ttl
+		}
+	}
+	return time.Duration(min) * time.Second
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type question struct {
+	name string
+	typ  dnsmessage.Type
+}
+
+// fakeServer is a DNS server answering questions from a fixed set of
+// records over UDP and TCP on the same port.
+type fakeServer struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	records map[question][]dnsmessage.Resource
+
+	// Truncate UDP responses, forcing clients to retry over TCP.
+	truncate bool
+
+	udp *net.UDPConn
+	tcp *net.TCPListener
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	tcp, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: tcp.Addr().(*net.TCPAddr).Port})
+	require.NoError(t, err)
+
+	s := &fakeServer{
+		t:       t,
+		records: make(map[question][]dnsmessage.Resource),
+		udp:     udp,
+		tcp:     tcp,
+	}
+	go s.serveUDP()
+	go s.serveTCP()
+	return s
+}
+
+func (s *fakeServer) Addr() string {
+	return s.tcp.Addr().String()
+}
+
+func (s *fakeServer) Close() {
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+func (s *fakeServer) add(name string, ttl uint32, body dnsmessage.ResourceBody) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := question{name: name, typ: resourceType(body)}
+	s.records[q] = append(s.records[q], dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName(name),
+			Class: dnsmessage.ClassINET,
+			TTL:   ttl,
+		},
+		Body: body,
+	})
+}
+
+func resourceType(body dnsmessage.ResourceBody) dnsmessage.Type {
+	switch body.(type) {
+	case *dnsmessage.AResource:
+		return dnsmessage.TypeA
+	case *dnsmessage.AAAAResource:
+		return dnsmessage.TypeAAAA
+	case *dnsmessage.SRVResource:
+		return dnsmessage.TypeSRV
+	default:
+		panic("unsupported resource type")
+	}
+}
+
+func (s *fakeServer) setTruncate(truncate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.truncate = truncate
+}
+
+func (s *fakeServer) respond(req []byte, udp bool) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(req); err != nil {
+		s.t.Errorf("failed to unpack DNS request: %v", err)
+		return nil
+	}
+
+	res := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: msg.Header.ID, Response: true},
+		Questions: msg.Questions,
+	}
+	for _, q := range msg.Questions {
+		answers, ok := s.records[question{name: q.Name.String(), typ: q.Type}]
+		if !ok {
+			res.Header.RCode = dnsmessage.RCodeNameError
+			continue
+		}
+		res.Answers = append(res.Answers, answers...)
+	}
+	if udp && s.truncate {
+		res.Header.Truncated = true
+		res.Answers = nil
+	}
+
+	b, err := res.Pack()
+	if err != nil {
+		s.t.Errorf("failed to pack DNS response: %v", err)
+		return nil
+	}
+	return b
+}
+
+func (s *fakeServer) serveUDP() {
+	buf := make([]byte, _maxUDPLength)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if res := s.respond(buf[:n], true); res != nil {
+			s.udp.WriteTo(res, addr)
+		}
+	}
+}
+
+func (s *fakeServer) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return
+			}
+			req := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, req); err != nil {
+				return
+			}
+			res := s.respond(req, false)
+			binary.BigEndian.PutUint16(length[:], uint16(len(res)))
+			conn.Write(append(length[:], res...))
+		}()
+	}
+}
+
+func testContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Second)
+}
+
+func TestResolverLookupIP(t *testing.T) {
+	for _, truncate := range []bool{false, true} {
+		server := newFakeServer(t)
+		defer server.Close()
+		server.setTruncate(truncate)
+		server.add("myservice.example.com.", 30, &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+		server.add("myservice.example.com.", 10, &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}})
+		server.add("myservice.example.com.", 60, &dnsmessage.AAAAResource{
+			AAAA: [16]byte{15: 1},
+		})
+
+		ctx, cancel := testContext()
+		defer cancel()
+		ips, ttl, err := NewResolver(server.Addr()).LookupIP(ctx, "myservice.example.com")
+		require.NoError(t, err, "truncate: %v", truncate)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "::1"}, ipStrings(ips), "truncate: %v", truncate)
+		assert.Equal(t, 10*time.Second, ttl, "truncate: %v", truncate)
+	}
+}
+
+func TestResolverLookupSRV(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+	server.add("_http._tcp.myservice.", 30, &dnsmessage.SRVResource{
+		Target: dnsmessage.MustNewName("a.myservice."),
+		Port:   8080,
+	})
+	server.add("_http._tcp.myservice.", 30, &dnsmessage.SRVResource{
+		Target:   dnsmessage.MustNewName("b.myservice."),
+		Port:     9090,
+		Priority: 1,
+		Weight:   2,
+	})
+
+	ctx, cancel := testContext()
+	defer cancel()
+	srvs, ttl, err := NewResolver(server.Addr()).LookupSRV(ctx, "_http._tcp.myservice.")
+	require.NoError(t, err)
+	assert.Equal(t, []*net.SRV{
+		{Target: "a.myservice.", Port: 8080},
+		{Target: "b.myservice.", Port: 9090, Priority: 1, Weight: 2},
+	}, srvs)
+	assert.Equal(t, 30*time.Second, ttl)
+}
+
+func TestResolverErrors(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	ctx, cancel := testContext()
+	defer cancel()
+	_, _, err := NewResolver(server.Addr()).LookupIP(ctx, "unknown.example.com")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RCodeNameError")
+
+	_, _, err = NewResolver(server.Addr()).LookupIP(ctx, strings.Repeat("a", 64))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "segment length too long")
+}
+
+func TestResolverTriesServersInOrder(t *testing.T) {
+	down := newFakeServer(t)
+	down.Close()
+
+	server := newFakeServer(t)
+	defer server.Close()
+	server.add("myservice.", 30, &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+	server.add("myservice.", 30, &dnsmessage.AAAAResource{})
+
+	ctx, cancel := testContext()
+	defer cancel()
+	ips, _, err := NewResolver(down.Addr(), server.Addr()).LookupIP(ctx, "myservice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "::"}, ipStrings(ips))
+}
+
+func TestResolverLookupIPOneFamily(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+	server.add("v4only.", 30, &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+
+	ctx, cancel := testContext()
+	defer cancel()
+	ips, ttl, err := NewResolver(server.Addr()).LookupIP(ctx, "v4only")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, ipStrings(ips))
+	assert.Equal(t, 30*time.Second, ttl)
+}
+
+func TestNewResolverDefaultsToSystemResolver(t *testing.T) {
+	r := NewResolver()
+	assert.Equal(t, systemResolver{net.DefaultResolver}, r)
+
+	// localhost is resolved from /etc/hosts, not DNS.
+	ctx, cancel := testContext()
+	defer cancel()
+	ips, ttl, err := r.LookupIP(ctx, "localhost")
+	require.NoError(t, err)
+	require.NotEmpty(t, ips)
+	assert.True(t, ips[0].IsLoopback(), "expected a loopback address, got %v", ips[0])
+	assert.Zero(t, ttl)
+}
+
+func ipStrings(ips []net.IP) []string {
+	var out []string
+	for _, ip := range ips {
+		out = append(out, ip.String())
+	}
+	return out
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	backoffapi "go.uber.org/yarpc/api/backoff"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/backoff"
+	"go.uber.org/yarpc/internal/peerdiff"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/pkg/lifecycle"
+	"go.uber.org/zap"
+)
+
+type updaterOptions struct {
+	srv         bool
+	port        int
+	interval    time.Duration
+	minInterval time.Duration
+	timeout     time.Duration
+	resolver    Resolver
+	backoff     backoffapi.Strategy
+	logger      *zap.Logger
+}
+
+var defaultUpdaterOptions = updaterOptions{
+	interval:    30 * time.Second,
+	minInterval: time.Second,
+	timeout:     5 * time.Second,
+	backoff:     backoff.DefaultExponential,
+}
+
+// Option customizes the behavior of a DNS peer list updater.
+type Option func(*updaterOptions)
+
+// Port specifies the port on which peers discovered through A and AAAA
+// records accept requests. It is required unless SRV is used.
+func Port(port int) Option {
+	return func(o *updaterOptions) {
+		o.port = port
+	}
+}
+
+// SRV resolves SRV records instead of A and AAAA records. The name must be
+// the full SRV name, for example "_http._tcp.myservice.example.com". Each
+// SRV target is resolved to its addresses and combined with the port from
+// its SRV record.
+func SRV() Option {
+	return func(o *updaterOptions) {
+		o.srv = true
+	}
+}
+
+// Interval specifies the maximum time between resolutions. Records are
+// re-resolved when their TTL expires, but at least this often.
+//
+// Defaults to 30 seconds.
+func Interval(d time.Duration) Option {
+	return func(o *updaterOptions) {
+		o.interval = d
+	}
+}
+
+// MinInterval specifies the minimum time between successful resolutions,
+// protecting DNS servers from records with very short TTLs.
+//
+// Defaults to 1 second.
+func MinInterval(d time.Duration) Option {
+	return func(o *updaterOptions) {
+		o.minInterval = d
+	}
+}
+
+// Timeout specifies how long a single resolution may take.
+//
+// Defaults to 5 seconds.
+func Timeout(d time.Duration) Option {
+	return func(o *updaterOptions) {
+		o.timeout = d
+	}
+}
+
+// WithResolver specifies the Resolver used to look up records.
+//
+// Defaults to a resolver using the nameservers in /etc/resolv.conf.
+func WithResolver(r Resolver) Option {
+	return func(o *updaterOptions) {
+		o.resolver = r
+	}
+}
+
+// Backoff specifies the backoff strategy used to retry failed resolutions.
+// Retries never wait longer than the Interval.
+//
+// Defaults to exponential backoff with full jitter.
+func Backoff(s backoffapi.Strategy) Option {
+	return func(o *updaterOptions) {
+		o.backoff = s
+	}
+}
+
+// Logger specifies a logger for resolution failures.
+func Logger(logger *zap.Logger) Option {
+	return func(o *updaterOptions) {
+		o.logger = logger
+	}
+}
+
+// NewBinder returns a peer.Binder that binds a peer list to the peers found
+// by resolving the given DNS name.
+func NewBinder(name string, opts ...Option) peer.Binder {
+	return func(pl peer.List) transport.Lifecycle {
+		return NewUpdater(pl, name, opts...)
+	}
+}
+
+// NewUpdater builds a peer list updater that resolves the given DNS name and
+// keeps the peer list up to date with the results while it is running.
+func NewUpdater(pl peer.List, name string, opts ...Option) *Updater {
+	options := defaultUpdaterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.resolver == nil {
+		options.resolver = NewResolver()
+	}
+	if options.logger == nil {
+		options.logger = zap.NewNop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Updater{
+		once:    lifecycle.NewOnce(),
+		list:    pl,
+		name:    name,
+		opts:    options,
+		log:     options.logger.With(zap.String("dnsName", name)),
+		backoff: options.backoff.Backoff(),
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+		peers:   make(map[string]peer.Identifier),
+	}
+}
+
+// Updater is a peer list updater that adds and removes peers as the DNS
+// records for a name change.
+type Updater struct {
+	once *lifecycle.Once
+	list peer.List
+	name string
+	opts updaterOptions
+	log  *zap.Logger
+
+	backoff  backoffapi.Backoff
+	attempts uint
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped chan struct{}
+
+	// Peers most recently pushed to the list. This is accessed only by
+	// Start before the resolution loop begins, the resolution loop itself,
+	// and Stop after the resolution loop has ended.
+	peers map[string]peer.Identifier
+}
+
+// Start resolves the name, adds the discovered peers to the peer list and
+// begins re-resolving the name periodically.
+//
+// Start does not fail if the initial resolution fails; it is retried in the
+// background.
+func (u *Updater) Start() error {
+	return u.once.Start(u.start)
+}
+
+func (u *Updater) start() error {
+	if !u.opts.srv && u.opts.port <= 0 {
+		return fmt.Errorf("a port is required to resolve A records for %q", u.name)
+	}
+
+	delay := u.resolve()
+	go u.run(delay)
+	return nil
+}
+
+// Stop stops re-resolving the name and removes all peers it added from the
+// peer list.
+func (u *Updater) Stop() error {
+	return u.once.Stop(u.stop)
+}
+
+func (u *Updater) stop() error {
+	u.cancel()
+	<-u.stopped
+
+	updates := peerdiff.Diff(u.peers, nil)
+	u.peers = make(map[string]peer.Identifier)
+	return u.list.Update(updates)
+}
+
+// IsRunning returns whether the updater is running.
+func (u *Updater) IsRunning() bool {
+	return u.once.IsRunning()
+}
+
+func (u *Updater) run(delay time.Duration) {
+	defer close(u.stopped)
+
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-u.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = u.resolve()
+	}
+}
+
+// resolve looks up the name and updates the peer list, returning the time to
+// wait before the next resolution.
+func (u *Updater) resolve() time.Duration {
+	ctx, cancel := context.WithTimeout(u.ctx, u.opts.timeout)
+	defer cancel()
+
+	peers, ttl, err := u.lookup(ctx)
+	if err == nil && len(peers) == 0 {
+		err = fmt.Errorf("no records found for %q", u.name)
+	}
+	if err != nil {
+		delay := u.backoff.Duration(u.attempts)
+		u.attempts++
+		if delay > u.opts.interval {
+			delay = u.opts.interval
+		}
+		u.log.Warn("DNS resolution failed, retaining last known peers.",
+			zap.Error(err), zap.Duration("retryAfter", delay))
+		return delay
+	}
+
+	u.attempts = 0
+	u.update(peers)
+
+	switch {
+	case ttl <= 0 || ttl > u.opts.interval:
+		return u.opts.interval
+	case ttl < u.opts.minInterval:
+		return u.opts.minInterval
+	default:
+		return ttl
+	}
+}
+
+func (u *Updater) lookup(ctx context.Context) (map[string]peer.Identifier, time.Duration, error) {
+	peers := make(map[string]peer.Identifier)
+	add := func(ip net.IP, port int) {
+		id := hostport.PeerIdentifier(net.JoinHostPort(ip.String(), strconv.Itoa(port)))
+		peers[id.Identifier()] = id
+	}
+
+	if !u.opts.srv {
+		ips, ttl, err := u.opts.resolver.LookupIP(ctx, u.name)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, ip := range ips {
+			add(ip, u.opts.port)
+		}
+		return peers, ttl, nil
+	}
+
+	srvs, ttl, err := u.opts.resolver.LookupSRV(ctx, u.name)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, srv := range srvs {
+		ips, ipTTL, err := u.opts.resolver.LookupIP(ctx, srv.Target)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, ip := range ips {
+			add(ip, int(srv.Port))
+		}
+		ttl = minTTLDuration(ttl, ipTTL)
+	}
+	return peers, ttl, nil
+}
+
+// update pushes the difference between the current and the given peers to
+// the peer list.
+func (u *Updater) update(peers map[string]peer.Identifier) {
+	updates := peerdiff.Diff(u.peers, peers)
+	u.peers = peers
+	if peerdiff.IsEmpty(updates) {
+		return
+	}
+
+	if err := u.list.Update(updates); err != nil {
+		u.log.Error("Failed to update peer list.", zap.Error(err))
+	}
+}
+
+// minTTLDuration returns the smaller of two TTLs where zero means unknown.
+func minTTLDuration(a, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
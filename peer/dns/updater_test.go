@@ -0,0 +1,257 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+)
+
+type lookupResult struct {
+	ips  []net.IP
+	srvs []*net.SRV
+	ttl  time.Duration
+	err  error
+}
+
+// fakeResolver answers each lookup with the next result sent to it,
+// blocking until one is available.
+type fakeResolver struct {
+	results chan lookupResult
+}
+
+func newFakeResolver() *fakeResolver {
+	return &fakeResolver{results: make(chan lookupResult)}
+}
+
+func (r *fakeResolver) next(ctx context.Context) lookupResult {
+	select {
+	case res := <-r.results:
+		return res
+	case <-ctx.Done():
+		return lookupResult{err: ctx.Err()}
+	}
+}
+
+func (r *fakeResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	res := r.next(ctx)
+	return res.ips, res.ttl, res.err
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, name string) ([]*net.SRV, time.Duration, error) {
+	res := r.next(ctx)
+	return res.srvs, res.ttl, res.err
+}
+
+// fakeList records the updates it receives.
+type fakeList struct {
+	updates chan peer.ListUpdates
+}
+
+func newFakeList() *fakeList {
+	return &fakeList{updates: make(chan peer.ListUpdates, 10)}
+}
+
+func (l *fakeList) Update(updates peer.ListUpdates) error {
+	l.updates <- updates
+	return nil
+}
+
+func (l *fakeList) expect(t *testing.T, additions, removals []string) {
+	select {
+	case updates := <-l.updates:
+		assert.Equal(t, additions, identifiers(updates.Additions), "additions")
+		assert.Equal(t, removals, identifiers(updates.Removals), "removals")
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for peer list update")
+	}
+}
+
+func (l *fakeList) expectNone(t *testing.T) {
+	select {
+	case updates := <-l.updates:
+		t.Fatalf("unexpected peer list update: %v", updates)
+	default:
+	}
+}
+
+func identifiers(pids []peer.Identifier) []string {
+	var ids []string
+	for _, pid := range pids {
+		ids = append(ids, pid.Identifier())
+	}
+	return ids
+}
+
+func ips(addrs ...string) []net.IP {
+	var ips []net.IP
+	for _, addr := range addrs {
+		ips = append(ips, net.ParseIP(addr))
+	}
+	return ips
+}
+
+func TestUpdaterA(t *testing.T) {
+	resolver := newFakeResolver()
+	list := newFakeList()
+	updater := NewBinder("myservice",
+		Port(8080),
+		WithResolver(resolver),
+		MinInterval(time.Millisecond),
+		Interval(time.Millisecond),
+	)(list)
+
+	// The initial resolution happens during Start.
+	started := make(chan error)
+	go func() { started <- updater.Start() }()
+	resolver.results <- lookupResult{ips: ips("10.0.0.1", "10.0.0.2")}
+	require.NoError(t, <-started)
+	assert.True(t, updater.IsRunning())
+	list.expect(t, []string{"10.0.0.1:8080", "10.0.0.2:8080"}, nil)
+
+	// Peers are diffed against the previous resolution.
+	resolver.results <- lookupResult{ips: ips("10.0.0.2", "::1")}
+	list.expect(t, []string{"[::1]:8080"}, []string{"10.0.0.1:8080"})
+
+	// Failed and empty resolutions retain the last known peers.
+	resolver.results <- lookupResult{err: errors.New("great sadness")}
+	resolver.results <- lookupResult{}
+	// Unchanged results do not update the list, so the next update seen is
+	// the one after.
+	resolver.results <- lookupResult{ips: ips("::1", "10.0.0.2")}
+	resolver.results <- lookupResult{ips: ips("10.0.0.3")}
+	list.expect(t, []string{"10.0.0.3:8080"}, []string{"10.0.0.2:8080", "[::1]:8080"})
+
+	require.NoError(t, updater.Stop())
+	assert.False(t, updater.IsRunning())
+	list.expect(t, nil, []string{"10.0.0.3:8080"})
+}
+
+func TestUpdaterSRV(t *testing.T) {
+	resolver := newFakeResolver()
+	list := newFakeList()
+	updater := NewUpdater(list, "_http._tcp.myservice",
+		SRV(),
+		WithResolver(resolver),
+	)
+
+	started := make(chan error)
+	go func() { started <- updater.Start() }()
+	resolver.results <- lookupResult{srvs: []*net.SRV{
+		{Target: "a.myservice.", Port: 8080},
+		{Target: "b.myservice.", Port: 9090},
+	}}
+	resolver.results <- lookupResult{ips: ips("10.0.0.1")}
+	resolver.results <- lookupResult{ips: ips("10.0.0.2", "10.0.0.3")}
+	require.NoError(t, <-started)
+	list.expect(t, []string{"10.0.0.1:8080", "10.0.0.2:9090", "10.0.0.3:9090"}, nil)
+
+	require.NoError(t, updater.Stop())
+	list.expect(t, nil, []string{"10.0.0.1:8080", "10.0.0.2:9090", "10.0.0.3:9090"})
+}
+
+func TestUpdaterInitialFailure(t *testing.T) {
+	resolver := newFakeResolver()
+	list := newFakeList()
+	updater := NewUpdater(list, "myservice",
+		Port(8080),
+		WithResolver(resolver),
+		Interval(time.Millisecond),
+	)
+
+	started := make(chan error)
+	go func() { started <- updater.Start() }()
+	resolver.results <- lookupResult{err: errors.New("great sadness")}
+	require.NoError(t, <-started, "start must not fail when resolution fails")
+	list.expectNone(t)
+
+	// The resolution is retried.
+	resolver.results <- lookupResult{ips: ips("10.0.0.1")}
+	list.expect(t, []string{"10.0.0.1:8080"}, nil)
+
+	require.NoError(t, updater.Stop())
+	list.expect(t, nil, []string{"10.0.0.1:8080"})
+}
+
+func TestUpdaterRequiresPort(t *testing.T) {
+	updater := NewUpdater(newFakeList(), "myservice", WithResolver(newFakeResolver()))
+	assert.EqualError(t, updater.Start(), `a port is required to resolve A records for "myservice"`)
+}
+
+func TestUpdaterNextResolution(t *testing.T) {
+	tests := []struct {
+		desc string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{desc: "unknown TTL", ttl: 0, want: time.Minute},
+		{desc: "short TTL", ttl: time.Millisecond, want: time.Second},
+		{desc: "long TTL", ttl: time.Hour, want: time.Minute},
+		{desc: "TTL within bounds", ttl: 10 * time.Second, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resolver := newFakeResolver()
+			updater := NewUpdater(newFakeList(), "myservice",
+				Port(8080),
+				WithResolver(resolver),
+				MinInterval(time.Second),
+				Interval(time.Minute),
+			)
+
+			go func() {
+				resolver.results <- lookupResult{ips: ips("10.0.0.1"), ttl: tt.ttl}
+			}()
+			assert.Equal(t, tt.want, updater.resolve())
+		})
+	}
+}
+
+func TestUpdaterRetryBackoffBoundedByInterval(t *testing.T) {
+	resolver := newFakeResolver()
+	updater := NewUpdater(newFakeList(), "myservice",
+		Port(8080),
+		WithResolver(resolver),
+		Interval(time.Millisecond),
+	)
+
+	for i := 0; i < 5; i++ {
+		go func() {
+			resolver.results <- lookupResult{err: errors.New("great sadness")}
+		}()
+		assert.True(t, updater.resolve() <= time.Millisecond)
+	}
+}
+
+func TestMinTTLDuration(t *testing.T) {
+	assert.Equal(t, time.Second, minTTLDuration(0, time.Second))
+	assert.Equal(t, time.Second, minTTLDuration(time.Second, 0))
+	assert.Equal(t, time.Second, minTTLDuration(time.Minute, time.Second))
+	assert.Equal(t, time.Second, minTTLDuration(time.Second, time.Minute))
+}
@@ -0,0 +1,388 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package failover
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/internal/introspection"
+	"go.uber.org/yarpc/internal/readiness"
+	yarpcpeer "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/pkg/lifecycle"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/zap"
+)
+
+type options struct {
+	errorRate   float64
+	minRequests int
+	interval    time.Duration
+	meter       *metrics.Scope
+	logger      *zap.Logger
+	clock       clock.Clock
+}
+
+var defaultOptions = options{
+	minRequests: 20,
+	interval:    10 * time.Second,
+}
+
+// Option customizes the behavior of a failover peer chooser.
+type Option func(*options)
+
+// ErrorThreshold fails over from a tier once the given rate of its requests
+// fail within an interval, if it received at least minRequests requests in
+// the interval. Only errors that indicate a problem with the peers count as
+// failures: unknown, internal, unavailable, deadline exceeded and data loss
+// errors.
+//
+// By default, tiers fail over only when they have no available peers.
+func ErrorThreshold(rate float64, minRequests int) Option {
+	return func(o *options) {
+		o.errorRate = rate
+		o.minRequests = minRequests
+	}
+}
+
+// Interval specifies the interval over which the error rate of each tier is
+// measured, and for which a tier that exceeds the error threshold is
+// skipped.
+//
+// Defaults to 10 seconds.
+func Interval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+// Meter specifies a metrics scope for the number of requests served by each
+// tier, counted by "failover_calls" with a "tier" tag holding the index of
+// the tier, starting with 0 for the primary.
+func Meter(meter *metrics.Scope) Option {
+	return func(o *options) {
+		o.meter = meter
+	}
+}
+
+// Logger specifies a logger for tiers exceeding the error threshold.
+func Logger(logger *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+func withClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+var _failureCodes = map[yarpcerrors.Code]struct{}{
+	yarpcerrors.CodeUnknown:          {},
+	yarpcerrors.CodeDeadlineExceeded: {},
+	yarpcerrors.CodeInternal:         {},
+	yarpcerrors.CodeUnavailable:      {},
+	yarpcerrors.CodeDataLoss:         {},
+}
+
+// New creates a peer chooser that fails over from each of the given peer
+// choosers to the next, in order. The first peer chooser is the primary.
+func New(choosers []peer.Chooser, opts ...Option) *Chooser {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logger == nil {
+		o.logger = zap.NewNop()
+	}
+	if o.clock == nil {
+		o.clock = clock.NewReal()
+	}
+
+	tiers := make([]*tier, len(choosers))
+	for i, c := range choosers {
+		calls, err := o.meter.Counter(metrics.Spec{
+			Name:      "failover_calls",
+			Help:      "Number of requests served by each tier of a failover peer chooser.",
+			ConstTags: metrics.Tags{"tier": strconv.Itoa(i)},
+		})
+		if err != nil {
+			o.logger.Error("Failed to create failover calls counter.", zap.Int("tier", i), zap.Error(err))
+		}
+		tiers[i] = &tier{
+			index:   i,
+			chooser: c,
+			calls:   calls,
+		}
+	}
+
+	return &Chooser{
+		once:    lifecycle.NewOnce(),
+		options: o,
+		tiers:   tiers,
+	}
+}
+
+// Chooser is a peer chooser that sends requests to the first of its tiers of
+// peer choosers that has available peers and is under the error threshold.
+type Chooser struct {
+	once    *lifecycle.Once
+	options options
+	tiers   []*tier
+}
+
+var (
+	_ peer.Chooser                        = (*Chooser)(nil)
+	_ peer.ReadinessWaiter                = (*Chooser)(nil)
+	_ introspection.IntrospectableChooser = (*Chooser)(nil)
+)
+
+// tier is a peer chooser with the outcomes of its recent requests.
+type tier struct {
+	index   int
+	chooser peer.Chooser
+	calls   *metrics.Counter
+
+	lock         sync.Mutex
+	since        time.Time // start of the current interval
+	requests     int
+	failures     int
+	trippedUntil time.Time
+}
+
+// healthy returns whether the tier is under the error threshold.
+func (t *tier) healthy(now time.Time) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return !now.Before(t.trippedUntil)
+}
+
+// observe records the outcome of a request, tripping the tier if it exceeds
+// the error threshold.
+func (t *tier) observe(c *Chooser, err error) {
+	o := c.options
+	if o.errorRate <= 0 {
+		return
+	}
+
+	now := o.clock.Now()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if now.Sub(t.since) >= o.interval {
+		t.since = now
+		t.requests = 0
+		t.failures = 0
+	}
+	t.requests++
+	if isFailure(err) {
+		t.failures++
+	}
+
+	if t.requests < o.minRequests || float64(t.failures) < o.errorRate*float64(t.requests) {
+		return
+	}
+
+	o.logger.Warn("Failover tier exceeded the error threshold.",
+		zap.Int("tier", t.index),
+		zap.Int("failures", t.failures),
+		zap.Int("requests", t.requests),
+		zap.Duration("skippedFor", o.interval))
+	t.trippedUntil = now.Add(o.interval)
+	t.since = t.trippedUntil
+	t.requests = 0
+	t.failures = 0
+}
+
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if !yarpcerrors.IsStatus(err) {
+		return true
+	}
+	_, ok := _failureCodes[yarpcerrors.FromError(err).Code()]
+	return ok
+}
+
+// numAvailable returns the number of available peers of the chooser, and
+// whether the chooser reports it.
+func numAvailable(c peer.Chooser) (int, bool) {
+	if b, ok := c.(*yarpcpeer.BoundChooser); ok {
+		return numAvailable(b.ChooserList())
+	}
+	if n, ok := c.(interface {
+		NumAvailable() int
+	}); ok {
+		return n.NumAvailable(), true
+	}
+	return 0, false
+}
+
+// hasAvailable returns whether the tier has available peers, assuming it does
+// if its chooser does not report them.
+func (t *tier) hasAvailable() bool {
+	n, ok := numAvailable(t.chooser)
+	return !ok || n > 0
+}
+
+// pick returns the tier to serve a request.
+func (c *Chooser) pick() *tier {
+	now := c.options.clock.Now()
+	for _, t := range c.tiers {
+		if t.hasAvailable() && t.healthy(now) {
+			return t
+		}
+	}
+	for _, t := range c.tiers {
+		if t.hasAvailable() {
+			return t
+		}
+	}
+	return c.tiers[0]
+}
+
+// Choose chooses a peer from the first tier that has available peers and is
+// under the error threshold.
+func (c *Chooser) Choose(ctx context.Context, req *transport.Request) (peer.Peer, func(error), error) {
+	if err := c.once.WaitUntilRunning(ctx); err != nil {
+		return nil, nil, err
+	}
+	if len(c.tiers) == 0 {
+		return nil, nil, yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "failover peer chooser has no tiers")
+	}
+
+	t := c.pick()
+	p, onFinish, err := t.chooser.Choose(ctx, req)
+	if err != nil {
+		t.observe(c, err)
+		return nil, nil, err
+	}
+
+	t.calls.Inc()
+	return p, func(err error) {
+		onFinish(err)
+		t.observe(c, err)
+	}, nil
+}
+
+// NumAvailable returns the number of available peers across all tiers, so
+// failover peer choosers can be tiers of other failover peer choosers.
+func (c *Chooser) NumAvailable() int {
+	var total int
+	for _, t := range c.tiers {
+		if n, ok := numAvailable(t.chooser); ok {
+			total += n
+		}
+	}
+	return total
+}
+
+// WaitUntilReady blocks until the peer chooser of any tier meets the given
+// readiness criteria, since requests fail over to it, or returns an error if
+// the context finishes first. Tiers whose peer choosers do not support
+// readiness are considered ready.
+func (c *Chooser) WaitUntilReady(ctx context.Context, r peer.Readiness) error {
+	waiters := make([]peer.ReadinessWaiter, 0, len(c.tiers))
+	for _, t := range c.tiers {
+		w, ok := t.chooser.(peer.ReadinessWaiter)
+		if !ok {
+			return nil
+		}
+		waiters = append(waiters, w)
+	}
+	return readiness.WaitForAny(ctx, waiters, r)
+}
+
+// Start starts the peer choosers of all tiers.
+func (c *Chooser) Start() error {
+	return c.once.Start(func() error {
+		var err error
+		for _, t := range c.tiers {
+			err = multierr.Append(err, t.chooser.Start())
+		}
+		return err
+	})
+}
+
+// Stop stops the peer choosers of all tiers.
+func (c *Chooser) Stop() error {
+	return c.once.Stop(func() error {
+		var err error
+		for _, t := range c.tiers {
+			err = multierr.Append(err, t.chooser.Stop())
+		}
+		return err
+	})
+}
+
+// IsRunning returns whether the peer chooser is running.
+func (c *Chooser) IsRunning() bool {
+	return c.once.IsRunning()
+}
+
+// Introspect returns a ChooserStatus with the state of each tier and a
+// summary of its peers.
+func (c *Chooser) Introspect() introspection.ChooserStatus {
+	state := "Stopped"
+	if c.IsRunning() {
+		state = "Running"
+	}
+
+	now := c.options.clock.Now()
+	tiers := make([]string, 0, len(c.tiers))
+	var peers []introspection.PeerStatus
+	for _, t := range c.tiers {
+		health := "healthy"
+		if !t.healthy(now) {
+			health = "over error threshold"
+		}
+		if n, ok := numAvailable(t.chooser); ok {
+			health = fmt.Sprintf("%s, %d available", health, n)
+		}
+		tiers = append(tiers, fmt.Sprintf("tier %d: %s", t.index, health))
+
+		ic, ok := t.chooser.(introspection.IntrospectableChooser)
+		if !ok {
+			continue
+		}
+		for _, ps := range ic.Introspect().Peers {
+			ps.State = fmt.Sprintf("tier %d, %s", t.index, ps.State)
+			peers = append(peers, ps)
+		}
+	}
+
+	return introspection.ChooserStatus{
+		Name:  "Failover",
+		State: fmt.Sprintf("%s (%s)", state, strings.Join(tiers, "; ")),
+		Peers: peers,
+	}
+}
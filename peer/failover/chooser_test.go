@@ -0,0 +1,247 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	yarpcpeer "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+// tierChooser always chooses the same peer and reports a configurable number
+// of available peers.
+type tierChooser struct {
+	id        string
+	available int
+	running   bool
+}
+
+func newTierChooser(id string, available int) *tierChooser {
+	return &tierChooser{id: id, available: available}
+}
+
+func (c *tierChooser) Choose(context.Context, *transport.Request) (peer.Peer, func(error), error) {
+	return hostport.NewPeer(hostport.PeerIdentifier(c.id), nil), func(error) {}, nil
+}
+
+func (c *tierChooser) NumAvailable() int { return c.available }
+func (c *tierChooser) Start() error      { c.running = true; return nil }
+func (c *tierChooser) Stop() error       { c.running = false; return nil }
+func (c *tierChooser) IsRunning() bool   { return c.running }
+
+// opaqueChooser is a tierChooser that does not report its available peers.
+type opaqueChooser struct {
+	c *tierChooser
+}
+
+func (o opaqueChooser) Choose(ctx context.Context, req *transport.Request) (peer.Peer, func(error), error) {
+	return o.c.Choose(ctx, req)
+}
+
+func (o opaqueChooser) Start() error    { return o.c.Start() }
+func (o opaqueChooser) Stop() error     { return o.c.Stop() }
+func (o opaqueChooser) IsRunning() bool { return o.c.IsRunning() }
+
+func choose(t *testing.T, c peer.Chooser, err error) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p, onFinish, chooseErr := c.Choose(ctx, &transport.Request{})
+	require.NoError(t, chooseErr)
+	onFinish(err)
+	return p.Identifier()
+}
+
+func TestFailoverAvailability(t *testing.T) {
+	primary := newTierChooser("primary", 2)
+	secondary := newTierChooser("secondary", 1)
+	tertiary := newTierChooser("tertiary", 1)
+	c := New([]peer.Chooser{primary, secondary, tertiary})
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	assert.True(t, primary.IsRunning())
+	assert.True(t, tertiary.IsRunning())
+
+	assert.Equal(t, "primary", choose(t, c, nil))
+	assert.Equal(t, 4, c.NumAvailable())
+
+	primary.available = 0
+	assert.Equal(t, "secondary", choose(t, c, nil))
+
+	secondary.available = 0
+	assert.Equal(t, "tertiary", choose(t, c, nil))
+
+	// Without available peers anywhere, wait for the primary.
+	tertiary.available = 0
+	assert.Equal(t, "primary", choose(t, c, nil))
+
+	primary.available = 1
+	assert.Equal(t, "primary", choose(t, c, nil))
+
+	require.NoError(t, c.Stop())
+	assert.False(t, primary.IsRunning())
+	assert.False(t, secondary.IsRunning())
+}
+
+func TestFailoverOpaqueTier(t *testing.T) {
+	primary := newTierChooser("primary", 0)
+	c := New([]peer.Chooser{opaqueChooser{primary}, newTierChooser("secondary", 1)})
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	// The primary doesn't report available peers, so it's assumed to have
+	// some.
+	assert.Equal(t, "primary", choose(t, c, nil))
+	assert.Equal(t, 1, c.NumAvailable())
+}
+
+func TestFailoverBoundChooser(t *testing.T) {
+	trans := yarpctest.NewFakeTransport(yarpctest.InitialConnectionStatus(peer.Unavailable))
+	primary := yarpcpeer.Bind(roundrobin.New(trans), yarpcpeer.BindPeers([]peer.Identifier{
+		hostport.PeerIdentifier("1.1.1.1:1111"),
+	}))
+	c := New([]peer.Chooser{primary, yarpcpeer.NewSingle(hostport.PeerIdentifier("2.2.2.2:2222"), trans)})
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	assert.Equal(t, "2.2.2.2:2222", choose(t, c, nil))
+
+	trans.SimulateConnect(hostport.PeerIdentifier("1.1.1.1:1111"))
+	assert.Equal(t, "1.1.1.1:1111", choose(t, c, nil))
+}
+
+func TestFailoverWaitUntilReady(t *testing.T) {
+	trans := yarpctest.NewFakeTransport(yarpctest.InitialConnectionStatus(peer.Unavailable))
+	bind := func(id string) peer.Chooser {
+		return yarpcpeer.Bind(roundrobin.New(trans), yarpcpeer.BindPeers([]peer.Identifier{
+			hostport.PeerIdentifier(id),
+		}))
+	}
+	c := New([]peer.Chooser{bind("1.1.1.1:1111"), bind("2.2.2.2:2222")})
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.Error(t, c.WaitUntilReady(ctx, peer.Readiness{}), "no tier is ready")
+
+	// Any ready tier makes the chooser ready, since requests fail over to it.
+	trans.SimulateConnect(hostport.PeerIdentifier("2.2.2.2:2222"))
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, c.WaitUntilReady(ctx, peer.Readiness{}))
+
+	// Tiers that cannot be waited on are considered ready.
+	opaque := New([]peer.Chooser{bind("3.3.3.3:3333"), newTierChooser("other", 0)})
+	assert.NoError(t, opaque.WaitUntilReady(ctx, peer.Readiness{}))
+}
+
+func TestFailoverErrorThreshold(t *testing.T) {
+	clk := clock.NewFake()
+	root := metrics.New()
+	c := New(
+		[]peer.Chooser{newTierChooser("primary", 1), newTierChooser("secondary", 1)},
+		ErrorThreshold(0.5, 4),
+		Interval(10*time.Second),
+		Meter(root.Scope()),
+		withClock(clk),
+	)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	unavailable := yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "unavailable")
+	invalid := yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, "invalid")
+
+	// Caller errors don't count as failures.
+	assert.Equal(t, "primary", choose(t, c, invalid))
+	assert.Equal(t, "primary", choose(t, c, invalid))
+	assert.Equal(t, "primary", choose(t, c, unavailable))
+	assert.Equal(t, "primary", choose(t, c, nil))
+	assert.Equal(t, "primary", choose(t, c, errors.New("great sadness")))
+	assert.Contains(t, c.Introspect().State, "tier 0: healthy, 1 available")
+
+	// The primary has now failed 2 of 4 requests in this interval.
+	assert.Equal(t, "primary", choose(t, c, unavailable))
+	assert.Equal(t, "secondary", choose(t, c, nil))
+	assert.Contains(t, c.Introspect().State, "tier 0: over error threshold, 1 available")
+
+	clk.Add(10 * time.Second)
+	assert.Equal(t, "primary", choose(t, c, nil))
+
+	calls := make(map[string]int64)
+	for _, counter := range root.Snapshot().Counters {
+		if counter.Name == "failover_calls" {
+			calls[counter.Tags["tier"]] = counter.Value
+		}
+	}
+	assert.Equal(t, map[string]int64{"0": 7, "1": 1}, calls)
+}
+
+func TestFailoverErrorThresholdInterval(t *testing.T) {
+	clk := clock.NewFake()
+	c := New(
+		[]peer.Chooser{newTierChooser("primary", 1), newTierChooser("secondary", 1)},
+		ErrorThreshold(0.5, 2),
+		Interval(10*time.Second),
+		withClock(clk),
+	)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	// Failures in past intervals don't count.
+	unavailable := yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "unavailable")
+	assert.Equal(t, "primary", choose(t, c, unavailable))
+	clk.Add(10 * time.Second)
+	assert.Equal(t, "primary", choose(t, c, nil))
+	assert.Equal(t, "primary", choose(t, c, nil))
+	assert.Equal(t, "primary", choose(t, c, nil))
+}
+
+func TestFailoverNotRunning(t *testing.T) {
+	c := New([]peer.Chooser{newTierChooser("primary", 1), newTierChooser("secondary", 1)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := c.Choose(ctx, &transport.Request{})
+	assert.Error(t, err)
+	assert.Equal(t, "Stopped (tier 0: healthy, 1 available; tier 1: healthy, 1 available)", c.Introspect().State)
+}
+
+func TestFailoverNoTiers(t *testing.T) {
+	c := New(nil)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	_, _, err := c.Choose(context.Background(), &transport.Request{})
+	assert.Equal(t, yarpcerrors.CodeUnavailable, yarpcerrors.FromError(err).Code())
+}
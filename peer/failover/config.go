@@ -0,0 +1,116 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package failover
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// Configuration describes how to build a failover peer chooser.
+type Configuration struct {
+	// Peer chooser configurations of the tiers, in order, starting with the
+	// primary.
+	With []map[string]interface{} `config:"with"`
+
+	ErrorThreshold float64       `config:"errorThreshold"`
+	MinRequests    *int          `config:"minRequests"`
+	Interval       time.Duration `config:"interval"`
+}
+
+// Spec returns a configuration specification for the failover peer chooser,
+// making it possible to send requests to a primary peer chooser and fail over
+// to secondary peer choosers with transports that use outbound peer chooser
+// configuration (like HTTP). The given options, like Meter, apply to every
+// failover peer chooser built from the configuration.
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerChooser(failover.Spec(failover.Meter(scope)))
+//
+// This enables the failover peer chooser, with the peer chooser
+// configuration of each tier under "with":
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: https://host:port/rpc
+//          failover:
+//            errorThreshold: 0.5
+//            minRequests: 20
+//            interval: 10s
+//            with:
+//              - round-robin:
+//                  peers:
+//                    - 127.0.0.1:8080
+//                    - 127.0.0.1:8081
+//              - with: legacy-proxy
+//
+// The peer lists and peer choosers of the tiers must be registered with the
+// Configurator.
+func Spec(opts ...Option) yarpcconfig.PeerChooserSpec {
+	return yarpcconfig.PeerChooserSpec{
+		Name: "failover",
+		BuildPeerChooser: func(cfg Configuration, t peer.Transport, k *yarpcconfig.Kit) (peer.Chooser, error) {
+			if len(cfg.With) < 2 {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"With must have at least 2 peer choosers. Got: %d.", len(cfg.With))
+			}
+			if cfg.ErrorThreshold < 0 || cfg.ErrorThreshold > 1 {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"ErrorThreshold must be between 0 and 1. Got: %v.", cfg.ErrorThreshold)
+			}
+			if cfg.Interval < 0 {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"Interval must not be negative. Got: %v.", cfg.Interval)
+			}
+
+			opts := append([]Option(nil), opts...)
+			if cfg.ErrorThreshold > 0 {
+				minRequests := defaultOptions.minRequests
+				if cfg.MinRequests != nil {
+					minRequests = *cfg.MinRequests
+				}
+				opts = append(opts, ErrorThreshold(cfg.ErrorThreshold, minRequests))
+			} else if cfg.MinRequests != nil {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"MinRequests may only be specified with ErrorThreshold.")
+			}
+			if cfg.Interval > 0 {
+				opts = append(opts, Interval(cfg.Interval))
+			}
+
+			choosers := make([]peer.Chooser, len(cfg.With))
+			for i, attrs := range cfg.With {
+				c, err := k.BuildPeerChooser(attrs, t)
+				if err != nil {
+					return nil, fmt.Errorf("failed to build peer chooser %d of failover: %v", i, err)
+				}
+				choosers[i] = c
+			}
+			return New(choosers, opts...), nil
+		},
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package failover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+type attrs map[string]interface{}
+
+func TestConfig(t *testing.T) {
+	tiers := []attrs{
+		{"round-robin": attrs{"peers": []string{"1.1.1.1:1111", "2.2.2.2:2222"}}},
+		{"with": "fake-preset"},
+	}
+
+	tests := []struct {
+		desc    string
+		give    attrs
+		wantErr string
+	}{
+		{desc: "defaults", give: attrs{"with": tiers}},
+		{
+			desc: "all options",
+			give: attrs{
+				"with":           tiers,
+				"errorThreshold": 0.5,
+				"minRequests":    10,
+				"interval":       "30s",
+			},
+		},
+		{
+			desc: "single peer tier",
+			give: attrs{"with": []attrs{tiers[0], {"peer": "3.3.3.3:3333"}}},
+		},
+		{
+			desc:    "one tier",
+			give:    attrs{"with": tiers[:1]},
+			wantErr: "With must have at least 2 peer choosers. Got: 1.",
+		},
+		{
+			desc:    "error threshold above 1",
+			give:    attrs{"with": tiers, "errorThreshold": 2},
+			wantErr: "ErrorThreshold must be between 0 and 1. Got: 2.",
+		},
+		{
+			desc:    "min requests without error threshold",
+			give:    attrs{"with": tiers, "minRequests": 10},
+			wantErr: "MinRequests may only be specified with ErrorThreshold.",
+		},
+		{
+			desc:    "negative interval",
+			give:    attrs{"with": tiers, "interval": "-1s"},
+			wantErr: "Interval must not be negative. Got: -1s.",
+		},
+		{
+			desc:    "unknown peer list",
+			give:    attrs{"with": []attrs{tiers[0], {"least-pending": attrs{}}}},
+			wantErr: `failed to build peer chooser 1 of failover: no recognized peer list or chooser "least-pending"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := yarpcconfig.New()
+			require.NoError(t, cfg.RegisterPeerChooser(Spec()))
+			require.NoError(t, cfg.RegisterPeerList(roundrobin.Spec()))
+			require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+			config, err := cfg.LoadConfig("our-service", attrs{
+				"outbounds": attrs{
+					"their-service": attrs{
+						"fake-transport": attrs{
+							"failover": tt.give,
+						},
+					},
+				},
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			chooser := config.Outbounds["their-service"].Unary.(*yarpctest.FakeOutbound).Chooser()
+			require.IsType(t, &Chooser{}, chooser)
+			assert.Len(t, chooser.(*Chooser).tiers, 2)
+		})
+	}
+}
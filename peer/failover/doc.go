@@ -0,0 +1,41 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package failover provides a peer chooser that sends requests to a primary
+// peer chooser and fails over to secondary peer choosers, in order, when the
+// primary has no available peers or fails too many requests.
+//
+// Each peer chooser is a tier. Requests go to the first tier with available
+// peers whose error rate is under the error threshold. A tier whose error
+// rate exceeds the threshold is skipped for an interval, after which it
+// receives requests again. If no tier qualifies, requests go to the first
+// tier with available peers, or wait for peers of the primary tier.
+//
+// Tiers that do not report how many peers they have available, like a single
+// peer, are always considered to have available peers.
+//
+// 	chooser := failover.New(
+// 		[]peer.Chooser{primary, secondary},
+// 		failover.ErrorThreshold(0.5, 20),
+// 		failover.Meter(scope),
+// 	)
+//
+// See Spec for configuring the chooser with yarpcconfig.
+package failover
@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package file
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+)
+
+// Configuration describes how to build a file peer list updater.
+type Configuration struct {
+	// Path of the file listing peers.
+	Path string `config:"path,interpolate"`
+
+	// Format of the file: "json" or "yaml". Inferred from the extension of
+	// the file by default.
+	Format string `config:"format"`
+
+	// How often to check the file for changes.
+	Interval time.Duration `config:"interval"`
+}
+
+// Spec returns a configuration specification for the file peer list updater,
+// making it possible to read peers from a file with transports that use
+// outbound peer list configuration (like HTTP).
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerListUpdater(file.Spec())
+//
+// This enables the peers-file peer list updater:
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: http://host/rpc
+//          round-robin:
+//            peers-file:
+//              path: /etc/otherservice/peers.json
+func Spec() yarpcconfig.PeerListUpdaterSpec {
+	return yarpcconfig.PeerListUpdaterSpec{
+		Name:                 "peers-file",
+		BuildPeerListUpdater: buildPeerListUpdater,
+	}
+}
+
+func buildPeerListUpdater(c Configuration, kit *yarpcconfig.Kit) (peer.Binder, error) {
+	if c.Path == "" {
+		return nil, fmt.Errorf("peers-file peer list updater config requires a path")
+	}
+
+	var opts []Option
+	switch format := Format(strings.ToLower(c.Format)); format {
+	case "":
+	case JSON, YAML:
+		opts = append(opts, WithFormat(format))
+	default:
+		return nil, fmt.Errorf(`peers-file peer list updater config has unsupported format %q: expected "json" or "yaml"`, c.Format)
+	}
+
+	if c.Interval < 0 {
+		return nil, fmt.Errorf("peers-file peer list updater config interval must not be negative")
+	}
+	if c.Interval > 0 {
+		opts = append(opts, Interval(c.Interval))
+	}
+
+	return NewBinder(c.Path, opts...), nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package file
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Configuration
+		wantFormat Format
+		wantErr    string
+	}{
+		{
+			name:       "path",
+			cfg:        Configuration{Path: "/etc/peers.json"},
+			wantFormat: JSON,
+		},
+		{
+			name:       "all options",
+			cfg:        Configuration{Path: "/etc/peers", Format: "JSON", Interval: time.Minute},
+			wantFormat: JSON,
+		},
+		{
+			name:    "missing path",
+			cfg:     Configuration{Format: "yaml"},
+			wantErr: "peers-file peer list updater config requires a path",
+		},
+		{
+			name:    "unsupported format",
+			cfg:     Configuration{Path: "/etc/peers", Format: "toml"},
+			wantErr: `peers-file peer list updater config has unsupported format "toml": expected "json" or "yaml"`,
+		},
+		{
+			name:    "negative interval",
+			cfg:     Configuration{Path: "/etc/peers", Interval: -time.Second},
+			wantErr: "peers-file peer list updater config interval must not be negative",
+		},
+	}
+
+	s := Spec()
+	assert.Equal(t, "peers-file", s.Name)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build := s.BuildPeerListUpdater.(func(Configuration, *yarpcconfig.Kit) (peer.Binder, error))
+			binder, err := build(tt.cfg, nil)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			updater := binder(newFakeList()).(*Updater)
+			assert.Equal(t, tt.wantFormat, updater.opts.format)
+		})
+	}
+}
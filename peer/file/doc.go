@@ -0,0 +1,51 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package file provides a peer list updater that reads peers from a JSON or
+// YAML file, like one maintained by a service discovery agent, and keeps a
+// peer list up to date as the file changes.
+//
+// To bind a peer list to a file,
+//
+// 	list := roundrobin.New(transport)
+// 	chooser := peer.Bind(list, file.NewBinder("/etc/myservice/peers.yaml"))
+//
+// The file lists peers as host:port strings, optionally with a weight and a
+// zone.
+//
+// 	peers:
+// 	  - 127.0.0.1:8080
+// 	  - peer: 127.0.0.1:8081
+// 	    weight: 3
+// 	    zone: us-west-1a
+//
+// The same structure is accepted as JSON.
+//
+// 	{"peers": ["127.0.0.1:8080", {"peer": "127.0.0.1:8081", "weight": 3}]}
+//
+// Peers with a weight or zone implement peer.WeightedIdentifier and
+// peer.ZonedIdentifier.
+//
+// The file is polled for changes and only the differences are pushed to the
+// peer list. If the file becomes unreadable, malformed or empty, the current
+// peers are left in place until the file is fixed.
+//
+// See Spec for configuring the updater with yarpcconfig.
+package file
@@ -0,0 +1,138 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/hostport"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is the format of a peers file.
+type Format string
+
+const (
+	// JSON is the format of files holding a JSON object.
+	JSON Format = "json"
+
+	// YAML is the format of files holding a YAML document.
+	YAML Format = "yaml"
+)
+
+// formatOf infers the format of a file from its extension, defaulting to
+// YAML.
+func formatOf(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return JSON
+	}
+	return YAML
+}
+
+type fileContents struct {
+	Peers []entry `json:"peers" yaml:"peers"`
+}
+
+// entry is a single peer in a file, either a host:port string or an object
+// with a peer and optional weight and zone.
+type entry struct {
+	Peer   string `json:"peer" yaml:"peer"`
+	Weight int    `json:"weight" yaml:"weight"`
+	Zone   string `json:"zone" yaml:"zone"`
+}
+
+// entryObject is an alias of entry without its custom unmarshaling.
+type entryObject entry
+
+func (e *entry) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &e.Peer); err == nil {
+		return nil
+	}
+	return decodeJSON(b, (*entryObject)(e))
+}
+
+func (e *entry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&e.Peer); err == nil {
+		return nil
+	}
+	return unmarshal((*entryObject)(e))
+}
+
+// parse parses the contents of a peers file into peer identifiers keyed by
+// identifier.
+func parse(format Format, data []byte) (map[string]peer.Identifier, error) {
+	var contents fileContents
+	switch format {
+	case JSON:
+		if err := decodeJSON(data, &contents); err != nil {
+			return nil, err
+		}
+	case YAML:
+		if err := yaml.UnmarshalStrict(data, &contents); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	peers := make(map[string]peer.Identifier, len(contents.Peers))
+	for i, e := range contents.Peers {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("invalid peer %d: %v", i, err)
+		}
+		if _, ok := peers[e.Peer]; ok {
+			return nil, fmt.Errorf("invalid peer %d: duplicate peer %q", i, e.Peer)
+		}
+		peers[e.Peer] = hostport.IdentifyWithAttributes(e.Peer, hostport.PeerAttributes{
+			Weight: e.Weight,
+			Zone:   e.Zone,
+		})
+	}
+	return peers, nil
+}
+
+// decodeJSON decodes JSON, rejecting unknown fields like YAML in strict
+// mode.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func (e entry) validate() error {
+	if e.Peer == "" {
+		return errors.New("peer is required")
+	}
+	if _, _, err := net.SplitHostPort(e.Peer); err != nil {
+		return err
+	}
+	if e.Weight < 0 {
+		return fmt.Errorf("weight of %q must not be negative, got %d", e.Peer, e.Weight)
+	}
+	return nil
+}
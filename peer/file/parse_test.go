@@ -0,0 +1,149 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, JSON, formatOf("/etc/peers.json"))
+	assert.Equal(t, JSON, formatOf("/etc/peers.JSON"))
+	assert.Equal(t, YAML, formatOf("/etc/peers.yaml"))
+	assert.Equal(t, YAML, formatOf("/etc/peers"))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		desc    string
+		format  Format
+		give    string
+		want    []peer.Identifier
+		wantErr string
+	}{
+		{
+			desc:   "yaml",
+			format: YAML,
+			give: `
+peers:
+  - 127.0.0.1:8080
+  - peer: 127.0.0.1:8081
+    weight: 3
+  - peer: 127.0.0.1:8082
+    zone: west
+`,
+			want: []peer.Identifier{
+				hostport.PeerIdentifier("127.0.0.1:8080"),
+				hostport.IdentifyWithAttributes("127.0.0.1:8081", hostport.PeerAttributes{Weight: 3}),
+				hostport.IdentifyWithAttributes("127.0.0.1:8082", hostport.PeerAttributes{Zone: "west"}),
+			},
+		},
+		{
+			desc:   "json",
+			format: JSON,
+			give:   `{"peers": ["127.0.0.1:8080", {"peer": "127.0.0.1:8081", "weight": 3, "zone": "east"}]}`,
+			want: []peer.Identifier{
+				hostport.PeerIdentifier("127.0.0.1:8080"),
+				hostport.IdentifyWithAttributes("127.0.0.1:8081", hostport.PeerAttributes{Weight: 3, Zone: "east"}),
+			},
+		},
+		{
+			desc:   "empty",
+			format: YAML,
+		},
+		{
+			desc:    "invalid yaml",
+			format:  YAML,
+			give:    "peers: [",
+			wantErr: "yaml",
+		},
+		{
+			desc:    "unknown yaml field",
+			format:  YAML,
+			give:    "peers:\n  - peer: 127.0.0.1:8080\n    wieght: 3",
+			wantErr: "wieght",
+		},
+		{
+			desc:    "invalid json",
+			format:  JSON,
+			give:    `{"peers": [`,
+			wantErr: "unexpected EOF",
+		},
+		{
+			desc:    "unknown json field",
+			format:  JSON,
+			give:    `{"peers": [{"peer": "127.0.0.1:8080", "wieght": 3}]}`,
+			wantErr: "wieght",
+		},
+		{
+			desc:    "missing peer",
+			format:  YAML,
+			give:    "peers:\n  - weight: 3",
+			wantErr: "invalid peer 0: peer is required",
+		},
+		{
+			desc:    "missing port",
+			format:  YAML,
+			give:    "peers:\n  - 127.0.0.1",
+			wantErr: "invalid peer 0: address 127.0.0.1: missing port in address",
+		},
+		{
+			desc:    "negative weight",
+			format:  JSON,
+			give:    `{"peers": [{"peer": "127.0.0.1:8080", "weight": -1}]}`,
+			wantErr: `invalid peer 0: weight of "127.0.0.1:8080" must not be negative, got -1`,
+		},
+		{
+			desc:    "duplicate peer",
+			format:  YAML,
+			give:    "peers:\n  - 127.0.0.1:8080\n  - peer: 127.0.0.1:8080",
+			wantErr: `invalid peer 1: duplicate peer "127.0.0.1:8080"`,
+		},
+		{
+			desc:    "unsupported format",
+			format:  Format("toml"),
+			wantErr: `unsupported format "toml"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			peers, err := parse(tt.format, []byte(tt.give))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			want := make(map[string]peer.Identifier)
+			for _, pid := range tt.want {
+				want[pid.Identifier()] = pid
+			}
+			assert.Equal(t, want, peers)
+		})
+	}
+}
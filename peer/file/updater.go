@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/peerdiff"
+	"go.uber.org/yarpc/pkg/lifecycle"
+	"go.uber.org/zap"
+)
+
+type updaterOptions struct {
+	format   Format
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// Option customizes the behavior of a file peer list updater.
+type Option func(*updaterOptions)
+
+// WithFormat specifies the format of the file.
+//
+// Defaults to JSON for files with a ".json" extension and YAML otherwise.
+func WithFormat(format Format) Option {
+	return func(o *updaterOptions) {
+		o.format = format
+	}
+}
+
+// Interval specifies how often the file is checked for changes.
+//
+// Defaults to 1 second.
+func Interval(d time.Duration) Option {
+	return func(o *updaterOptions) {
+		o.interval = d
+	}
+}
+
+// Logger specifies a logger for failures to read the file.
+func Logger(logger *zap.Logger) Option {
+	return func(o *updaterOptions) {
+		o.logger = logger
+	}
+}
+
+// NewBinder returns a peer.Binder that binds a peer list to the peers listed
+// in the given file.
+func NewBinder(path string, opts ...Option) peer.Binder {
+	return func(pl peer.List) transport.Lifecycle {
+		return NewUpdater(pl, path, opts...)
+	}
+}
+
+// NewUpdater builds a peer list updater that keeps the peer list up to date
+// with the peers listed in the given file while it is running.
+func NewUpdater(pl peer.List, path string, opts ...Option) *Updater {
+	options := updaterOptions{
+		format:   formatOf(path),
+		interval: time.Second,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.logger == nil {
+		options.logger = zap.NewNop()
+	}
+
+	return &Updater{
+		once:    lifecycle.NewOnce(),
+		list:    pl,
+		path:    path,
+		opts:    options,
+		log:     options.logger.With(zap.String("path", path)),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		peers:   make(map[string]peer.Identifier),
+	}
+}
+
+// Updater is a peer list updater that adds and removes peers as the file
+// listing them changes.
+type Updater struct {
+	once *lifecycle.Once
+	list peer.List
+	path string
+	opts updaterOptions
+	log  *zap.Logger
+
+	stop    chan struct{}
+	stopped chan struct{}
+
+	// The following are accessed only by Start before the polling loop
+	// begins, the polling loop itself, and Stop after the polling loop has
+	// ended.
+
+	// Contents of the file when it was last read and the error parsing
+	// them, if any.
+	contents []byte
+	parseErr error
+
+	// Error from the previous reload, used to log only changes.
+	lastErr error
+
+	// Peers most recently pushed to the list.
+	peers map[string]peer.Identifier
+}
+
+// Start reads the file, adds its peers to the peer list and begins watching
+// the file for changes.
+//
+// Start fails if the file cannot be read or is malformed.
+func (u *Updater) Start() error {
+	return u.once.Start(u.start)
+}
+
+func (u *Updater) start() error {
+	if err := u.reload(); err != nil {
+		return fmt.Errorf("failed to read peers from %q: %v", u.path, err)
+	}
+	go u.run()
+	return nil
+}
+
+// Stop stops watching the file and removes all peers it added from the peer
+// list.
+func (u *Updater) Stop() error {
+	return u.once.Stop(u.stopUpdater)
+}
+
+func (u *Updater) stopUpdater() error {
+	close(u.stop)
+	<-u.stopped
+
+	updates := peerdiff.Diff(u.peers, nil)
+	u.peers = make(map[string]peer.Identifier)
+	return u.list.Update(updates)
+}
+
+// IsRunning returns whether the updater is running.
+func (u *Updater) IsRunning() bool {
+	return u.once.IsRunning()
+}
+
+func (u *Updater) run() {
+	defer close(u.stopped)
+
+	ticker := time.NewTicker(u.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-u.stop:
+			return
+		case <-ticker.C:
+		}
+
+		err := u.reload()
+		switch {
+		case err != nil && (u.lastErr == nil || u.lastErr.Error() != err.Error()):
+			u.log.Warn("Failed to read peers from file, retaining last known peers.", zap.Error(err))
+		case err == nil && u.lastErr != nil:
+			u.log.Info("Read peers from file after previous failure.")
+		}
+		u.lastErr = err
+	}
+}
+
+// reload reads the file and, if it changed, pushes the differences to the
+// peer list.
+func (u *Updater) reload() error {
+	contents, err := ioutil.ReadFile(u.path)
+	if err != nil {
+		return err
+	}
+	if u.contents != nil && bytes.Equal(contents, u.contents) {
+		// Unchanged since it was last read, including if it was malformed.
+		return u.parseErr
+	}
+	u.contents = contents
+
+	peers, err := parse(u.opts.format, contents)
+	if err == nil && len(peers) == 0 {
+		// Most likely a partially written file.
+		err = errors.New("no peers found")
+	}
+	u.parseErr = err
+	if err != nil {
+		return err
+	}
+
+	updates := peerdiff.Diff(u.peers, peers)
+	u.peers = peers
+	if peerdiff.IsEmpty(updates) {
+		return nil
+	}
+	if err := u.list.Update(updates); err != nil {
+		u.log.Error("Failed to update peer list.", zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+// fakeList records the updates it receives.
+type fakeList struct {
+	updates chan peer.ListUpdates
+}
+
+func newFakeList() *fakeList {
+	return &fakeList{updates: make(chan peer.ListUpdates, 10)}
+}
+
+func (l *fakeList) Update(updates peer.ListUpdates) error {
+	l.updates <- updates
+	return nil
+}
+
+func (l *fakeList) expect(t *testing.T, want peer.ListUpdates) {
+	select {
+	case updates := <-l.updates:
+		assert.Equal(t, want, updates)
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for peer list update")
+	}
+}
+
+func (l *fakeList) expectNone(t *testing.T) {
+	select {
+	case updates := <-l.updates:
+		t.Fatalf("unexpected peer list update: %v", updates)
+	default:
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "yarpc-peer-file")
+	require.NoError(t, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestUpdaterReload(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "peers.yaml")
+
+	var (
+		a        = hostport.PeerIdentifier("127.0.0.1:8080")
+		b        = hostport.PeerIdentifier("127.0.0.1:8081")
+		weightyB = hostport.IdentifyWithAttributes("127.0.0.1:8081", hostport.PeerAttributes{Weight: 2})
+		c        = hostport.PeerIdentifier("127.0.0.1:8082")
+	)
+
+	list := newFakeList()
+	updater := NewUpdater(list, path)
+
+	writeFile(t, path, "peers: [127.0.0.1:8080, 127.0.0.1:8081]")
+	require.NoError(t, updater.reload())
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{a, b}})
+
+	// Unchanged files do not update the list.
+	require.NoError(t, updater.reload())
+	list.expectNone(t)
+
+	writeFile(t, path, "peers: [127.0.0.1:8081, 127.0.0.1:8082]")
+	require.NoError(t, updater.reload())
+	list.expect(t, peer.ListUpdates{
+		Additions: []peer.Identifier{c},
+		Removals:  []peer.Identifier{a},
+	})
+
+	// Malformed, empty and missing files retain the current peers.
+	writeFile(t, path, "peers: [127.0.0.1")
+	assert.Error(t, updater.reload())
+	assert.Error(t, updater.reload(), "unchanged malformed file must still fail")
+	writeFile(t, path, "")
+	assert.EqualError(t, updater.reload(), "no peers found")
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, updater.reload())
+	list.expectNone(t)
+
+	// Changing the weight of a peer changes it in place.
+	writeFile(t, path, "peers: [{peer: 127.0.0.1:8081, weight: 2}, 127.0.0.1:8082]")
+	require.NoError(t, updater.reload())
+	list.expect(t, peer.ListUpdates{Changes: []peer.Identifier{weightyB}})
+}
+
+func TestUpdaterLifecycle(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "peers.json")
+
+	var (
+		a = hostport.PeerIdentifier("127.0.0.1:8080")
+		b = hostport.PeerIdentifier("127.0.0.1:8081")
+	)
+
+	list := newFakeList()
+	updater := NewBinder(path, Interval(time.Millisecond))(list)
+
+	writeFile(t, path, `{"peers": ["127.0.0.1:8080"]}`)
+	require.NoError(t, updater.Start())
+	assert.True(t, updater.IsRunning())
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{a}})
+
+	writeFile(t, path, `{"peers": ["127.0.0.1:8081"]}`)
+	list.expect(t, peer.ListUpdates{
+		Additions: []peer.Identifier{b},
+		Removals:  []peer.Identifier{a},
+	})
+
+	require.NoError(t, updater.Stop())
+	assert.False(t, updater.IsRunning())
+	list.expect(t, peer.ListUpdates{Removals: []peer.Identifier{b}})
+}
+
+func TestUpdaterStartFailure(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "peers.yaml")
+
+	list := newFakeList()
+	updater := NewUpdater(list, path)
+	err := updater.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read peers from")
+
+	writeFile(t, path, "peers: [127.0.0.1:8080]")
+	updater = NewUpdater(list, path, WithFormat(JSON))
+	err = updater.Start()
+	require.Error(t, err, "must fail to parse YAML as JSON")
+	list.expectNone(t)
+}
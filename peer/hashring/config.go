@@ -0,0 +1,143 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashring
+
+import (
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// Configuration describes how to build a consistent hashing peer list.
+type Configuration struct {
+	Capacity     *int `config:"capacity"`
+	VirtualNodes *int `config:"virtualNodes"`
+
+	// Key of requests to hash: "shard-key" (the default), "routing-key" or
+	// "header". Hashing a header requires its name in Header.
+	Key    string `config:"key"`
+	Header string `config:"header"`
+
+	// BoundedLoads caps the pending requests of each peer at (1 +
+	// BoundedLoads) times the average, if specified.
+	BoundedLoads *float64 `config:"boundedLoads"`
+
+	// Reports metrics for the list to the metrics scope of the Configurator.
+	Metrics bool `config:"metrics"`
+}
+
+// Spec returns a configuration specification for the consistent hashing peer
+// list implementation, making it possible to send requests with the same key
+// to the same peer with transports that use outbound peer list configuration
+// (like HTTP).
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerList(hashring.Spec())
+//
+// This enables the consistent hashing peer list:
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: https://host:port/rpc
+//          consistent-hash:
+//            key: header
+//            header: x-user-id
+//            peers:
+//              - 127.0.0.1:8080
+//              - 127.0.0.1:8081
+//
+// Bounding the load of peers spills requests for hot keys over to the next
+// peers on the ring:
+//
+//          consistent-hash:
+//            key: routing-key
+//            boundedLoads: 0.25
+//            peers:
+//              - 127.0.0.1:8080
+//              - 127.0.0.1:8081
+//
+// Setting metrics to true reports the list's metrics to the metrics scope
+// given to the Configurator with yarpcconfig.Metrics, tagged with the
+// outbound. Enabling metrics without such a scope is an error.
+func Spec() yarpcconfig.PeerListSpec {
+	return yarpcconfig.PeerListSpec{
+		Name: "consistent-hash",
+		BuildPeerList: func(cfg Configuration, t peer.Transport, k *yarpcconfig.Kit) (peer.ChooserList, error) {
+			var opts []ListOption
+
+			if cfg.Capacity != nil {
+				if *cfg.Capacity <= 0 {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						"Capacity must be greater than 0. Got: %d.", *cfg.Capacity)
+				}
+				opts = append(opts, Capacity(*cfg.Capacity))
+			}
+
+			if cfg.VirtualNodes != nil {
+				if *cfg.VirtualNodes <= 0 {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						"VirtualNodes must be greater than 0. Got: %d.", *cfg.VirtualNodes)
+				}
+				opts = append(opts, VirtualNodes(*cfg.VirtualNodes))
+			}
+
+			if cfg.BoundedLoads != nil {
+				if *cfg.BoundedLoads <= 0 {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						"BoundedLoads must be greater than 0. Got: %v.", *cfg.BoundedLoads)
+				}
+				opts = append(opts, BoundedLoads(*cfg.BoundedLoads))
+			}
+
+			if cfg.Header != "" && cfg.Key != "header" {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					`Header may only be specified when Key is "header". Got Key: %q.`, cfg.Key)
+			}
+			switch cfg.Key {
+			case "", "shard-key":
+				opts = append(opts, ShardKey())
+			case "routing-key":
+				opts = append(opts, RoutingKey())
+			case "header":
+				if cfg.Header == "" {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						`Header is required when Key is "header".`)
+				}
+				opts = append(opts, Header(cfg.Header))
+			default:
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					`Key must be "shard-key", "routing-key" or "header". Got: %q.`, cfg.Key)
+			}
+
+			if cfg.Metrics {
+				meter, err := k.RequireMeter()
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, Meter(meter))
+			}
+
+			return New(t, opts...), nil
+		},
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+type attrs map[string]interface{}
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		desc    string
+		give    attrs
+		wantErr string
+	}{
+		{
+			desc: "defaults",
+			give: attrs{},
+		},
+		{
+			desc: "all options",
+			give: attrs{
+				"capacity":     20,
+				"virtualNodes": 50,
+				"key":          "header",
+				"header":       "x-user-id",
+			},
+		},
+		{
+			desc: "routing key",
+			give: attrs{"key": "routing-key"},
+		},
+		{
+			desc: "bounded loads",
+			give: attrs{"key": "routing-key", "boundedLoads": 0.25},
+		},
+		{
+			desc:    "invalid capacity",
+			give:    attrs{"capacity": 0},
+			wantErr: "Capacity must be greater than 0. Got: 0.",
+		},
+		{
+			desc:    "invalid virtual nodes",
+			give:    attrs{"virtualNodes": -1},
+			wantErr: "VirtualNodes must be greater than 0. Got: -1.",
+		},
+		{
+			desc:    "invalid bounded loads",
+			give:    attrs{"boundedLoads": 0},
+			wantErr: "BoundedLoads must be greater than 0. Got: 0.",
+		},
+		{
+			desc:    "unknown key",
+			give:    attrs{"key": "procedure"},
+			wantErr: `Key must be "shard-key", "routing-key" or "header". Got: "procedure".`,
+		},
+		{
+			desc:    "header without name",
+			give:    attrs{"key": "header"},
+			wantErr: `Header is required when Key is "header".`,
+		},
+		{
+			desc:    "header name without header key",
+			give:    attrs{"header": "x-user-id"},
+			wantErr: `Header may only be specified when Key is "header". Got Key: "".`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			listCfg := attrs{"peers": []string{"1.1.1.1:1111", "2.2.2.2:2222"}}
+			for k, v := range tt.give {
+				listCfg[k] = v
+			}
+
+			cfg := yarpcconfig.New()
+			require.NoError(t, cfg.RegisterPeerList(Spec()))
+			require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+			config, err := cfg.LoadConfig("our-service", attrs{
+				"outbounds": attrs{
+					"their-service": attrs{
+						"fake-transport": attrs{
+							"consistent-hash": listCfg,
+						},
+					},
+				},
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, config.Outbounds["their-service"].Unary)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package hashring provides a peer list that sends requests with the same key
+// to the same peer using consistent hashing.
+//
+// Each peer is placed on a ring at many points (virtual nodes) derived from
+// a hash of its identifier. A request is sent to the first peer on the ring
+// at or after the hash of its key, which is the shard key by default, or the
+// routing key or a header if configured. Only available peers are on the
+// ring, so while a peer is unavailable its requests fall back to the next
+// available peer on the ring, and adding or removing a peer only moves the
+// keys adjacent to its virtual nodes.
+//
+// Requests without a key are sent to a random peer.
+//
+// With bounded loads, a peer with more than (1+ε) times the average number of
+// pending requests is skipped, and its requests spill over to the next peer on
+// the ring, so that hot keys do not overload their peers.
+//
+// 	list := hashring.New(transport, hashring.Header("x-user-id"))
+// 	list := hashring.New(transport, hashring.RoutingKey(), hashring.BoundedLoads(0.25))
+//
+// See Spec for configuring the list with yarpcconfig.
+package hashring
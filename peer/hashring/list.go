@@ -0,0 +1,177 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashring
+
+import (
+	"math/rand"
+	"time"
+
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/peerlist/v2"
+)
+
+type listOptions struct {
+	capacity     int
+	virtualNodes int
+	key          func(*transport.Request) string
+	source       rand.Source
+	loadFactor   float64
+	meter        *metrics.Scope
+}
+
+var defaultListOptions = listOptions{
+	capacity:     10,
+	virtualNodes: 100,
+	key:          shardKey,
+}
+
+// ListOption customizes the behavior of a consistent hashing peer list.
+type ListOption interface {
+	apply(*listOptions)
+}
+
+type listOptionFunc func(*listOptions)
+
+func (f listOptionFunc) apply(options *listOptions) { f(options) }
+
+// Capacity specifies the default capacity of the underlying
+// data structures for this list.
+//
+// Defaults to 10.
+func Capacity(capacity int) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.capacity = capacity
+	})
+}
+
+// VirtualNodes specifies the number of points at which each peer is placed on
+// the ring. More virtual nodes spread keys more evenly across peers at the
+// cost of memory and time to add and remove peers.
+//
+// Defaults to 100.
+func VirtualNodes(n int) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.virtualNodes = n
+	})
+}
+
+// ShardKey hashes the shard key of requests to choose a peer.
+//
+// This is the default.
+func ShardKey() ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.key = shardKey
+	})
+}
+
+// RoutingKey hashes the routing key of requests to choose a peer.
+func RoutingKey() ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.key = routingKey
+	})
+}
+
+// Header hashes the value of the given request header to choose a peer.
+func Header(name string) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.key = func(req *transport.Request) string {
+			v, _ := req.Headers.Get(name)
+			return v
+		}
+	})
+}
+
+// BoundedLoads caps the pending requests of each peer at (1 + epsilon) times
+// the average pending requests of the peers on the ring, rounded up. Requests
+// whose peer is at capacity spill over to the next peer on the ring with
+// capacity, so that a hot key does not overload a peer while most keys keep
+// going to the same peer.
+//
+// Smaller values of epsilon spread load more evenly at the cost of moving
+// more keys away from their peer. Bounded loads are disabled by default.
+func BoundedLoads(epsilon float64) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.loadFactor = epsilon
+	})
+}
+
+// Seed specifies the seed for choosing peers for requests without a key.
+func Seed(seed int64) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.source = rand.NewSource(seed)
+	})
+}
+
+func shardKey(req *transport.Request) string {
+	return req.ShardKey
+}
+
+func routingKey(req *transport.Request) string {
+	return req.RoutingKey
+}
+
+// Meter specifies a metrics scope for the list's metrics, like the number of
+// pending requests to each peer and the latency of choosing a peer. See
+// peerlist.Meter for all metrics.
+//
+// Metrics are disabled by default.
+func Meter(meter *metrics.Scope) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.meter = meter
+	})
+}
+
+// New creates a new consistent hashing peer list.
+func New(transport peer.Transport, opts ...ListOption) *List {
+	options := defaultListOptions
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	if options.source == nil {
+		options.source = rand.NewSource(time.Now().UnixNano())
+	}
+
+	plOpts := []peerlist.ListOption{
+		peerlist.Capacity(options.capacity),
+		peerlist.NoShuffle(),
+	}
+	if options.meter != nil {
+		plOpts = append(plOpts, peerlist.Meter(options.meter))
+	}
+
+	return &List{
+		List: peerlist.New(
+			"consistent-hash",
+			transport,
+			newHashRing(options.virtualNodes, options.key, options.source, options.loadFactor),
+			plOpts...,
+		),
+	}
+}
+
+// List is a PeerList that chooses peers by consistent hashing of a request
+// key.
+type List struct {
+	*peerlist.List
+}
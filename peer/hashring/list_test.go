@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/peer"
+	. "go.uber.org/yarpc/api/peer/peertest"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func TestConsistentHashList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	trans := NewMockTransport(mockCtrl)
+	peers := ExpectPeerRetains(trans, []string{"1", "2", "3"}, nil)
+	ExpectPeerReleases(trans, []string{"1", "2", "3"}, nil)
+
+	pl := New(trans, Seed(0))
+	deps := ListActionDeps{Peers: peers}
+	ApplyPeerListActions(t, pl, []PeerListAction{
+		StartAction{},
+		UpdateAction{AddedPeerIDs: []string{"1", "2", "3"}},
+	}, deps)
+
+	choose := func(key string) string {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		p, onFinish, err := pl.Choose(ctx, &transport.Request{ShardKey: key})
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		onFinish(nil)
+		return p.Identifier()
+	}
+
+	// Requests with the same key go to the same peer.
+	chosen := choose("foo")
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, chosen, choose("foo"))
+	}
+
+	// While the peer is unavailable, requests fall back to another peer, and
+	// return once it is available again.
+	ApplyPeerListActions(t, pl, []PeerListAction{
+		NotifyStatusChangeAction{PeerID: chosen, NewConnectionStatus: peer.Unavailable},
+	}, deps)
+	fallback := choose("foo")
+	assert.NotEqual(t, chosen, fallback)
+	assert.Equal(t, fallback, choose("foo"))
+
+	ApplyPeerListActions(t, pl, []PeerListAction{
+		NotifyStatusChangeAction{PeerID: chosen, NewConnectionStatus: peer.Available},
+	}, deps)
+	assert.Equal(t, chosen, choose("foo"))
+
+	ApplyPeerListActions(t, pl, []PeerListAction{StopAction{}}, deps)
+}
+
+func TestConsistentHashListNotRunning(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ApplyPeerListActions(t, New(NewMockTransport(mockCtrl)), []PeerListAction{
+		ChooseAction{
+			InputContextTimeout: 10 * time.Millisecond,
+			InputRequest:        &transport.Request{ShardKey: "foo"},
+			ExpectedErr:         yarpcerrors.FailedPreconditionErrorf("consistent-hash peer list is not running: %s", "context finished while waiting for instance to start: context deadline exceeded"),
+		},
+	}, ListActionDeps{})
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashring
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/peerlist/v2"
+)
+
+type subscriber struct {
+	peer peer.StatusPeer
+}
+
+func (s *subscriber) NotifyStatusChanged(pid peer.Identifier) {}
+
+// point is a virtual node of a peer on the ring.
+type point struct {
+	hash uint64
+	sub  *subscriber
+}
+
+// hashRing places available peers on a ring of virtual nodes and chooses the
+// first peer at or after the hash of the request key.
+//
+// hashRing is NOT thread-safe; the peer list calls it under a lock.
+type hashRing struct {
+	virtualNodes int
+	key          func(*transport.Request) string
+	random       *rand.Rand
+
+	// Bounds the pending requests of each peer to (1 + loadFactor) times the
+	// average, if greater than 0.
+	loadFactor float64
+
+	// Virtual nodes sorted by hash.
+	points []point
+
+	// Peers on the ring.
+	subs []*subscriber
+}
+
+var _ peerlist.Implementation = (*hashRing)(nil)
+
+func newHashRing(virtualNodes int, key func(*transport.Request) string, source rand.Source, loadFactor float64) *hashRing {
+	if virtualNodes < 1 {
+		virtualNodes = 1
+	}
+	return &hashRing{
+		virtualNodes: virtualNodes,
+		key:          key,
+		random:       rand.New(source),
+		loadFactor:   loadFactor,
+	}
+}
+
+// Add places the peer on the ring at each of its virtual nodes.
+func (r *hashRing) Add(p peer.StatusPeer, _ peer.Identifier) peer.Subscriber {
+	sub := &subscriber{peer: p}
+	r.subs = append(r.subs, sub)
+	id := p.Identifier()
+	for i := 0; i < r.virtualNodes; i++ {
+		r.points = append(r.points, point{
+			hash: hash(id + "#" + strconv.Itoa(i)),
+			sub:  sub,
+		})
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash == r.points[j].hash {
+			// Break ties deterministically, regardless of the order in
+			// which peers were added.
+			return r.points[i].sub.peer.Identifier() < r.points[j].sub.peer.Identifier()
+		}
+		return r.points[i].hash < r.points[j].hash
+	})
+	return sub
+}
+
+// Remove takes all virtual nodes of the peer off the ring.
+func (r *hashRing) Remove(_ peer.StatusPeer, _ peer.Identifier, s peer.Subscriber) {
+	sub, ok := s.(*subscriber)
+	if !ok {
+		// Don't panic.
+		return
+	}
+
+	points := r.points[:0]
+	for _, pt := range r.points {
+		if pt.sub != sub {
+			points = append(points, pt)
+		}
+	}
+	for i := len(points); i < len(r.points); i++ {
+		r.points[i] = point{} // release references to removed peers
+	}
+	r.points = points
+
+	for i, other := range r.subs {
+		if other == sub {
+			last := len(r.subs) - 1
+			r.subs[i] = r.subs[last]
+			r.subs[last] = nil
+			r.subs = r.subs[:last]
+			break
+		}
+	}
+}
+
+// Choose returns the peer that owns the hash of the request key, a random
+// peer if the request has no key, or nil if the ring is empty.
+//
+// With bounded loads, Choose returns the first peer at or after the hash of
+// the key that has fewer pending requests than the bound.
+func (r *hashRing) Choose(_ context.Context, req *transport.Request) peer.StatusPeer {
+	if len(r.points) == 0 {
+		return nil
+	}
+
+	var key string
+	if req != nil {
+		key = r.key(req)
+	}
+	if key == "" {
+		return r.points[r.random.Intn(len(r.points))].sub.peer
+	}
+
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	if i == len(r.points) {
+		// Wrap around the ring.
+		i = 0
+	}
+	if r.loadFactor <= 0 {
+		return r.points[i].sub.peer
+	}
+
+	bound := r.loadBound()
+	for n := 0; n < len(r.points); n++ {
+		p := r.points[(i+n)%len(r.points)].sub.peer
+		if p.Status().PendingRequestCount < bound {
+			return p
+		}
+	}
+	// Unreachable since the pending requests of some peer must be at most
+	// the average, but don't fail the request.
+	return r.points[i].sub.peer
+}
+
+// loadBound returns the number of pending requests, including the request
+// being routed, above which a peer is skipped: (1 + loadFactor) times the
+// average, rounded up.
+func (r *hashRing) loadBound() int {
+	pending := 1
+	for _, sub := range r.subs {
+		pending += sub.peer.Status().PendingRequestCount
+	}
+	avg := float64(pending) / float64(len(r.subs))
+	return int(math.Ceil(avg * (1 + r.loadFactor)))
+}
+
+func (r *hashRing) Start() error {
+	return nil
+}
+
+func (r *hashRing) Stop() error {
+	return nil
+}
+
+func (r *hashRing) IsRunning() bool {
+	return true
+}
+
+// hash returns a well-distributed 64-bit hash of the given string.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix(h.Sum64())
+}
+
+// mix is the 64-bit finalizer from MurmurHash3, which spreads FNV hashes of
+// similar strings, like the virtual nodes of a peer, across the ring.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package hashring
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+type ringPeers struct {
+	ring  *hashRing
+	subs  map[string]peer.Subscriber
+	peers map[string]*hostport.Peer
+}
+
+func newRingPeers(ids ...string) *ringPeers {
+	return newBoundedRingPeers(0, ids...)
+}
+
+func newBoundedRingPeers(loadFactor float64, ids ...string) *ringPeers {
+	rp := &ringPeers{
+		ring:  newHashRing(100, shardKey, rand.NewSource(0), loadFactor),
+		subs:  make(map[string]peer.Subscriber),
+		peers: make(map[string]*hostport.Peer),
+	}
+	for _, id := range ids {
+		rp.add(id)
+	}
+	return rp
+}
+
+func (rp *ringPeers) add(id string) {
+	pid := hostport.PeerIdentifier(id)
+	rp.peers[id] = hostport.NewPeer(pid, nil)
+	rp.subs[id] = rp.ring.Add(rp.peers[id], pid)
+}
+
+func (rp *ringPeers) remove(id string) {
+	pid := hostport.PeerIdentifier(id)
+	rp.ring.Remove(rp.peers[id], pid, rp.subs[id])
+	delete(rp.subs, id)
+	delete(rp.peers, id)
+}
+
+// assignments returns the peer chosen for each of n keys.
+func (rp *ringPeers) assignments(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		p := rp.ring.Choose(context.Background(), &transport.Request{ShardKey: fmt.Sprintf("key-%d", i)})
+		out[i] = p.Identifier()
+	}
+	return out
+}
+
+func TestHashRingEmpty(t *testing.T) {
+	rp := newRingPeers()
+	assert.Nil(t, rp.ring.Choose(context.Background(), &transport.Request{ShardKey: "foo"}))
+}
+
+func TestHashRingConsistent(t *testing.T) {
+	rp := newRingPeers("1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80")
+	before := rp.assignments(1000)
+
+	// The same keys map to the same peers regardless of insertion order.
+	assert.Equal(t, before, newRingPeers("3.3.3.3:80", "1.1.1.1:80", "2.2.2.2:80").assignments(1000))
+
+	// Removing a peer only moves its own keys, to the next peers on the ring.
+	rp.remove("2.2.2.2:80")
+	during := rp.assignments(1000)
+	for i := range before {
+		if before[i] != "2.2.2.2:80" {
+			assert.Equal(t, before[i], during[i], "key %d moved", i)
+		} else {
+			assert.NotEqual(t, "2.2.2.2:80", during[i])
+		}
+	}
+
+	// Adding it back restores the original assignments.
+	rp.add("2.2.2.2:80")
+	assert.Equal(t, before, rp.assignments(1000))
+}
+
+func TestHashRingDistribution(t *testing.T) {
+	rp := newRingPeers("1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80", "4.4.4.4:80")
+
+	counts := make(map[string]int)
+	for _, id := range rp.assignments(10000) {
+		counts[id]++
+	}
+	require.Len(t, counts, 4)
+	for id, count := range counts {
+		// Perfectly even would be 2500 each.
+		assert.InDelta(t, 2500, count, 750, "uneven share for %v", id)
+	}
+}
+
+func TestHashRingKeys(t *testing.T) {
+	req := &transport.Request{
+		ShardKey:   "shard",
+		RoutingKey: "routing",
+		Headers:    transport.NewHeaders().With("x-user-id", "user"),
+	}
+
+	tests := []struct {
+		desc string
+		key  func(*transport.Request) string
+		want string
+	}{
+		{desc: "shard key", key: shardKey, want: "shard"},
+		{desc: "routing key", key: routingKey, want: "routing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.key(req))
+		})
+	}
+
+	var options listOptions
+	Header("X-User-ID").apply(&options)
+	assert.Equal(t, "user", options.key(req))
+	assert.Equal(t, "", options.key(&transport.Request{}))
+}
+
+func TestHashRingNoKey(t *testing.T) {
+	rp := newRingPeers("1.1.1.1:80", "2.2.2.2:80")
+
+	seen := make(map[string]struct{})
+	for i := 0; i < 100; i++ {
+		p := rp.ring.Choose(context.Background(), &transport.Request{})
+		seen[p.Identifier()] = struct{}{}
+	}
+	assert.Len(t, seen, 2, "requests without a key should be spread across peers")
+	assert.NotNil(t, rp.ring.Choose(context.Background(), nil))
+}
+
+func TestHashRingRemoveUnknownSubscriber(t *testing.T) {
+	rp := newRingPeers("1.1.1.1:80")
+	pid := hostport.PeerIdentifier("1.1.1.1:80")
+	rp.ring.Remove(hostport.NewPeer(pid, nil), pid, nil)
+	assert.Len(t, rp.ring.points, 100)
+}
+
+func TestHashRingBoundedLoads(t *testing.T) {
+	ids := []string{"1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80"}
+	rp := newBoundedRingPeers(0.25, ids...)
+
+	// Without pending requests, keys go to the same peers as without bounds.
+	assert.Equal(t, newRingPeers(ids...).assignments(1000), rp.assignments(1000))
+
+	hot := &transport.Request{ShardKey: "hot"}
+	owner := rp.ring.Choose(context.Background(), hot).Identifier()
+	for i := 0; i < 30; i++ {
+		rp.ring.Choose(context.Background(), hot).(*hostport.Peer).StartRequest()
+	}
+
+	// Each peer is capped at 1.25 times the average of 10 pending requests,
+	// rounded up, so the owner of the hot key takes its share and the rest
+	// spill over to the other peers.
+	assert.Equal(t, 13, rp.peers[owner].Status().PendingRequestCount)
+	for _, id := range ids {
+		assert.True(t, rp.peers[id].Status().PendingRequestCount <= 13, "peer %v over capacity", id)
+	}
+
+	// As the owner finishes requests, the hot key returns to it.
+	for i := 0; i < 5; i++ {
+		rp.peers[owner].EndRequest()
+	}
+	assert.Equal(t, owner, rp.ring.Choose(context.Background(), hot).Identifier())
+
+	// Removed peers no longer count toward the average.
+	for _, id := range ids {
+		if id != owner {
+			rp.remove(id)
+		}
+	}
+	assert.Equal(t, owner, rp.ring.Choose(context.Background(), hot).Identifier())
+	assert.Len(t, rp.ring.subs, 1)
+}
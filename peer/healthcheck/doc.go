@@ -0,0 +1,49 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package healthcheck marks peers unavailable while they fail active health
+// checks.
+//
+// A process that accepts connections but cannot serve requests looks
+// available to transports. The Transport in this package wraps a peer
+// transport and periodically checks the health of each peer it retains, for
+// example by calling a health procedure on it. A peer that fails several
+// consecutive checks reports itself unavailable, even though it is
+// connected, until it passes several consecutive checks again.
+//
+// Any peer list built on the wrapping transport takes peer health into
+// account, because peer lists choose among available peers and are notified
+// when their status changes.
+//
+// 	check := healthcheck.Procedure(httpTransport, func(c peer.Chooser) transport.UnaryOutbound {
+// 		return httpTransport.NewOutbound(c)
+// 	}, "myservice", "health")
+// 	list := roundrobin.New(healthcheck.NewTransport(httpTransport, check))
+//
+// Peers serving the standard gRPC health service are checked by calling its
+// Check method and verifying the status it returns.
+//
+// 	check := healthcheck.Procedure(grpcTransport, func(c peer.Chooser) transport.UnaryOutbound {
+// 		return grpcTransport.NewOutbound(c)
+// 	}, "myservice", "grpc.health.v1.Health::Check",
+// 		healthcheck.Encoding("proto"),
+// 		healthcheck.ValidateGRPCServing(),
+// 	)
+package healthcheck
@@ -0,0 +1,185 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/zap"
+)
+
+// checkedPeer is a peer of the underlying transport that reports itself
+// unavailable while it is unhealthy. It subscribes to the underlying peer on
+// behalf of its own subscribers.
+type checkedPeer struct {
+	peer.Peer
+
+	pid       peer.Identifier
+	transport *Transport
+	stopped   chan struct{}
+
+	lock        sync.Mutex
+	subscribers map[peer.Subscriber]struct{}
+	healthy     bool
+	// Consecutive checks that disagree with the current health.
+	streak int
+}
+
+var (
+	_ peer.Peer       = (*checkedPeer)(nil)
+	_ peer.Subscriber = (*checkedPeer)(nil)
+)
+
+func newCheckedPeer(pid peer.Identifier, t *Transport) *checkedPeer {
+	return &checkedPeer{
+		pid:         pid,
+		transport:   t,
+		stopped:     make(chan struct{}),
+		subscribers: make(map[peer.Subscriber]struct{}),
+		healthy:     true,
+	}
+}
+
+func (p *checkedPeer) setPeer(inner peer.Peer) {
+	p.lock.Lock()
+	p.Peer = inner
+	p.lock.Unlock()
+}
+
+// Status returns the status of the underlying peer, except that a connected
+// peer is unavailable while it is unhealthy.
+func (p *checkedPeer) Status() peer.Status {
+	p.lock.Lock()
+	inner, healthy := p.Peer, p.healthy
+	p.lock.Unlock()
+
+	status := inner.Status()
+	if !healthy && status.ConnectionStatus == peer.Available {
+		status.ConnectionStatus = peer.Unavailable
+	}
+	return status
+}
+
+// NotifyStatusChanged forwards status changes of the underlying peer to
+// subscribers.
+func (p *checkedPeer) NotifyStatusChanged(peer.Identifier) {
+	p.notify()
+}
+
+func (p *checkedPeer) notify() {
+	p.lock.Lock()
+	subscribers := make([]peer.Subscriber, 0, len(p.subscribers))
+	for sub := range p.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	p.lock.Unlock()
+
+	for _, sub := range subscribers {
+		sub.NotifyStatusChanged(p.pid)
+	}
+}
+
+func (p *checkedPeer) subscribe(sub peer.Subscriber) {
+	p.lock.Lock()
+	p.subscribers[sub] = struct{}{}
+	p.lock.Unlock()
+}
+
+// unsubscribe removes the subscriber, returning the number of remaining
+// subscribers.
+func (p *checkedPeer) unsubscribe(sub peer.Subscriber) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.subscribers[sub]; !ok {
+		return 0, peer.ErrPeerHasNoReferenceToSubscriber{
+			PeerIdentifier: p.pid,
+			PeerSubscriber: sub,
+		}
+	}
+	delete(p.subscribers, sub)
+	return len(p.subscribers), nil
+}
+
+func (p *checkedPeer) stop() {
+	close(p.stopped)
+}
+
+func (p *checkedPeer) checkLoop() {
+	options := p.transport.options
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-p.stopped:
+			return
+		case <-timer.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), options.timeout)
+		err := p.transport.check(ctx, p.pid)
+		cancel()
+
+		p.record(err)
+		timer.Reset(options.interval)
+	}
+}
+
+// record records the result of a health check, notifying subscribers if the
+// peer becomes healthy or unhealthy.
+func (p *checkedPeer) record(err error) {
+	options := p.transport.options
+
+	p.lock.Lock()
+	if (err == nil) == p.healthy {
+		p.streak = 0
+		p.lock.Unlock()
+		return
+	}
+
+	p.streak++
+	threshold := options.unhealthyThreshold
+	if !p.healthy {
+		threshold = options.healthyThreshold
+	}
+	if p.streak < threshold {
+		p.lock.Unlock()
+		return
+	}
+
+	p.healthy = !p.healthy
+	p.streak = 0
+	healthy := p.healthy
+	p.lock.Unlock()
+
+	if healthy {
+		options.logger.Info("peer passed health checks",
+			zap.String("peer", p.pid.Identifier()))
+	} else {
+		options.logger.Warn("peer failed health checks",
+			zap.String("peer", p.pid.Identifier()), zap.Error(err))
+	}
+	p.notify()
+}
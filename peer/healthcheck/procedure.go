@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	yarpcpeer "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/yarpcerrors"
+	hpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type procedureOptions struct {
+	caller   string
+	encoding transport.Encoding
+	body     []byte
+	validate func([]byte) error
+}
+
+var defaultProcedureOptions = procedureOptions{
+	caller:   "yarpc-health-check",
+	encoding: "raw",
+}
+
+// ProcedureOption customizes the request made by a Procedure check.
+type ProcedureOption func(*procedureOptions)
+
+// Caller specifies the name of the caller of health checks.
+//
+// Defaults to "yarpc-health-check".
+func Caller(name string) ProcedureOption {
+	return func(o *procedureOptions) {
+		o.caller = name
+	}
+}
+
+// Encoding specifies the encoding of health check requests.
+//
+// Defaults to raw.
+func Encoding(encoding transport.Encoding) ProcedureOption {
+	return func(o *procedureOptions) {
+		o.encoding = encoding
+	}
+}
+
+// Body specifies the body of health check requests, already encoded.
+//
+// Defaults to an empty body.
+func Body(body []byte) ProcedureOption {
+	return func(o *procedureOptions) {
+		o.body = body
+	}
+}
+
+// ValidateResponse specifies a function that checks the body of successful
+// health check responses, failing the check if it returns an error. For
+// example, ValidateGRPCServing verifies the status returned by the gRPC
+// health service.
+func ValidateResponse(validate func(body []byte) error) ProcedureOption {
+	return func(o *procedureOptions) {
+		o.validate = validate
+	}
+}
+
+// ValidateGRPCServing fails health checks unless they return a
+// grpc.health.v1.HealthCheckResponse with the SERVING status. Use it with
+// the "proto" encoding to call the Check method of the gRPC health service.
+func ValidateGRPCServing() ProcedureOption {
+	return ValidateResponse(validateGRPCServing)
+}
+
+func validateGRPCServing(body []byte) error {
+	var res hpb.HealthCheckResponse
+	if err := proto.Unmarshal(body, &res); err != nil {
+		return yarpcerrors.Newf(yarpcerrors.CodeInternal,
+			"failed to decode gRPC health check response: %v", err)
+	}
+	if res.Status != hpb.HealthCheckResponse_SERVING {
+		return yarpcerrors.Newf(yarpcerrors.CodeUnavailable,
+			"gRPC health check returned status %v", res.Status)
+	}
+	return nil
+}
+
+// Procedure returns a Check that calls a procedure on each peer, failing if
+// the call fails. Calls are made through outbounds built by newOutbound with
+// a chooser of the peer on the given transport, which should be the
+// transport wrapped by the health checking Transport.
+//
+// For example, with the standard gRPC health service,
+//
+// 	check := healthcheck.Procedure(grpcTransport, func(c peer.Chooser) transport.UnaryOutbound {
+// 		return grpcTransport.NewOutbound(c)
+// 	}, "myservice", "grpc.health.v1.Health::Check",
+// 		healthcheck.Encoding("proto"),
+// 		healthcheck.ValidateGRPCServing(),
+// 	)
+func Procedure(
+	t peer.Transport,
+	newOutbound func(peer.Chooser) transport.UnaryOutbound,
+	service, procedure string,
+	opts ...ProcedureOption,
+) Check {
+	options := defaultProcedureOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(ctx context.Context, pid peer.Identifier) error {
+		out := newOutbound(yarpcpeer.NewSingle(pid, t))
+		if err := out.Start(); err != nil {
+			return err
+		}
+		defer out.Stop()
+
+		res, err := out.Call(ctx, &transport.Request{
+			Caller:    options.caller,
+			Service:   service,
+			Procedure: procedure,
+			Encoding:  options.encoding,
+			Body:      bytes.NewReader(options.body),
+		})
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.ApplicationError {
+			return yarpcerrors.Newf(yarpcerrors.CodeUnknown,
+				"health check %q of %q returned an application error", procedure, service)
+		}
+		if options.validate == nil {
+			return nil
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return options.validate(body)
+	}
+}
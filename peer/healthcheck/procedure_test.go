@@ -0,0 +1,180 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpctest"
+	hpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	body, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return body
+}
+
+func TestProcedure(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []ProcedureOption
+		res      *transport.Response
+		err      error
+		wantReq  transport.Request
+		wantBody string
+		wantErr  string
+	}{
+		{
+			desc: "defaults",
+			res:  &transport.Response{Body: ioutil.NopCloser(&bytes.Buffer{})},
+			wantReq: transport.Request{
+				Caller:    "yarpc-health-check",
+				Service:   "myservice",
+				Procedure: "health",
+				Encoding:  "raw",
+			},
+		},
+		{
+			desc: "options",
+			opts: []ProcedureOption{
+				Caller("me"),
+				Encoding("json"),
+				Body([]byte("{}")),
+				ValidateResponse(func(body []byte) error {
+					if string(body) != `{"ok":true}` {
+						return errors.New("not ok")
+					}
+					return nil
+				}),
+			},
+			res: &transport.Response{Body: ioutil.NopCloser(bytes.NewBufferString(`{"ok":true}`))},
+			wantReq: transport.Request{
+				Caller:    "me",
+				Service:   "myservice",
+				Procedure: "health",
+				Encoding:  "json",
+			},
+			wantBody: "{}",
+		},
+		{
+			desc: "invalid response",
+			opts: []ProcedureOption{
+				ValidateResponse(func([]byte) error { return errors.New("not ok") }),
+			},
+			res:     &transport.Response{Body: ioutil.NopCloser(&bytes.Buffer{})},
+			wantErr: "not ok",
+		},
+		{
+			desc: "gRPC serving",
+			opts: []ProcedureOption{ValidateGRPCServing()},
+			res: &transport.Response{Body: ioutil.NopCloser(bytes.NewReader(
+				mustMarshal(t, &hpb.HealthCheckResponse{Status: hpb.HealthCheckResponse_SERVING}),
+			))},
+			wantReq: transport.Request{
+				Caller:    "yarpc-health-check",
+				Service:   "myservice",
+				Procedure: "health",
+				Encoding:  "raw",
+			},
+		},
+		{
+			desc: "gRPC not serving",
+			opts: []ProcedureOption{ValidateGRPCServing()},
+			res: &transport.Response{Body: ioutil.NopCloser(bytes.NewReader(
+				mustMarshal(t, &hpb.HealthCheckResponse{Status: hpb.HealthCheckResponse_NOT_SERVING}),
+			))},
+			wantErr: "gRPC health check returned status NOT_SERVING",
+		},
+		{
+			desc:    "gRPC invalid response",
+			opts:    []ProcedureOption{ValidateGRPCServing()},
+			res:     &transport.Response{Body: ioutil.NopCloser(bytes.NewBufferString("\xff"))},
+			wantErr: "failed to decode gRPC health check response",
+		},
+		{
+			desc: "application error",
+			res: &transport.Response{
+				Body:             ioutil.NopCloser(&bytes.Buffer{}),
+				ApplicationError: true,
+			},
+			wantErr: `health check "health" of "myservice" returned an application error`,
+		},
+		{
+			desc:    "call error",
+			err:     errors.New("great sadness"),
+			wantErr: "great sadness",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			trans := yarpctest.NewFakeTransport()
+
+			var (
+				gotPeer string
+				gotReq  *transport.Request
+				gotBody []byte
+			)
+			newOutbound := func(c peer.Chooser) transport.UnaryOutbound {
+				return trans.NewOutbound(c, yarpctest.OutboundCallOverride(
+					func(ctx context.Context, req *transport.Request) (*transport.Response, error) {
+						p, onFinish, err := c.Choose(ctx, req)
+						require.NoError(t, err)
+						defer onFinish(nil)
+
+						gotPeer = p.Identifier()
+						gotReq = req
+						gotBody, err = ioutil.ReadAll(req.Body)
+						require.NoError(t, err)
+						return tt.res, tt.err
+					}))
+			}
+
+			check := Procedure(trans, newOutbound, "myservice", "health", tt.opts...)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err := check(ctx, hostport.PeerIdentifier("1.1.1.1:80"))
+			assert.Equal(t, "1.1.1.1:80", gotPeer)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			gotReq.Body = nil
+			assert.Equal(t, tt.wantReq, *gotReq)
+			assert.Equal(t, tt.wantBody, string(gotBody))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/zap"
+)
+
+// Check checks the health of a peer, returning an error if it is unhealthy.
+type Check func(ctx context.Context, pid peer.Identifier) error
+
+type transportOptions struct {
+	interval           time.Duration
+	timeout            time.Duration
+	unhealthyThreshold int
+	healthyThreshold   int
+	logger             *zap.Logger
+}
+
+var defaultTransportOptions = transportOptions{
+	interval:           5 * time.Second,
+	timeout:            time.Second,
+	unhealthyThreshold: 3,
+	healthyThreshold:   2,
+}
+
+// TransportOption customizes the behavior of a health checking transport.
+type TransportOption func(*transportOptions)
+
+// Interval specifies how often each peer is checked.
+//
+// Defaults to 5 seconds.
+func Interval(d time.Duration) TransportOption {
+	return func(o *transportOptions) {
+		o.interval = d
+	}
+}
+
+// Timeout specifies how long a check may take before it fails.
+//
+// Defaults to 1 second.
+func Timeout(d time.Duration) TransportOption {
+	return func(o *transportOptions) {
+		o.timeout = d
+	}
+}
+
+// UnhealthyThreshold specifies how many consecutive checks a healthy peer
+// must fail to become unavailable.
+//
+// Defaults to 3.
+func UnhealthyThreshold(n int) TransportOption {
+	return func(o *transportOptions) {
+		o.unhealthyThreshold = n
+	}
+}
+
+// HealthyThreshold specifies how many consecutive checks an unhealthy peer
+// must pass to become available again.
+//
+// Defaults to 2.
+func HealthyThreshold(n int) TransportOption {
+	return func(o *transportOptions) {
+		o.healthyThreshold = n
+	}
+}
+
+// Logger specifies a logger for changes in peer health.
+func Logger(logger *zap.Logger) TransportOption {
+	return func(o *transportOptions) {
+		o.logger = logger
+	}
+}
+
+// NewTransport wraps a peer transport, checking the health of each peer
+// while it is retained. Peers start out healthy, and are checked as soon as
+// they are first retained and then at every interval.
+func NewTransport(t peer.Transport, check Check, opts ...TransportOption) *Transport {
+	options := defaultTransportOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		options.logger = zap.NewNop()
+	}
+
+	return &Transport{
+		transport: t,
+		check:     check,
+		options:   options,
+		peers:     make(map[string]*checkedPeer),
+	}
+}
+
+// Transport is a peer transport whose peers are unavailable while they fail
+// health checks.
+type Transport struct {
+	transport peer.Transport
+	check     Check
+	options   transportOptions
+
+	lock  sync.Mutex
+	peers map[string]*checkedPeer
+}
+
+var _ peer.Transport = (*Transport)(nil)
+
+// RetainPeer retains the peer from the underlying transport, starting to
+// check its health if it was not already retained.
+func (t *Transport) RetainPeer(pid peer.Identifier, sub peer.Subscriber) (peer.Peer, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peers[pid.Identifier()]
+	if p == nil {
+		p = newCheckedPeer(pid, t)
+		inner, err := t.transport.RetainPeer(pid, p)
+		if err != nil {
+			return nil, err
+		}
+		p.setPeer(inner)
+		t.peers[pid.Identifier()] = p
+		go p.checkLoop()
+	}
+
+	p.subscribe(sub)
+	return p, nil
+}
+
+// ReleasePeer releases the peer, releasing it from the underlying transport
+// and no longer checking its health if no other subscribers retain it.
+func (t *Transport) ReleasePeer(pid peer.Identifier, sub peer.Subscriber) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peers[pid.Identifier()]
+	if p == nil {
+		return peer.ErrTransportHasNoReferenceToPeer{
+			TransportName:  "healthcheck.Transport",
+			PeerIdentifier: pid.Identifier(),
+		}
+	}
+
+	remaining, err := p.unsubscribe(sub)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return nil
+	}
+
+	delete(t.peers, pid.Identifier())
+	p.stop()
+	return t.transport.ReleasePeer(pid, p)
+}
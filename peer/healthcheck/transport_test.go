@@ -0,0 +1,232 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+// scriptedCheck is a health check whose results are provided by the test.
+type scriptedCheck struct {
+	calls   chan string
+	results chan error
+}
+
+func newScriptedCheck() *scriptedCheck {
+	return &scriptedCheck{
+		calls:   make(chan string),
+		results: make(chan error),
+	}
+}
+
+func (s *scriptedCheck) Check(ctx context.Context, pid peer.Identifier) error {
+	select {
+	case s.calls <- pid.Identifier():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-s.results:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// respond responds to the pending check and waits for the next one, by which
+// time the result has been recorded.
+func (s *scriptedCheck) respond(t *testing.T, err error) {
+	select {
+	case s.results <- err:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for check")
+	}
+	s.wait(t)
+}
+
+func (s *scriptedCheck) wait(t *testing.T) {
+	select {
+	case <-s.calls:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for check")
+	}
+}
+
+type countingSubscriber struct {
+	lock  sync.Mutex
+	count int
+}
+
+func (s *countingSubscriber) NotifyStatusChanged(peer.Identifier) {
+	s.lock.Lock()
+	s.count++
+	s.lock.Unlock()
+}
+
+func (s *countingSubscriber) Count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.count
+}
+
+func TestHealthTransitions(t *testing.T) {
+	check := newScriptedCheck()
+	trans := NewTransport(yarpctest.NewFakeTransport(), check.Check,
+		Interval(time.Millisecond),
+		UnhealthyThreshold(3),
+		HealthyThreshold(2),
+	)
+
+	pid := hostport.PeerIdentifier("1.1.1.1:80")
+	sub := &countingSubscriber{}
+	p, err := trans.RetainPeer(pid, sub)
+	require.NoError(t, err)
+	defer trans.ReleasePeer(pid, sub)
+	assert.Equal(t, "1.1.1.1:80", p.Identifier())
+
+	check.wait(t)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus, "peers must start out healthy")
+
+	failure := errors.New("great sadness")
+	check.respond(t, failure)
+	check.respond(t, failure)
+	check.respond(t, nil)
+	check.respond(t, failure)
+	check.respond(t, failure)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus,
+		"failures must be consecutive")
+	assert.Equal(t, 0, sub.Count())
+
+	check.respond(t, failure)
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus)
+	assert.Equal(t, 1, sub.Count(), "subscribers must be notified")
+
+	check.respond(t, nil)
+	check.respond(t, failure)
+	check.respond(t, nil)
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus,
+		"successes must be consecutive")
+
+	check.respond(t, nil)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus)
+	assert.Equal(t, 2, sub.Count(), "subscribers must be notified")
+}
+
+func TestUnhealthyDisconnectedPeer(t *testing.T) {
+	check := newScriptedCheck()
+	inner := yarpctest.NewFakeTransport()
+	trans := NewTransport(inner, check.Check, Interval(time.Millisecond), UnhealthyThreshold(1))
+
+	pid := hostport.PeerIdentifier("1.1.1.1:80")
+	sub := &countingSubscriber{}
+	p, err := trans.RetainPeer(pid, sub)
+	require.NoError(t, err)
+	defer trans.ReleasePeer(pid, sub)
+
+	check.wait(t)
+	check.respond(t, errors.New("great sadness"))
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus)
+
+	inner.SimulateDisconnect(pid)
+	assert.Equal(t, 2, sub.Count(), "status changes of the underlying peer must be forwarded")
+	inner.SimulateConnect(pid)
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus,
+		"connected peers must remain unavailable while unhealthy")
+}
+
+func TestRetainRelease(t *testing.T) {
+	check := newScriptedCheck()
+	inner := yarpctest.NewFakeTransport()
+	trans := NewTransport(inner, check.Check, Interval(time.Millisecond))
+
+	pid := hostport.PeerIdentifier("1.1.1.1:80")
+	sub1, sub2 := &countingSubscriber{}, &countingSubscriber{}
+
+	p1, err := trans.RetainPeer(pid, sub1)
+	require.NoError(t, err)
+	p2, err := trans.RetainPeer(pid, sub2)
+	require.NoError(t, err)
+	assert.True(t, p1 == p2, "subscribers must share the peer")
+	check.wait(t)
+
+	assert.Error(t, trans.ReleasePeer(pid, &countingSubscriber{}))
+	assert.Error(t, trans.ReleasePeer(hostport.PeerIdentifier("2.2.2.2:80"), sub1))
+
+	require.NoError(t, trans.ReleasePeer(pid, sub1))
+	check.respond(t, nil)
+
+	require.NoError(t, trans.ReleasePeer(pid, sub2))
+	check.results <- nil
+	select {
+	case <-check.calls:
+		t.Fatal("released peers must not be checked")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.Error(t, inner.ReleasePeer(pid, p1.(peer.Subscriber)),
+		"the peer must be released from the underlying transport")
+}
+
+func TestPeerList(t *testing.T) {
+	healthy := map[string]bool{"1.1.1.1:80": true, "2.2.2.2:80": false}
+	check := func(ctx context.Context, pid peer.Identifier) error {
+		if healthy[pid.Identifier()] {
+			return nil
+		}
+		return errors.New("great sadness")
+	}
+
+	trans := NewTransport(yarpctest.NewFakeTransport(), check,
+		Interval(time.Millisecond), UnhealthyThreshold(1))
+	list := roundrobin.New(trans)
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			hostport.PeerIdentifier("1.1.1.1:80"),
+			hostport.PeerIdentifier("2.2.2.2:80"),
+		},
+	}))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	for deadline := time.Now().Add(time.Second); list.NumAvailable() != 1; {
+		require.True(t, time.Now().Before(deadline), "timed out waiting for unhealthy peer")
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		p, onFinish, err := list.Choose(ctx, &transport.Request{})
+		cancel()
+		require.NoError(t, err)
+		onFinish(nil)
+		assert.Equal(t, "1.1.1.1:80", p.Identifier())
+	}
+}
@@ -21,6 +21,9 @@
 package hostport
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 
 	"go.uber.org/atomic"
@@ -40,6 +43,120 @@ func Identify(peer string) peer.Identifier {
 	return PeerIdentifier(peer)
 }
 
+// PeerAttributes are optional attributes of a host:port combination that peer
+// lists may use to select peers.
+type PeerAttributes struct {
+	// Weight of the peer relative to other peers. Zero means the default
+	// weight.
+	Weight int
+
+	// Zone of the peer, or an empty string if unknown.
+	Zone string
+}
+
+// AttributedPeerIdentifier is a PeerIdentifier annotated with PeerAttributes.
+// It implements peer.WeightedIdentifier and peer.ZonedIdentifier.
+type AttributedPeerIdentifier struct {
+	PeerIdentifier
+
+	Attributes PeerAttributes
+}
+
+var (
+	_ peer.WeightedIdentifier = AttributedPeerIdentifier{}
+	_ peer.ZonedIdentifier    = AttributedPeerIdentifier{}
+)
+
+// Weight returns the weight of the peer.
+func (p AttributedPeerIdentifier) Weight() int {
+	return p.Attributes.Weight
+}
+
+// Zone returns the zone of the peer.
+func (p AttributedPeerIdentifier) Zone() string {
+	return p.Attributes.Zone
+}
+
+// IdentifyWithAttributes coerces a string and attributes to a
+// peer.Identifier. If the attributes are empty, the result is a plain
+// PeerIdentifier.
+func IdentifyWithAttributes(peer string, attrs PeerAttributes) peer.Identifier {
+	return WithAttributes(PeerIdentifier(peer), attrs)
+}
+
+// AttributedIdentifier is a peer.Identifier of any transport annotated with
+// PeerAttributes. It implements peer.WeightedIdentifier and
+// peer.ZonedIdentifier.
+type AttributedIdentifier struct {
+	// Peer is the annotated identifier.
+	Peer peer.Identifier
+
+	Attributes PeerAttributes
+}
+
+var (
+	_ peer.WeightedIdentifier = AttributedIdentifier{}
+	_ peer.ZonedIdentifier    = AttributedIdentifier{}
+)
+
+// Identifier returns the identifier of the annotated peer.
+func (p AttributedIdentifier) Identifier() string {
+	return p.Peer.Identifier()
+}
+
+// Weight returns the weight of the peer.
+func (p AttributedIdentifier) Weight() int {
+	return p.Attributes.Weight
+}
+
+// Zone returns the zone of the peer.
+func (p AttributedIdentifier) Zone() string {
+	return p.Attributes.Zone
+}
+
+// WithAttributes annotates the given peer.Identifier with attributes. A
+// PeerIdentifier becomes an AttributedPeerIdentifier, and identifiers of
+// other types are wrapped in an AttributedIdentifier. If the attributes are
+// empty, the identifier is returned as is.
+func WithAttributes(pid peer.Identifier, attrs PeerAttributes) peer.Identifier {
+	if attrs == (PeerAttributes{}) {
+		return pid
+	}
+	if hp, ok := pid.(PeerIdentifier); ok {
+		return AttributedPeerIdentifier{PeerIdentifier: hp, Attributes: attrs}
+	}
+	return AttributedIdentifier{Peer: pid, Attributes: attrs}
+}
+
+// ParseAttributes splits a host:port string with optional attributes, like
+// "127.0.0.1:8080;weight=3;zone=us-east-1a", into the host:port and its
+// attributes. The supported attributes are "weight", a positive integer, and
+// "zone".
+func ParseAttributes(s string) (string, PeerAttributes, error) {
+	var attrs PeerAttributes
+	parts := strings.Split(s, ";")
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return "", attrs, fmt.Errorf("invalid peer attribute %q in %q: expected key=value", part, s)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "weight":
+			weight, err := strconv.Atoi(value)
+			if err != nil || weight <= 0 {
+				return "", attrs, fmt.Errorf("invalid weight %q in %q: expected a positive integer", value, s)
+			}
+			attrs.Weight = weight
+		case "zone":
+			attrs.Zone = value
+		default:
+			return "", attrs, fmt.Errorf("unknown peer attribute %q in %q", key, s)
+		}
+	}
+	return strings.TrimSpace(parts[0]), attrs, nil
+}
+
 // NewPeer creates a new hostport.Peer from a hostport.PeerIdentifier, peer.Transport, and peer.Subscriber
 func NewPeer(pid PeerIdentifier, transport peer.Transport) *Peer {
 	p := &Peer{
@@ -52,6 +52,84 @@ func TestPeerIdentifier(t *testing.T) {
 	}
 }
 
+func TestIdentifyWithAttributes(t *testing.T) {
+	pid := IdentifyWithAttributes("localhost:12345", PeerAttributes{})
+	assert.Equal(t, PeerIdentifier("localhost:12345"), pid)
+
+	pid = IdentifyWithAttributes("localhost:12345", PeerAttributes{Weight: 3, Zone: "west"})
+	assert.Equal(t, "localhost:12345", pid.Identifier())
+	assert.Equal(t, 3, pid.(peer.WeightedIdentifier).Weight())
+	assert.Equal(t, "west", pid.(peer.ZonedIdentifier).Zone())
+}
+
+type customIdentifier struct{ addr string }
+
+func (c customIdentifier) Identifier() string { return c.addr }
+
+func TestWithAttributes(t *testing.T) {
+	custom := customIdentifier{addr: "localhost:12345"}
+	assert.Equal(t, custom, WithAttributes(custom, PeerAttributes{}))
+
+	pid := WithAttributes(custom, PeerAttributes{Weight: 3, Zone: "west"})
+	assert.Equal(t, "localhost:12345", pid.Identifier())
+	assert.Equal(t, 3, pid.(peer.WeightedIdentifier).Weight())
+	assert.Equal(t, "west", pid.(peer.ZonedIdentifier).Zone())
+	assert.Equal(t, custom, pid.(AttributedIdentifier).Peer, "the identifier must keep its type")
+
+	pid = WithAttributes(PeerIdentifier("localhost:12345"), PeerAttributes{Weight: 3})
+	assert.Equal(t, IdentifyWithAttributes("localhost:12345", PeerAttributes{Weight: 3}), pid)
+}
+
+func TestParseAttributes(t *testing.T) {
+	tests := []struct {
+		give      string
+		wantHost  string
+		wantAttrs PeerAttributes
+		wantErr   string
+	}{
+		{give: "localhost:12345", wantHost: "localhost:12345"},
+		{
+			give:      "localhost:12345;weight=3",
+			wantHost:  "localhost:12345",
+			wantAttrs: PeerAttributes{Weight: 3},
+		},
+		{
+			give:      "localhost:12345; zone=west ;weight=2",
+			wantHost:  "localhost:12345",
+			wantAttrs: PeerAttributes{Weight: 2, Zone: "west"},
+		},
+		{
+			give:    "localhost:12345;weight",
+			wantErr: `invalid peer attribute "weight" in "localhost:12345;weight": expected key=value`,
+		},
+		{
+			give:    "localhost:12345;weight=0",
+			wantErr: `invalid weight "0" in "localhost:12345;weight=0": expected a positive integer`,
+		},
+		{
+			give:    "localhost:12345;weight=heavy",
+			wantErr: `invalid weight "heavy" in "localhost:12345;weight=heavy": expected a positive integer`,
+		},
+		{
+			give:    "localhost:12345;color=red",
+			wantErr: `unknown peer attribute "color" in "localhost:12345;color=red"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			host, attrs, err := ParseAttributes(tt.give)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHost, host)
+			assert.Equal(t, tt.wantAttrs, attrs)
+		})
+	}
+}
+
 func TestPeer(t *testing.T) {
 	type testStruct struct {
 		msg string
@@ -0,0 +1,54 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package outlier ejects peers that fail too many requests from the
+// available peers of peer lists for a while.
+//
+// A peer that accepts requests but fails most of them, for example with
+// CodeInternal or CodeUnavailable errors, stays connected and so stays
+// available to peer lists. The Transport in this package wraps a peer
+// transport and tracks the outcome of the requests sent to each peer it
+// retains. A peer that fails too many consecutive requests, or too high a
+// proportion of requests, is ejected: it reports itself unavailable, so peer
+// lists stop choosing it, until its ejection expires. Each time a peer is
+// ejected again its ejection lasts twice as long, up to a maximum. To avoid
+// ejecting too many peers at once, no more peers are ejected while the
+// maximum percentage of peers is ejected.
+//
+// Outcomes are reported by peer lists built on peer/peerlist/v2, which
+// includes all of the peer lists in this repository.
+//
+// 	list := roundrobin.New(outlier.NewTransport(httpTransport,
+// 		outlier.ConsecutiveErrors(5),
+// 		outlier.Logger(logger),
+// 	))
+//
+// With the HonorRetryAfter option, a peer that rejects a request with
+// CodeResourceExhausted or CodeUnavailable and a retry hint is also ejected,
+// for as long as the hint asks.
+//
+// Ejected peers are logged, counted in metrics if a metrics scope is
+// provided, and reported as ejected in the peer list introspection shown by
+// x/debug.
+//
+// When combined with active health checking, the outlier detection
+// transport must wrap the health checking transport, not the other way
+// around.
+package outlier
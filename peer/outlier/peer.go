@@ -0,0 +1,286 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package outlier
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/internal/introspection"
+	"go.uber.org/yarpc/peer/peerlist/v2"
+	"go.uber.org/zap"
+)
+
+// trackedPeer is a peer of the underlying transport that tracks the outcome
+// of its requests and reports itself unavailable while it is ejected. It
+// subscribes to the underlying peer on behalf of its own subscribers.
+//
+// Fields following the subscribers are guarded by the transport lock.
+type trackedPeer struct {
+	peer.Peer
+
+	pid       peer.Identifier
+	transport *Transport
+
+	// Notifications from the underlying peer may arrive while the transport
+	// lock is held, so subscribers have their own lock.
+	subscribersLock sync.Mutex
+	subscribers     map[peer.Subscriber]struct{}
+
+	consecutiveFailures int
+	requests            int
+	failures            int
+	windowStart         time.Time
+
+	ejected      bool
+	ejectedUntil time.Time
+	reason       string
+	timer        clock.Timer
+	// Number of recent ejections, doubling the time of the next one.
+	ejections  int
+	restoredAt time.Time
+}
+
+var (
+	_ peerlist.OutcomeObserver         = (*trackedPeer)(nil)
+	_ peer.Subscriber                  = (*trackedPeer)(nil)
+	_ introspection.IntrospectablePeer = (*trackedPeer)(nil)
+)
+
+func newTrackedPeer(pid peer.Identifier, t *Transport) *trackedPeer {
+	return &trackedPeer{
+		pid:         pid,
+		transport:   t,
+		subscribers: make(map[peer.Subscriber]struct{}),
+		windowStart: t.options.clock.Now(),
+	}
+}
+
+// Status returns the status of the underlying peer, except that a connected
+// peer is unavailable while it is ejected.
+func (p *trackedPeer) Status() peer.Status {
+	p.transport.lock.Lock()
+	ejected := p.ejected
+	p.transport.lock.Unlock()
+
+	status := p.Peer.Status()
+	if ejected && status.ConnectionStatus == peer.Available {
+		status.ConnectionStatus = peer.Unavailable
+	}
+	return status
+}
+
+// Introspect describes the status of the peer, and why and until when it is
+// ejected if it is.
+func (p *trackedPeer) Introspect() introspection.PeerStatus {
+	p.transport.lock.Lock()
+	ejected, until, reason := p.ejected, p.ejectedUntil, p.reason
+	p.transport.lock.Unlock()
+
+	status := p.Status()
+	state := fmt.Sprintf("%s, %d pending request(s)",
+		status.ConnectionStatus.String(), status.PendingRequestCount)
+	if ejected {
+		state = fmt.Sprintf("%s, ejected until %s after %s",
+			state, until.Format(time.RFC3339), reason)
+	}
+	return introspection.PeerStatus{
+		Identifier: p.Identifier(),
+		State:      state,
+	}
+}
+
+// NotifyStatusChanged forwards status changes of the underlying peer to
+// subscribers.
+func (p *trackedPeer) NotifyStatusChanged(peer.Identifier) {
+	p.notify()
+}
+
+func (p *trackedPeer) subscribe(sub peer.Subscriber) {
+	p.subscribersLock.Lock()
+	p.subscribers[sub] = struct{}{}
+	p.subscribersLock.Unlock()
+}
+
+// unsubscribe removes the subscriber, returning the number of remaining
+// subscribers.
+func (p *trackedPeer) unsubscribe(sub peer.Subscriber) (int, error) {
+	p.subscribersLock.Lock()
+	defer p.subscribersLock.Unlock()
+
+	if _, ok := p.subscribers[sub]; !ok {
+		return 0, peer.ErrPeerHasNoReferenceToSubscriber{
+			PeerIdentifier: p.pid,
+			PeerSubscriber: sub,
+		}
+	}
+	delete(p.subscribers, sub)
+	return len(p.subscribers), nil
+}
+
+func (p *trackedPeer) notify() {
+	p.subscribersLock.Lock()
+	subscribers := make([]peer.Subscriber, 0, len(p.subscribers))
+	for sub := range p.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	p.subscribersLock.Unlock()
+
+	for _, sub := range subscribers {
+		sub.NotifyStatusChanged(p.pid)
+	}
+}
+
+// ObserveOutcome records the outcome of a request, ejecting the peer if it
+// has failed too many requests or, if retry hints are honored, asked to be
+// retried later.
+func (p *trackedPeer) ObserveOutcome(err error) {
+	t := p.transport
+	options := t.options
+	failed := t.isFailure(err)
+	retryAfter := t.retryAfter(err)
+
+	t.lock.Lock()
+	if t.peers[p.pid.Identifier()] != p {
+		// The peer was released.
+		t.lock.Unlock()
+		return
+	}
+
+	now := options.clock.Now()
+	if now.Sub(p.windowStart) >= options.interval {
+		p.requests, p.failures = 0, 0
+		p.windowStart = now
+	}
+	p.requests++
+	if failed {
+		p.failures++
+		p.consecutiveFailures++
+	} else {
+		p.consecutiveFailures = 0
+	}
+
+	if (!failed && retryAfter == 0) || p.ejected {
+		t.lock.Unlock()
+		return
+	}
+
+	var reason string
+	// Outliers are ejected with backoff; peers asking to be retried later
+	// are ejected for as long as they asked.
+	backoff := true
+	switch {
+	case failed && options.consecutiveErrors > 0 && p.consecutiveFailures >= options.consecutiveErrors:
+		reason = fmt.Sprintf("%d consecutive errors", p.consecutiveFailures)
+	case failed && options.errorRate > 0 && p.requests >= options.minRequests &&
+		float64(p.failures) >= options.errorRate*float64(p.requests):
+		reason = fmt.Sprintf("%d errors in %d requests", p.failures, p.requests)
+	case retryAfter > 0:
+		reason = fmt.Sprintf("a request to retry after %v", retryAfter)
+		backoff = false
+	}
+	if reason == "" || !t.canEject() {
+		t.lock.Unlock()
+		return
+	}
+
+	var d time.Duration
+	if backoff {
+		d = p.eject(now, reason)
+	} else {
+		d = p.ejectFor(now, retryAfter, reason)
+	}
+	t.lock.Unlock()
+
+	t.ejections.Inc()
+	t.ejectedGauge.Inc()
+	options.logger.Warn("ejected peer as an outlier",
+		zap.String("peer", p.Identifier()),
+		zap.String("reason", reason),
+		zap.Duration("duration", d),
+		zap.Error(err))
+	p.notify()
+}
+
+// eject ejects the peer, returning for how long.
+// Must be called under the transport lock.
+func (p *trackedPeer) eject(now time.Time, reason string) time.Duration {
+	t := p.transport
+	options := t.options
+
+	if p.ejections > 0 && now.Sub(p.restoredAt) >= options.maxEjectionTime {
+		p.ejections = 0
+	}
+	d := options.baseEjectionTime
+	for i := 0; i < p.ejections && d < options.maxEjectionTime; i++ {
+		d *= 2
+	}
+	if d > options.maxEjectionTime {
+		d = options.maxEjectionTime
+	}
+	p.ejections++
+	return p.ejectFor(now, d, reason)
+}
+
+// ejectFor ejects the peer for the given duration, up to the maximum
+// ejection time, without affecting the duration of later ejections. Returns
+// for how long the peer is ejected.
+// Must be called under the transport lock.
+func (p *trackedPeer) ejectFor(now time.Time, d time.Duration, reason string) time.Duration {
+	t := p.transport
+	options := t.options
+
+	if d > options.maxEjectionTime {
+		d = options.maxEjectionTime
+	}
+	p.ejected = true
+	p.ejectedUntil = now.Add(d)
+	p.reason = reason
+	p.consecutiveFailures, p.requests, p.failures = 0, 0, 0
+	t.ejected++
+	p.timer = options.clock.AfterFunc(d, p.restore)
+	return d
+}
+
+// restore returns the peer to service when its ejection expires.
+func (p *trackedPeer) restore() {
+	t := p.transport
+
+	t.lock.Lock()
+	if !p.ejected || t.peers[p.pid.Identifier()] != p {
+		t.lock.Unlock()
+		return
+	}
+	now := t.options.clock.Now()
+	p.ejected = false
+	p.restoredAt = now
+	p.windowStart = now
+	t.ejected--
+	t.lock.Unlock()
+
+	t.ejectedGauge.Dec()
+	t.options.logger.Info("restored ejected peer",
+		zap.String("peer", p.Identifier()))
+	p.notify()
+}
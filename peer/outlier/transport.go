@@ -0,0 +1,318 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package outlier
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/zap"
+)
+
+type transportOptions struct {
+	consecutiveErrors  int
+	errorRate          float64
+	minRequests        int
+	interval           time.Duration
+	baseEjectionTime   time.Duration
+	maxEjectionTime    time.Duration
+	maxEjectionPercent int
+	errorCodes         map[yarpcerrors.Code]struct{}
+	honorRetryAfter    bool
+	logger             *zap.Logger
+	meter              *metrics.Scope
+	clock              clock.Clock
+}
+
+var defaultTransportOptions = transportOptions{
+	consecutiveErrors:  5,
+	minRequests:        20,
+	interval:           10 * time.Second,
+	baseEjectionTime:   30 * time.Second,
+	maxEjectionTime:    5 * time.Minute,
+	maxEjectionPercent: 10,
+}
+
+// _defaultErrorCodes are the codes of errors that count as failures by
+// default: errors that indicate a problem with the peer rather than the
+// request.
+var _defaultErrorCodes = []yarpcerrors.Code{
+	yarpcerrors.CodeUnknown,
+	yarpcerrors.CodeDeadlineExceeded,
+	yarpcerrors.CodeInternal,
+	yarpcerrors.CodeUnavailable,
+	yarpcerrors.CodeDataLoss,
+}
+
+// TransportOption customizes the behavior of an outlier detecting transport.
+type TransportOption func(*transportOptions)
+
+// ConsecutiveErrors specifies how many consecutive requests a peer must fail
+// to be ejected. Zero disables ejection for consecutive errors.
+//
+// Defaults to 5.
+func ConsecutiveErrors(n int) TransportOption {
+	return func(o *transportOptions) {
+		o.consecutiveErrors = n
+	}
+}
+
+// ErrorRate ejects peers that fail at least the given fraction, between 0 and
+// 1, of at least minRequests requests within an interval.
+//
+// Peers are not ejected for their error rate by default.
+func ErrorRate(rate float64, minRequests int) TransportOption {
+	return func(o *transportOptions) {
+		o.errorRate = rate
+		o.minRequests = minRequests
+	}
+}
+
+// Interval specifies the period over which the error rate of peers is
+// measured.
+//
+// Defaults to 10 seconds.
+func Interval(d time.Duration) TransportOption {
+	return func(o *transportOptions) {
+		o.interval = d
+	}
+}
+
+// BaseEjectionTime specifies how long a peer is ejected the first time. Each
+// time it is ejected again, its ejection lasts twice as long as the last.
+// A peer that goes the maximum ejection time without being ejected starts
+// over at the base ejection time.
+//
+// Defaults to 30 seconds.
+func BaseEjectionTime(d time.Duration) TransportOption {
+	return func(o *transportOptions) {
+		o.baseEjectionTime = d
+	}
+}
+
+// MaxEjectionTime specifies the maximum time for which a peer is ejected.
+//
+// Defaults to 5 minutes.
+func MaxEjectionTime(d time.Duration) TransportOption {
+	return func(o *transportOptions) {
+		o.maxEjectionTime = d
+	}
+}
+
+// MaxEjectionPercent specifies the percentage of peers above which no more
+// peers are ejected. At least one peer may always be ejected.
+//
+// Defaults to 10.
+func MaxEjectionPercent(percent int) TransportOption {
+	return func(o *transportOptions) {
+		o.maxEjectionPercent = percent
+	}
+}
+
+// ErrorCodes specifies the codes of errors that count as failed requests.
+//
+// Defaults to CodeUnknown, CodeDeadlineExceeded, CodeInternal,
+// CodeUnavailable and CodeDataLoss.
+func ErrorCodes(codes ...yarpcerrors.Code) TransportOption {
+	return func(o *transportOptions) {
+		o.errorCodes = make(map[yarpcerrors.Code]struct{}, len(codes))
+		for _, code := range codes {
+			o.errorCodes[code] = struct{}{}
+		}
+	}
+}
+
+// HonorRetryAfter ejects a peer that rejects a request with
+// CodeResourceExhausted or CodeUnavailable and a retry hint, set by the
+// server with yarpcerrors.Status.WithRetryAfter, until the hint expires.
+// These ejections last no longer than the maximum ejection time, are subject
+// to the maximum ejection percentage, and do not lengthen later ejections.
+//
+// Retry hints are ignored by default.
+func HonorRetryAfter() TransportOption {
+	return func(o *transportOptions) {
+		o.honorRetryAfter = true
+	}
+}
+
+// Logger specifies a logger for ejections.
+func Logger(logger *zap.Logger) TransportOption {
+	return func(o *transportOptions) {
+		o.logger = logger
+	}
+}
+
+// Meter specifies a metrics scope for the number of ejections and ejected
+// peers.
+func Meter(meter *metrics.Scope) TransportOption {
+	return func(o *transportOptions) {
+		o.meter = meter
+	}
+}
+
+func withClock(c clock.Clock) TransportOption {
+	return func(o *transportOptions) {
+		o.clock = c
+	}
+}
+
+// NewTransport wraps a peer transport, ejecting its peers while they fail
+// too many requests.
+func NewTransport(t peer.Transport, opts ...TransportOption) *Transport {
+	options := defaultTransportOptions
+	ErrorCodes(_defaultErrorCodes...)(&options)
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		options.logger = zap.NewNop()
+	}
+	if options.clock == nil {
+		options.clock = clock.NewReal()
+	}
+
+	ejections, err := options.meter.Counter(metrics.Spec{
+		Name: "outlier_ejections",
+		Help: "Number of times peers were ejected as outliers.",
+	})
+	if err != nil {
+		options.logger.Error("Failed to create outlier ejections counter.", zap.Error(err))
+	}
+	ejected, err := options.meter.Gauge(metrics.Spec{
+		Name: "outlier_ejected_peers",
+		Help: "Number of peers currently ejected as outliers.",
+	})
+	if err != nil {
+		options.logger.Error("Failed to create ejected peers gauge.", zap.Error(err))
+	}
+
+	return &Transport{
+		transport:    t,
+		options:      options,
+		peers:        make(map[string]*trackedPeer),
+		ejections:    ejections,
+		ejectedGauge: ejected,
+	}
+}
+
+// Transport is a peer transport whose peers are unavailable while they are
+// ejected as outliers.
+type Transport struct {
+	transport peer.Transport
+	options   transportOptions
+
+	ejections    *metrics.Counter
+	ejectedGauge *metrics.Gauge
+
+	// The lock guards the peers and the outcomes they track, since ejecting a
+	// peer depends on how many others are ejected.
+	lock    sync.Mutex
+	peers   map[string]*trackedPeer
+	ejected int
+}
+
+var _ peer.Transport = (*Transport)(nil)
+
+// RetainPeer retains the peer from the underlying transport, starting to
+// track the outcome of its requests if it was not already retained.
+func (t *Transport) RetainPeer(pid peer.Identifier, sub peer.Subscriber) (peer.Peer, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peers[pid.Identifier()]
+	if p == nil {
+		p = newTrackedPeer(pid, t)
+		inner, err := t.transport.RetainPeer(pid, p)
+		if err != nil {
+			return nil, err
+		}
+		p.Peer = inner
+		t.peers[pid.Identifier()] = p
+	}
+
+	p.subscribe(sub)
+	return p, nil
+}
+
+// ReleasePeer releases the peer, releasing it from the underlying transport
+// if no other subscribers retain it.
+func (t *Transport) ReleasePeer(pid peer.Identifier, sub peer.Subscriber) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peers[pid.Identifier()]
+	if p == nil {
+		return peer.ErrTransportHasNoReferenceToPeer{
+			TransportName:  "outlier.Transport",
+			PeerIdentifier: pid.Identifier(),
+		}
+	}
+	remaining, err := p.unsubscribe(sub)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return nil
+	}
+
+	delete(t.peers, pid.Identifier())
+	if p.ejected {
+		p.timer.Stop()
+		p.ejected = false
+		t.ejected--
+		t.ejectedGauge.Dec()
+	}
+	return t.transport.ReleasePeer(pid, p)
+}
+
+// isFailure returns whether an error counts as a failed request.
+func (t *Transport) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := t.options.errorCodes[yarpcerrors.FromError(err).Code()]
+	return ok
+}
+
+// retryAfter returns how long the peer asked to be left alone for, if the
+// error is a rejection with a retry hint that should be honored.
+func (t *Transport) retryAfter(err error) time.Duration {
+	if !t.options.honorRetryAfter || err == nil {
+		return 0
+	}
+	status := yarpcerrors.FromError(err)
+	switch status.Code() {
+	case yarpcerrors.CodeResourceExhausted, yarpcerrors.CodeUnavailable:
+		return status.RetryAfter()
+	default:
+		return 0
+	}
+}
+
+// canEject returns whether another peer may be ejected.
+// Must be called under the lock.
+func (t *Transport) canEject() bool {
+	return t.ejected*100 < t.options.maxEjectionPercent*len(t.peers) || t.ejected == 0
+}
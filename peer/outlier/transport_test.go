@@ -0,0 +1,347 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package outlier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+var (
+	errInternal = yarpcerrors.InternalErrorf("great sadness")
+	errCaller   = yarpcerrors.InvalidArgumentErrorf("bad request")
+)
+
+// notifyingSubscriber sends on a channel when its peer changes status.
+type notifyingSubscriber chan struct{}
+
+func newNotifyingSubscriber() notifyingSubscriber {
+	return make(chan struct{}, 10)
+}
+
+func (s notifyingSubscriber) NotifyStatusChanged(peer.Identifier) {
+	s <- struct{}{}
+}
+
+func (s notifyingSubscriber) wait(t *testing.T) {
+	select {
+	case <-s:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for status change")
+	}
+}
+
+func retain(t *testing.T, trans *Transport, id string) (*trackedPeer, notifyingSubscriber) {
+	sub := newNotifyingSubscriber()
+	p, err := trans.RetainPeer(hostport.PeerIdentifier(id), sub)
+	require.NoError(t, err)
+	return p.(*trackedPeer), sub
+}
+
+func observe(p *trackedPeer, errs ...error) {
+	for _, err := range errs {
+		p.ObserveOutcome(err)
+	}
+}
+
+func repeat(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func TestConsecutiveErrors(t *testing.T) {
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk), ConsecutiveErrors(3))
+	p, sub := retain(t, trans, "1.1.1.1:80")
+
+	observe(p, errInternal, errInternal, nil, errInternal, errInternal, errCaller)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus,
+		"successes and caller errors must reset the count")
+
+	observe(p, errInternal, errInternal, errors.New("unknown"))
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus)
+	sub.wait(t)
+	assert.Contains(t, p.Introspect().State, "ejected until 1970-01-01T00:00:30Z after 3 consecutive errors")
+
+	clk.Add(30 * time.Second)
+	sub.wait(t)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus)
+	assert.Equal(t, "Available, 0 pending request(s)", p.Introspect().State)
+}
+
+func TestErrorRate(t *testing.T) {
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk),
+		ConsecutiveErrors(0),
+		ErrorRate(0.5, 4),
+		Interval(time.Second),
+	)
+	p, sub := retain(t, trans, "1.1.1.1:80")
+
+	observe(p, errInternal, nil, errInternal)
+	clk.Add(time.Second)
+	observe(p, nil, nil, errInternal)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus,
+		"requests from past intervals must not count")
+
+	observe(p, errInternal)
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus)
+	sub.wait(t)
+	assert.Contains(t, p.Introspect().State, "after 2 errors in 4 requests")
+}
+
+func TestHonorRetryAfter(t *testing.T) {
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk),
+		ConsecutiveErrors(0),
+		MaxEjectionTime(time.Minute),
+		HonorRetryAfter(),
+	)
+	p, sub := retain(t, trans, "1.1.1.1:80")
+
+	observe(p,
+		yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down"),
+		yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, "bad request").WithRetryAfter(time.Second),
+	)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus,
+		"errors without hints and other codes must not eject")
+
+	observe(p, yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down").WithRetryAfter(2*time.Second))
+	assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus)
+	sub.wait(t)
+	assert.Contains(t, p.Introspect().State, "after a request to retry after 2s")
+
+	clk.Add(2 * time.Second)
+	sub.wait(t)
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus)
+
+	observe(p, yarpcerrors.Newf(yarpcerrors.CodeUnavailable, "go away").WithRetryAfter(time.Hour))
+	sub.wait(t)
+	assert.Equal(t, clk.Now().Add(time.Minute), p.ejectedUntil,
+		"ejection must be capped at the maximum ejection time")
+	assert.Equal(t, 0, p.ejections, "hints must not lengthen later ejections")
+}
+
+func TestIgnoreRetryAfter(t *testing.T) {
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clock.NewFake()), ConsecutiveErrors(0))
+	p, _ := retain(t, trans, "1.1.1.1:80")
+
+	observe(p, yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down").WithRetryAfter(time.Second))
+	assert.Equal(t, peer.Available, p.Status().ConnectionStatus)
+}
+
+func TestEjectionTime(t *testing.T) {
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk),
+		ConsecutiveErrors(1),
+		BaseEjectionTime(time.Second),
+		MaxEjectionTime(3*time.Second),
+	)
+	p, sub := retain(t, trans, "1.1.1.1:80")
+
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		observe(p, errInternal)
+		sub.wait(t)
+		assert.Equal(t, clk.Now().Add(want), p.ejectedUntil)
+
+		clk.Add(want - time.Millisecond)
+		assert.Equal(t, peer.Unavailable, p.Status().ConnectionStatus)
+		clk.Add(time.Millisecond)
+		sub.wait(t)
+		assert.Equal(t, peer.Available, p.Status().ConnectionStatus)
+	}
+
+	// Peers that go the maximum ejection time without being ejected start
+	// over.
+	clk.Add(3 * time.Second)
+	observe(p, errInternal)
+	sub.wait(t)
+	assert.Equal(t, clk.Now().Add(time.Second), p.ejectedUntil)
+}
+
+func TestMaxEjectionPercent(t *testing.T) {
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk),
+		ConsecutiveErrors(1),
+		MaxEjectionPercent(50),
+	)
+	p1, _ := retain(t, trans, "1.1.1.1:80")
+	p2, _ := retain(t, trans, "2.2.2.2:80")
+	p3, _ := retain(t, trans, "3.3.3.3:80")
+	p4, _ := retain(t, trans, "4.4.4.4:80")
+
+	observe(p1, errInternal)
+	observe(p2, errInternal)
+	observe(p3, errInternal)
+	observe(p4, errInternal)
+	assert.Equal(t, peer.Unavailable, p1.Status().ConnectionStatus)
+	assert.Equal(t, peer.Unavailable, p2.Status().ConnectionStatus)
+	assert.Equal(t, peer.Available, p3.Status().ConnectionStatus)
+	assert.Equal(t, peer.Available, p4.Status().ConnectionStatus)
+
+	// At least one peer may be ejected.
+	trans = NewTransport(yarpctest.NewFakeTransport(), withClock(clk),
+		ConsecutiveErrors(1),
+		MaxEjectionPercent(10),
+	)
+	p1, _ = retain(t, trans, "1.1.1.1:80")
+	p2, _ = retain(t, trans, "2.2.2.2:80")
+	observe(p1, errInternal)
+	observe(p2, errInternal)
+	assert.Equal(t, peer.Unavailable, p1.Status().ConnectionStatus)
+	assert.Equal(t, peer.Available, p2.Status().ConnectionStatus)
+}
+
+func TestRetainRelease(t *testing.T) {
+	clk := clock.NewFake()
+	inner := yarpctest.NewFakeTransport()
+	trans := NewTransport(inner, withClock(clk), ConsecutiveErrors(1))
+
+	pid := hostport.PeerIdentifier("1.1.1.1:80")
+	sub1, sub2 := newNotifyingSubscriber(), newNotifyingSubscriber()
+	p1, err := trans.RetainPeer(pid, sub1)
+	require.NoError(t, err)
+	p2, err := trans.RetainPeer(pid, sub2)
+	require.NoError(t, err)
+	assert.True(t, p1 == p2, "subscribers must share the peer")
+
+	inner.SimulateDisconnect(pid)
+	sub1.wait(t)
+	sub2.wait(t)
+
+	assert.Error(t, trans.ReleasePeer(pid, newNotifyingSubscriber()))
+	assert.Error(t, trans.ReleasePeer(hostport.PeerIdentifier("2.2.2.2:80"), sub1))
+
+	observe(p1.(*trackedPeer), errInternal)
+	assert.Equal(t, 1, trans.ejected)
+
+	require.NoError(t, trans.ReleasePeer(pid, sub1))
+	require.NoError(t, trans.ReleasePeer(pid, sub2))
+	assert.Equal(t, 0, trans.ejected)
+	assert.Empty(t, trans.peers)
+	assert.Error(t, inner.ReleasePeer(pid, p1.(peer.Subscriber)),
+		"the peer must be released from the underlying transport")
+
+	// Outcomes of requests to released peers are ignored.
+	observe(p1.(*trackedPeer), errInternal)
+	assert.Equal(t, 0, trans.ejected)
+}
+
+func TestMetrics(t *testing.T) {
+	root := metrics.New()
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk),
+		ConsecutiveErrors(1),
+		Meter(root.Scope()),
+	)
+	p, sub := retain(t, trans, "1.1.1.1:80")
+
+	snapshot := func() map[string]int64 {
+		values := make(map[string]int64)
+		s := root.Snapshot()
+		for _, c := range s.Counters {
+			values[c.Name] = c.Value
+		}
+		for _, g := range s.Gauges {
+			values[g.Name] = g.Value
+		}
+		return values
+	}
+
+	observe(p, errInternal)
+	sub.wait(t)
+	assert.Equal(t, map[string]int64{
+		"outlier_ejections":     1,
+		"outlier_ejected_peers": 1,
+	}, snapshot())
+
+	clk.Add(30 * time.Second)
+	sub.wait(t)
+	assert.Equal(t, map[string]int64{
+		"outlier_ejections":     1,
+		"outlier_ejected_peers": 0,
+	}, snapshot())
+}
+
+func TestPeerList(t *testing.T) {
+	clk := clock.NewFake()
+	trans := NewTransport(yarpctest.NewFakeTransport(), withClock(clk), ConsecutiveErrors(2))
+	list := roundrobin.New(trans)
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			hostport.PeerIdentifier("1.1.1.1:80"),
+			hostport.PeerIdentifier("2.2.2.2:80"),
+		},
+	}))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	call := func() string {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		p, onFinish, err := list.Choose(ctx, &transport.Request{})
+		require.NoError(t, err)
+		if p.Identifier() == "1.1.1.1:80" {
+			onFinish(errInternal)
+		} else {
+			onFinish(nil)
+		}
+		return p.Identifier()
+	}
+
+	chosen := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		chosen[call()]++
+	}
+	assert.Equal(t, map[string]int{"1.1.1.1:80": 2, "2.2.2.2:80": 8}, chosen,
+		"failing peer must be ejected after two requests")
+	assert.Equal(t, 1, list.NumAvailable())
+
+	status := list.Introspect()
+	require.Len(t, status.Peers, 2)
+	for _, ps := range status.Peers {
+		if ps.Identifier == "1.1.1.1:80" {
+			assert.Contains(t, ps.State, "Unavailable, 0 pending request(s), ejected until")
+		}
+	}
+
+	clk.Add(30 * time.Second)
+	for deadline := time.Now().Add(time.Second); list.NumAvailable() != 2; {
+		require.True(t, time.Now().Before(deadline), "timed out waiting for ejected peer")
+		time.Sleep(time.Millisecond)
+	}
+}
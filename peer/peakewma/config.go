@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peakewma
+
+import (
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// Configuration describes how to build a peak-EWMA peer list.
+type Configuration struct {
+	Capacity *int `config:"capacity"`
+
+	// How quickly estimated latencies decay towards recent latencies.
+	DecayTime time.Duration `config:"decayTime"`
+
+	// Estimated latency of peers without any finished requests.
+	DefaultLatency time.Duration `config:"defaultLatency"`
+
+	// Reports metrics for the list to the metrics scope of the Configurator.
+	Metrics bool `config:"metrics"`
+}
+
+// Spec returns a configuration specification for the peak-EWMA peer list
+// implementation, making it possible to prefer peers with lower latencies and
+// fewer pending requests with transports that use outbound peer list
+// configuration (like HTTP).
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerList(peakewma.Spec())
+//
+// This enables the peak-EWMA peer list:
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: https://host:port/rpc
+//          peak-ewma:
+//            decayTime: 10s
+//            defaultLatency: 30ms
+//            peers:
+//              - 127.0.0.1:8080
+//              - 127.0.0.1:8081
+//
+// Setting metrics to true reports the list's metrics to the metrics scope
+// given to the Configurator with yarpcconfig.Metrics, tagged with the
+// outbound. Enabling metrics without such a scope is an error.
+func Spec() yarpcconfig.PeerListSpec {
+	return yarpcconfig.PeerListSpec{
+		Name: "peak-ewma",
+		BuildPeerList: func(cfg Configuration, t peer.Transport, k *yarpcconfig.Kit) (peer.ChooserList, error) {
+			var opts []ListOption
+
+			if cfg.Capacity != nil {
+				if *cfg.Capacity <= 0 {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						"Capacity must be greater than 0. Got: %d.", *cfg.Capacity)
+				}
+				opts = append(opts, Capacity(*cfg.Capacity))
+			}
+
+			if cfg.DecayTime < 0 {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"DecayTime must not be negative. Got: %v.", cfg.DecayTime)
+			}
+			if cfg.DecayTime > 0 {
+				opts = append(opts, DecayTime(cfg.DecayTime))
+			}
+
+			if cfg.DefaultLatency < 0 {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"DefaultLatency must not be negative. Got: %v.", cfg.DefaultLatency)
+			}
+			if cfg.DefaultLatency > 0 {
+				opts = append(opts, DefaultLatency(cfg.DefaultLatency))
+			}
+
+			if cfg.Metrics {
+				meter, err := k.RequireMeter()
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, Meter(meter))
+			}
+
+			return New(t, opts...), nil
+		},
+	}
+}
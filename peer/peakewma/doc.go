@@ -0,0 +1,39 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package peakewma provides a latency-aware peer list that chooses peers by
+// their peak exponentially weighted moving average (peak-EWMA) latency.
+//
+// The list tracks the latency of each request to each peer. The estimated
+// latency of a peer is a moving average of its request latencies that jumps
+// up immediately when a request is slower than the estimate (the peak) and
+// decays gradually otherwise. The score of a peer is its estimated latency
+// multiplied by one more than its number of pending requests, so a peer that
+// slows down or accumulates requests receives less traffic before it
+// saturates.
+//
+// For each request, the list picks two available peers at random and chooses
+// the one with the lower score (the power of two choices), which avoids
+// sending every request to the single best peer.
+//
+// 	list := peakewma.New(transport, peakewma.DecayTime(5*time.Second))
+//
+// See Spec for configuring the list with yarpcconfig.
+package peakewma
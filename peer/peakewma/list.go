@@ -0,0 +1,158 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peakewma
+
+import (
+	"math/rand"
+	"time"
+
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/peerlist/v2"
+)
+
+type listOptions struct {
+	capacity       int
+	decayTime      time.Duration
+	defaultLatency time.Duration
+	source         rand.Source
+	clock          clock.Clock
+	meter          *metrics.Scope
+}
+
+var defaultListOptions = listOptions{
+	capacity:       10,
+	decayTime:      10 * time.Second,
+	defaultLatency: 30 * time.Millisecond,
+}
+
+// ListOption customizes the behavior of a peak-EWMA peer list.
+type ListOption interface {
+	apply(*listOptions)
+}
+
+type listOptionFunc func(*listOptions)
+
+func (f listOptionFunc) apply(options *listOptions) { f(options) }
+
+// Capacity specifies the default capacity of the underlying
+// data structures for this list.
+//
+// Defaults to 10.
+func Capacity(capacity int) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.capacity = capacity
+	})
+}
+
+// DecayTime specifies how quickly the estimated latency of a peer decays
+// towards its recent latencies. After this much time, the weight of older
+// observations has decayed to about a third (1/e).
+//
+// Shorter decay times react faster to peers recovering from a slowdown but
+// are more sensitive to noise.
+//
+// Defaults to 10 seconds.
+func DecayTime(d time.Duration) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.decayTime = d
+	})
+}
+
+// DefaultLatency specifies the estimated latency of peers without any
+// finished requests.
+//
+// Defaults to 30 milliseconds.
+func DefaultLatency(d time.Duration) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.defaultLatency = d
+	})
+}
+
+// Seed specifies the seed for generating random choices.
+func Seed(seed int64) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.source = rand.NewSource(seed)
+	})
+}
+
+// Source is a source of randomness for the peer list.
+func Source(source rand.Source) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.source = source
+	})
+}
+
+func withClock(c clock.Clock) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.clock = c
+	})
+}
+
+// Meter specifies a metrics scope for the list's metrics, like the number of
+// pending requests to each peer and the latency of choosing a peer. See
+// peerlist.Meter for all metrics.
+//
+// Metrics are disabled by default.
+func Meter(meter *metrics.Scope) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.meter = meter
+	})
+}
+
+// New creates a new peak-EWMA peer list.
+func New(transport peer.Transport, opts ...ListOption) *List {
+	options := defaultListOptions
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	if options.source == nil {
+		options.source = rand.NewSource(time.Now().UnixNano())
+	}
+	if options.clock == nil {
+		options.clock = clock.NewReal()
+	}
+
+	plOpts := []peerlist.ListOption{
+		peerlist.Capacity(options.capacity),
+		peerlist.NoShuffle(),
+	}
+	if options.meter != nil {
+		plOpts = append(plOpts, peerlist.Meter(options.meter))
+	}
+
+	return &List{
+		List: peerlist.New(
+			"peak-ewma",
+			transport,
+			newPeakEWMAList(options),
+			plOpts...,
+		),
+	}
+}
+
+// List is a PeerList that chooses the peer with the lower peak-EWMA latency
+// score of two random peers.
+type List struct {
+	*peerlist.List
+}
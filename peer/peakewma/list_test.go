@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peakewma
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+func TestList(t *testing.T) {
+	clk := clock.NewFake()
+	list := New(yarpctest.NewFakeTransport(), Seed(0), withClock(clk))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			hostport.PeerIdentifier("1.1.1.1:80"),
+			hostport.PeerIdentifier("2.2.2.2:80"),
+		},
+	}))
+
+	// Requests to 1.1.1.1 take ten times as long.
+	latencies := map[string]time.Duration{
+		"1.1.1.1:80": 100 * time.Millisecond,
+		"2.2.2.2:80": 10 * time.Millisecond,
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		p, onFinish, err := list.Choose(ctx, &transport.Request{})
+		cancel()
+		require.NoError(t, err)
+
+		clk.Add(latencies[p.Identifier()])
+		onFinish(nil)
+		counts[p.Identifier()]++
+	}
+	assert.True(t, counts["2.2.2.2:80"] > 3*counts["1.1.1.1:80"],
+		"the faster peer must receive most requests: %v", counts)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peakewma
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/peerlist/v2"
+)
+
+// subscriber tracks the peak-EWMA latency of a peer.
+type subscriber struct {
+	index int
+	peer  peer.StatusPeer
+	list  *peakEWMAList
+
+	// Requests finish concurrently with choices, outside the list lock.
+	lock sync.Mutex
+	// Estimated latency in nanoseconds, as of the stamp.
+	latency float64
+	stamp   time.Time
+}
+
+var _ peerlist.RequestSubscriber = (*subscriber)(nil)
+
+func (*subscriber) NotifyStatusChanged(peer.Identifier) {}
+
+// StartRequest begins timing a request to the peer.
+func (s *subscriber) StartRequest() func(error) {
+	start := s.list.clock.Now()
+	return func(error) {
+		now := s.list.clock.Now()
+		s.observe(now, now.Sub(start))
+	}
+}
+
+// observe updates the estimated latency with the latency of a request. Slower
+// requests replace the estimate; faster requests are averaged into it with a
+// weight that decays with the time since the last update.
+func (s *subscriber) observe(now time.Time, latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rtt := float64(latency)
+	if rtt > s.latency {
+		s.latency = rtt
+	} else {
+		w := s.list.decay(now.Sub(s.stamp))
+		s.latency = s.latency*w + rtt*(1-w)
+	}
+	s.stamp = now
+}
+
+// score returns the estimated latency of the peer decayed to now, weighted
+// by its pending requests.
+func (s *subscriber) score(now time.Time) float64 {
+	s.lock.Lock()
+	latency := s.latency * s.list.decay(now.Sub(s.stamp))
+	s.lock.Unlock()
+
+	return latency * float64(s.peer.Status().PendingRequestCount+1)
+}
+
+// peakEWMAList chooses the peer with the lower score of two random peers.
+//
+// The peerlist.List calls Add, Remove and Choose under a lock.
+type peakEWMAList struct {
+	subscribers    []*subscriber
+	random         *rand.Rand
+	clock          clock.Clock
+	decayTime      float64
+	defaultLatency time.Duration
+}
+
+var _ peerlist.Implementation = (*peakEWMAList)(nil)
+
+func newPeakEWMAList(options listOptions) *peakEWMAList {
+	return &peakEWMAList{
+		subscribers:    make([]*subscriber, 0, options.capacity),
+		random:         rand.New(options.source),
+		clock:          options.clock,
+		decayTime:      float64(options.decayTime),
+		defaultLatency: options.defaultLatency,
+	}
+}
+
+// decay returns the weight of an estimate after the given time.
+func (l *peakEWMAList) decay(elapsed time.Duration) float64 {
+	if elapsed <= 0 || l.decayTime <= 0 {
+		return 1
+	}
+	return math.Exp(-float64(elapsed) / l.decayTime)
+}
+
+func (l *peakEWMAList) Add(p peer.StatusPeer, _ peer.Identifier) peer.Subscriber {
+	sub := &subscriber{
+		index:   len(l.subscribers),
+		peer:    p,
+		list:    l,
+		latency: float64(l.defaultLatency),
+		stamp:   l.clock.Now(),
+	}
+	l.subscribers = append(l.subscribers, sub)
+	return sub
+}
+
+func (l *peakEWMAList) Remove(_ peer.StatusPeer, _ peer.Identifier, ps peer.Subscriber) {
+	sub, ok := ps.(*subscriber)
+	if !ok || len(l.subscribers) == 0 {
+		return
+	}
+	index := sub.index
+	last := len(l.subscribers) - 1
+	l.subscribers[index] = l.subscribers[last]
+	l.subscribers[index].index = index
+	l.subscribers[last] = nil
+	l.subscribers = l.subscribers[:last]
+}
+
+func (l *peakEWMAList) Choose(_ context.Context, _ *transport.Request) peer.StatusPeer {
+	numSubs := len(l.subscribers)
+	if numSubs == 0 {
+		return nil
+	}
+	if numSubs == 1 {
+		return l.subscribers[0].peer
+	}
+	i := l.random.Intn(numSubs)
+	j := i + 1 + l.random.Intn(numSubs-1)
+	if j >= numSubs {
+		j -= numSubs
+	}
+
+	now := l.clock.Now()
+	if l.subscribers[j].score(now) < l.subscribers[i].score(now) {
+		i = j
+	}
+	return l.subscribers[i].peer
+}
+
+func (l *peakEWMAList) Start() error {
+	return nil
+}
+
+func (l *peakEWMAList) Stop() error {
+	return nil
+}
+
+func (l *peakEWMAList) IsRunning() bool {
+	return true
+}
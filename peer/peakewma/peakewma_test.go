@@ -0,0 +1,116 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peakewma
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+func newTestList(clk clock.Clock) *peakEWMAList {
+	options := defaultListOptions
+	options.source = rand.NewSource(0)
+	options.clock = clk
+	return newPeakEWMAList(options)
+}
+
+func (l *peakEWMAList) add(id string) *subscriber {
+	pid := hostport.PeerIdentifier(id)
+	return l.Add(hostport.NewPeer(pid, nil), pid).(*subscriber)
+}
+
+func TestObserve(t *testing.T) {
+	clk := clock.NewFake()
+	l := newTestList(clk)
+	sub := l.add("1.1.1.1:80")
+	assert.Equal(t, float64(30*time.Millisecond), sub.latency, "must start with the default latency")
+
+	// Slower requests replace the estimate immediately.
+	sub.observe(clk.Now(), 100*time.Millisecond)
+	assert.Equal(t, float64(100*time.Millisecond), sub.latency)
+
+	// Faster requests are averaged in, with more weight after more time.
+	clk.Add(10 * time.Second)
+	sub.observe(clk.Now(), 0)
+	assert.InDelta(t, float64(100*time.Millisecond)/math.E, sub.latency, 1)
+
+	// The score decays with time and grows with pending requests.
+	latency := sub.latency
+	assert.InDelta(t, latency, sub.score(clk.Now()), 1)
+	assert.InDelta(t, latency/math.E, sub.score(clk.Now().Add(10*time.Second)), 1)
+	sub.peer.(*hostport.Peer).StartRequest()
+	assert.InDelta(t, 2*latency, sub.score(clk.Now()), 1)
+}
+
+func TestStartRequest(t *testing.T) {
+	clk := clock.NewFake()
+	l := newTestList(clk)
+	sub := l.add("1.1.1.1:80")
+
+	onFinish := sub.StartRequest()
+	clk.Add(50 * time.Millisecond)
+	onFinish(nil)
+	assert.Equal(t, float64(50*time.Millisecond), sub.latency)
+}
+
+func TestChoose(t *testing.T) {
+	clk := clock.NewFake()
+	l := newTestList(clk)
+	choose := func() string {
+		return l.Choose(context.Background(), &transport.Request{}).Identifier()
+	}
+
+	assert.Nil(t, l.Choose(context.Background(), &transport.Request{}))
+
+	slow := l.add("1.1.1.1:80")
+	assert.Equal(t, "1.1.1.1:80", choose())
+
+	fast := l.add("2.2.2.2:80")
+	slow.observe(clk.Now(), time.Second)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "2.2.2.2:80", choose(), "must choose the faster peer")
+	}
+
+	// Once the slow peer recovers, it is preferred.
+	clk.Add(time.Minute)
+	slow.observe(clk.Now(), time.Millisecond)
+	fast.observe(clk.Now(), 20*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "1.1.1.1:80", choose(), "must choose the recovered peer")
+	}
+
+	l.Remove(nil, nil, slow)
+	assert.Len(t, l.subscribers, 1)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "2.2.2.2:80", choose())
+	}
+	l.Remove(nil, nil, peer.Subscriber(nil))
+	assert.Len(t, l.subscribers, 1)
+}
@@ -29,8 +29,10 @@ import (
 
 	"go.uber.org/atomic"
 	"go.uber.org/multierr"
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
 	"go.uber.org/yarpc/internal/introspection"
 	intyarpcerrors "go.uber.org/yarpc/internal/yarpcerrors"
 	"go.uber.org/yarpc/pkg/lifecycle"
@@ -64,10 +66,56 @@ type Implementation interface {
 	Choose(context.Context, *transport.Request) peer.StatusPeer
 }
 
+// ChangeableImplementation is an Implementation that tracks attributes of
+// peer identifiers, like their weight.
+//
+// When a peer list updater changes the identifier of an available peer, the
+// peerlist.List calls Change with the new identifier instead of removing and
+// adding the peer again. Implementations that do not implement Change
+// receive the new identifier the next time the peer becomes available.
+type ChangeableImplementation interface {
+	Implementation
+
+	Change(peer.StatusPeer, peer.Identifier, peer.Subscriber)
+}
+
+// RequestSubscriber is a peer.Subscriber that observes the requests sent to
+// its peer. If the subscriber an Implementation returns from Add implements
+// RequestSubscriber, the peerlist.List calls StartRequest each time the peer
+// is chosen, allowing implementations to choose peers based on the outcome
+// of their requests, like their latency.
+//
+// StartRequest and the function it returns are called without holding the
+// list lock, so they must be safe for concurrent use.
+type RequestSubscriber interface {
+	peer.Subscriber
+
+	// StartRequest is called when the peer is chosen for a request. It
+	// returns a function that is called with the error, if any, when the
+	// request finishes.
+	StartRequest() (onFinish func(error))
+}
+
+// OutcomeObserver is a peer that observes the outcome of the requests sent to
+// it, like a peer of a transport that detects outliers. If a peer retained
+// from the transport implements OutcomeObserver, the peerlist.List reports
+// the error, if any, of each request sent to it when the request finishes.
+type OutcomeObserver interface {
+	peer.Peer
+
+	// ObserveOutcome is called with the error, if any, when a request to the
+	// peer finishes, after EndRequest.
+	ObserveOutcome(error)
+}
+
 type listOptions struct {
-	capacity  int
-	noShuffle bool
-	seed      int64
+	capacity        int
+	noShuffle       bool
+	seed            int64
+	slowStartWindow time.Duration
+	slowStartRamp   SlowStartRamp
+	clock           clock.Clock
+	meter           *metrics.Scope
 }
 
 var defaultListOptions = listOptions{
@@ -110,6 +158,24 @@ func Seed(seed int64) ListOption {
 	})
 }
 
+// Meter specifies a metrics scope for the list's metrics: the number of
+// pending requests to the list and to each of its peers, the latency of
+// choosing a peer, the number of available and unavailable peers, the number
+// of peers added and removed, and the number of requests that timed out
+// waiting for an available peer.
+//
+// Metrics are tagged with the name of the list. Lists that share a scope
+// must have different names, so give each list its own scope, like one
+// tagged with the name of its outbound. Pending requests are reported for up
+// to 256 peers separately, and for any further peers together under the peer
+// tag "other", so that the number of metrics stays bounded as peers come and
+// go.
+func Meter(meter *metrics.Scope) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.meter = meter
+	})
+}
+
 // New creates a new peer list with an identifier chooser for available peers.
 func New(name string, transport peer.Transport, availableChooser Implementation, opts ...ListOption) *List {
 	options := defaultListOptions
@@ -117,6 +183,17 @@ func New(name string, transport peer.Transport, availableChooser Implementation,
 		o.apply(&options)
 	}
 
+	randSrc := rand.NewSource(options.seed)
+
+	var ss *slowStart
+	if options.slowStartWindow > 0 {
+		c := options.clock
+		if c == nil {
+			c = clock.NewReal()
+		}
+		ss = newSlowStart(options.slowStartWindow, options.slowStartRamp, c, randSrc)
+	}
+
 	return &List{
 		once:               lifecycle.NewOnce(),
 		name:               name,
@@ -126,8 +203,11 @@ func New(name string, transport peer.Transport, availableChooser Implementation,
 		availableChooser:   availableChooser,
 		transport:          transport,
 		noShuffle:          options.noShuffle,
-		randSrc:            rand.NewSource(options.seed),
+		randSrc:            randSrc,
+		slowStart:          ss,
+		metrics:            newListMetrics(options.meter, name),
 		peerAvailableEvent: make(chan struct{}, 1),
+		peerCountsChanged:  make(chan struct{}),
 	}
 }
 
@@ -154,22 +234,32 @@ type List struct {
 	peerAvailableEvent chan struct{}
 	transport          peer.Transport
 
+	// Closed and replaced whenever the number of available or unavailable
+	// peers may have changed, waking goroutines waiting for readiness.
+	peerCountsChanged chan struct{}
+
 	noShuffle bool
 	randSrc   rand.Source
+	slowStart *slowStart
+	metrics   *listMetrics
 
 	once *lifecycle.Once
 }
 
-// Update applies the additions and removals of peer Identifiers to the list
-// it returns a multi-error result of every failure that happened without
-// circuit breaking due to failures.
+// Update applies the additions, removals and changes of peer Identifiers to
+// the list it returns a multi-error result of every failure that happened
+// without circuit breaking due to failures.
 func (pl *List) Update(updates peer.ListUpdates) error {
-	if len(updates.Additions) == 0 && len(updates.Removals) == 0 {
+	if len(updates.Additions) == 0 && len(updates.Removals) == 0 && len(updates.Changes) == 0 {
 		return nil
 	}
 
 	pl.lock.Lock()
 	defer pl.lock.Unlock()
+	defer pl.observePeerCounts()
+
+	pl.metrics.added.Add(int64(len(updates.Additions)))
+	pl.metrics.removed.Add(int64(len(updates.Removals)))
 
 	if pl.shouldRetainPeers.Load() {
 		return pl.updateInitialized(updates)
@@ -177,6 +267,17 @@ func (pl *List) Update(updates peer.ListUpdates) error {
 	return pl.updateUninitialized(updates)
 }
 
+// observePeerCounts updates the gauges of available and unavailable peers
+// and wakes goroutines waiting for readiness.
+// Must be run in a mutex.Lock()
+func (pl *List) observePeerCounts() {
+	pl.metrics.available.Store(int64(len(pl.availablePeers)))
+	pl.metrics.unavailable.Store(int64(len(pl.unavailablePeers)))
+
+	close(pl.peerCountsChanged)
+	pl.peerCountsChanged = make(chan struct{})
+}
+
 // updateInitialized applies peer list updates when the peer list
 // is able to retain peers, putting the updates into the available
 // or unavailable containers.
@@ -196,6 +297,10 @@ func (pl *List) updateInitialized(updates peer.ListUpdates) error {
 	for _, pid := range add {
 		errs = multierr.Append(errs, pl.addPeerIdentifier(pid))
 	}
+
+	for _, pid := range updates.Changes {
+		errs = multierr.Append(errs, pl.changePeerIdentifier(pid))
+	}
 	return errs
 }
 
@@ -216,6 +321,13 @@ func (pl *List) updateUninitialized(updates peer.ListUpdates) error {
 	for _, pid := range updates.Additions {
 		pl.uninitializedPeers[pid.Identifier()] = pid
 	}
+	for _, pid := range updates.Changes {
+		if _, ok := pl.uninitializedPeers[pid.Identifier()]; !ok {
+			errs = multierr.Append(errs, peer.ErrPeerChangeNotInList(pid.Identifier()))
+			continue
+		}
+		pl.uninitializedPeers[pid.Identifier()] = pid
+	}
 
 	return errs
 }
@@ -226,7 +338,7 @@ func (pl *List) addPeerIdentifier(pid peer.Identifier) error {
 		return peer.ErrPeerAddAlreadyInList(pid.Identifier())
 	}
 
-	t := &peerThunk{list: pl, id: pid}
+	t := &peerThunk{list: pl, id: pid, pending: pl.metrics.peerPendingGauge(pid.Identifier())}
 	t.boundOnFinish = t.onFinish
 	p, err := pl.transport.RetainPeer(pid, t)
 	if err != nil {
@@ -236,6 +348,25 @@ func (pl *List) addPeerIdentifier(pid peer.Identifier) error {
 	return pl.addPeer(t)
 }
 
+// changePeerIdentifier replaces the identifier of a peer in the list,
+// informing the implementation if the peer is available.
+// Must be run in a mutex.Lock()
+func (pl *List) changePeerIdentifier(pid peer.Identifier) error {
+	t := pl.getThunk(pid)
+	if t == nil {
+		return peer.ErrPeerChangeNotInList(pid.Identifier())
+	}
+
+	t.id = pid
+	if pl.availablePeers[pid.Identifier()] == nil {
+		return nil
+	}
+	if impl, ok := pl.availableChooser.(ChangeableImplementation); ok {
+		impl.Change(t, t.id, t.Subscriber())
+	}
+	return nil
+}
+
 // Must be run in a mutex.Lock()
 func (pl *List) addPeer(t *peerThunk) error {
 	if t.peer.Status().ConnectionStatus != peer.Available {
@@ -259,6 +390,9 @@ func (pl *List) addToAvailablePeers(t *peerThunk) error {
 	sub := pl.availableChooser.Add(t, t.id)
 	t.SetSubscriber(sub)
 	pl.availablePeers[t.Identifier()] = t
+	if pl.slowStart != nil {
+		pl.slowStart.add(t)
+	}
 	pl.notifyPeerAvailable()
 	return nil
 }
@@ -288,6 +422,7 @@ func (pl *List) start() error {
 	}
 
 	pl.shouldRetainPeers.Store(true)
+	pl.observePeerCounts()
 
 	return errs
 }
@@ -317,6 +452,7 @@ func (pl *List) stop() error {
 	pl.addToUninitialized(unavailablePeers)
 
 	pl.shouldRetainPeers.Store(false)
+	pl.observePeerCounts()
 
 	return errs
 }
@@ -336,6 +472,7 @@ func (pl *List) removeAllAvailablePeers(toRemove map[string]*peerThunk) []*peerT
 		thunks = append(thunks, t)
 		delete(pl.availablePeers, id)
 		pl.availableChooser.Remove(t, t.id, t.Subscriber())
+		pl.removeFromSlowStart(t)
 	}
 	return thunks
 }
@@ -399,6 +536,7 @@ func (pl *List) removeFromAvailablePeers(t *peerThunk) error {
 	delete(pl.availablePeers, t.peer.Identifier())
 	pl.availableChooser.Remove(t, t.id, t.Subscriber())
 	t.SetSubscriber(nil)
+	pl.removeFromSlowStart(t)
 	return nil
 }
 
@@ -411,13 +549,22 @@ func (pl *List) removeFromUnavailablePeers(t *peerThunk) {
 
 // Choose selects the next available peer in the peer list
 func (pl *List) Choose(ctx context.Context, req *transport.Request) (peer.Peer, func(error), error) {
+	if pl.metrics.chooseLatency != nil {
+		start := time.Now()
+		defer func() { pl.metrics.chooseLatency.Observe(time.Since(start)) }()
+	}
+
 	if err := pl.once.WaitUntilRunning(ctx); err != nil {
 		return nil, nil, intyarpcerrors.AnnotateWithInfo(yarpcerrors.FromError(err), "%s peer list is not running", pl.name)
 	}
 
 	for {
 		pl.lock.Lock()
-		p := pl.availableChooser.Choose(ctx, req)
+		p := pl.slowStartChoose(ctx, req, pl.availableChooser.Choose(ctx, req))
+		var sub peer.Subscriber
+		if p != nil {
+			sub = p.(*peerThunk).Subscriber()
+		}
 		pl.lock.Unlock()
 
 		if p != nil {
@@ -430,6 +577,15 @@ func (pl *List) Choose(ctx context.Context, req *transport.Request) (peer.Peer,
 			t := p.(*peerThunk)
 			pl.notifyPeerAvailable()
 			t.StartRequest()
+			pl.metrics.pending.Inc()
+			t.pending.Inc()
+			if rs, ok := sub.(RequestSubscriber); ok {
+				onFinish := rs.StartRequest()
+				return t.peer, func(err error) {
+					t.onFinish(err)
+					onFinish(err)
+				}, nil
+			}
 			return t.peer, t.boundOnFinish, nil
 		}
 		if err := pl.waitForPeerAddedEvent(ctx); err != nil {
@@ -464,6 +620,7 @@ func (pl *List) waitForPeerAddedEvent(ctx context.Context) error {
 	case <-pl.peerAvailableEvent:
 		return nil
 	case <-ctx.Done():
+		pl.metrics.timeouts.Inc()
 		return pl.newUnavailableError(ctx.Err())
 	}
 }
@@ -481,10 +638,14 @@ func (pl *List) newUnavailableError(err error) error {
 func (pl *List) NotifyStatusChanged(pid peer.Identifier) {
 	pl.lock.RLock()
 	t := pl.getThunk(pid)
+	var id peer.Identifier
+	if t != nil {
+		id = t.id
+	}
 	pl.lock.RUnlock()
 
 	if t != nil {
-		t.NotifyStatusChanged(t.id)
+		t.NotifyStatusChanged(id)
 	}
 }
 
@@ -501,6 +662,7 @@ func (pl *List) getThunk(pid peer.Identifier) *peerThunk {
 func (pl *List) notifyStatusChanged(pid peer.Identifier) {
 	pl.lock.Lock()
 	defer pl.lock.Unlock()
+	defer pl.observePeerCounts()
 
 	if t := pl.availablePeers[pid.Identifier()]; t != nil {
 		// TODO: log error
@@ -528,6 +690,7 @@ func (pl *List) handleAvailablePeerStatusChange(t *peerThunk) error {
 	pl.availableChooser.Remove(t, t.id, t.Subscriber())
 	t.SetSubscriber(nil)
 	delete(pl.availablePeers, t.peer.Identifier())
+	pl.removeFromSlowStart(t)
 
 	return pl.addToUnavailablePeers(t)
 
@@ -573,18 +736,51 @@ func (pl *List) Peers() []peer.Peer {
 	return peers
 }
 
+// WaitUntilReady blocks until the list meets the given readiness criteria,
+// counting its available and unavailable peers, or returns an error if the
+// context finishes first.
+//
+// Unlike Choose, WaitUntilReady does not require a context deadline.
+func (pl *List) WaitUntilReady(ctx context.Context, r peer.Readiness) error {
+	for {
+		pl.lock.RLock()
+		available := len(pl.availablePeers)
+		total := available + len(pl.unavailablePeers)
+		changed := pl.peerCountsChanged
+		pl.lock.RUnlock()
+
+		if r.IsReady(available, total) {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return yarpcerrors.Newf(yarpcerrors.CodeUnavailable,
+				"%s peer list timed out waiting for readiness with %d of %d peers available: %s",
+				pl.name, available, total, ctx.Err().Error())
+		}
+	}
+}
+
 // NumAvailable returns how many peers are available.
 func (pl *List) NumAvailable() int {
+	pl.lock.RLock()
+	defer pl.lock.RUnlock()
 	return len(pl.availablePeers)
 }
 
 // NumUnavailable returns how many peers are unavailable.
 func (pl *List) NumUnavailable() int {
+	pl.lock.RLock()
+	defer pl.lock.RUnlock()
 	return len(pl.unavailablePeers)
 }
 
 // NumUninitialized returns how many peers are unavailable.
 func (pl *List) NumUninitialized() int {
+	pl.lock.RLock()
+	defer pl.lock.RUnlock()
 	return len(pl.uninitializedPeers)
 }
 
@@ -610,6 +806,9 @@ func (pl *List) Introspect() introspection.ChooserStatus {
 		len(availables)+len(unavailables))
 
 	buildPeerStatus := func(peer peer.Peer) introspection.PeerStatus {
+		if ip, ok := peer.(introspection.IntrospectablePeer); ok {
+			return ip.Introspect()
+		}
 		ps := peer.Status()
 		return introspection.PeerStatus{
 			Identifier: peer.Identifier(),
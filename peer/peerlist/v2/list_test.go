@@ -22,8 +22,10 @@ package peerlist
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -222,3 +224,151 @@ func TestPeerList(t *testing.T) {
 		},
 	}))
 }
+
+// changeList records the identifiers it receives.
+type changeList struct {
+	mraList
+
+	added   []peer.Identifier
+	changed []peer.Identifier
+}
+
+var _ ChangeableImplementation = (*changeList)(nil)
+
+func (l *changeList) Add(peer peer.StatusPeer, pid peer.Identifier) peer.Subscriber {
+	l.added = append(l.added, pid)
+	return l.mraList.Add(peer, pid)
+}
+
+func (l *changeList) Change(peer peer.StatusPeer, pid peer.Identifier, ps peer.Subscriber) {
+	l.changed = append(l.changed, pid)
+}
+
+func TestPeerListChanges(t *testing.T) {
+	weighted := func(weight int) peer.Identifier {
+		return hostport.IdentifyWithAttributes("1.1.1.1:4040", hostport.PeerAttributes{Weight: weight})
+	}
+
+	fake := yarpctest.NewFakeTransport(yarpctest.InitialConnectionStatus(peer.Unavailable))
+	impl := &changeList{}
+	list := New("change", fake, impl, NoShuffle())
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{hostport.Identify("1.1.1.1:4040")},
+	}))
+
+	// Changes before start replace uninitialized peers.
+	require.NoError(t, list.Update(peer.ListUpdates{Changes: []peer.Identifier{weighted(2)}}))
+	assert.Error(t, list.Update(peer.ListUpdates{
+		Changes: []peer.Identifier{hostport.Identify("2.2.2.2:4040")},
+	}), "must not change unknown peer")
+
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	fake.SimulateConnect(hostport.Identify("1.1.1.1:4040"))
+	assert.Equal(t, []peer.Identifier{weighted(2)}, impl.added)
+
+	// Changes to available peers are forwarded to the implementation.
+	require.NoError(t, list.Update(peer.ListUpdates{Changes: []peer.Identifier{weighted(3)}}))
+	assert.Equal(t, []peer.Identifier{weighted(3)}, impl.changed)
+	assert.Nil(t, impl.mrr, "must not remove changed peer")
+
+	// Changes to unavailable peers take effect once they become available.
+	fake.SimulateDisconnect(hostport.Identify("1.1.1.1:4040"))
+	require.NoError(t, list.Update(peer.ListUpdates{Changes: []peer.Identifier{weighted(4)}}))
+	assert.Equal(t, []peer.Identifier{weighted(3)}, impl.changed)
+	fake.SimulateConnect(hostport.Identify("1.1.1.1:4040"))
+	assert.Equal(t, []peer.Identifier{weighted(2), weighted(4)}, impl.added)
+
+	assert.Error(t, list.Update(peer.ListUpdates{
+		Changes: []peer.Identifier{hostport.Identify("2.2.2.2:4040")},
+	}), "must not change unknown peer")
+}
+
+// requestList chooses the first peer it receives, with a subscriber that
+// records the outcome of requests.
+type requestList struct {
+	mraList
+
+	started  int
+	finished []error
+}
+
+type requestSub struct {
+	list *requestList
+}
+
+var _ RequestSubscriber = (*requestSub)(nil)
+
+func (s *requestSub) NotifyStatusChanged(peer.Identifier) {}
+
+func (s *requestSub) StartRequest() func(error) {
+	s.list.started++
+	return func(err error) {
+		s.list.finished = append(s.list.finished, err)
+	}
+}
+
+func (l *requestList) Add(peer peer.StatusPeer, pid peer.Identifier) peer.Subscriber {
+	l.mraList.Add(peer, pid)
+	return &requestSub{list: l}
+}
+
+func TestPeerListRequestSubscriber(t *testing.T) {
+	fake := yarpctest.NewFakeTransport()
+	impl := &requestList{}
+	list := New("request", fake, impl)
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{hostport.Identify("1.1.1.1:4040")},
+	}))
+
+	p, onFinish, err := list.Choose(context.Background(), &transport.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, impl.started)
+	assert.Equal(t, 1, p.Status().PendingRequestCount)
+
+	sadness := errors.New("great sadness")
+	onFinish(sadness)
+	assert.Equal(t, []error{sadness}, impl.finished)
+	assert.Equal(t, 0, p.Status().PendingRequestCount)
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	fake := yarpctest.NewFakeTransport(yarpctest.InitialConnectionStatus(peer.Unavailable))
+	list := New("rotating", fake, &rotatingList{})
+	require.NoError(t, list.Start())
+	defer list.Stop()
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{id1, id2, id3},
+	}))
+
+	readiness := peer.Readiness{MinAvailableFraction: 0.5}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := list.WaitUntilReady(ctx, readiness)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rotating peer list timed out waiting for readiness with 0 of 3 peers available")
+
+	ready := make(chan error)
+	go func() { ready <- list.WaitUntilReady(context.Background(), readiness) }()
+
+	fake.SimulateConnect(id1)
+	select {
+	case err := <-ready:
+		t.Fatalf("list must not be ready with 1 of 3 peers available: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	fake.SimulateConnect(id2)
+	select {
+	case err := <-ready:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("list must be ready with 2 of 3 peers available")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerlist
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/net/metrics"
+)
+
+// Buckets of the choose latency histogram, in milliseconds.
+var _chooseLatencyBuckets = []int64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// _maxPeerPendingSeries is the number of peers of a list whose pending
+// requests are reported separately. Pending requests to any further peers are
+// reported together under the peer tag _otherPeers.
+const _maxPeerPendingSeries = 256
+
+// _otherPeers is the peer tag of pending requests to peers beyond
+// _maxPeerPendingSeries.
+const _otherPeers = "other"
+
+// listMetrics are the metrics of a peer list. All metrics are nil, and do
+// nothing, if the list has no metrics scope.
+type listMetrics struct {
+	pending       *metrics.Gauge
+	peerPending   *metrics.GaugeVector
+	chooseLatency *metrics.Histogram
+	available     *metrics.Gauge
+	unavailable   *metrics.Gauge
+	added         *metrics.Counter
+	removed       *metrics.Counter
+	timeouts      *metrics.Counter
+
+	// Metrics cannot be unregistered, so the peers with their own series of
+	// pending requests are tracked to bound the number of series.
+	peersMu sync.Mutex
+	peers   map[string]struct{}
+}
+
+// newListMetrics registers the metrics of a peer list with the given name.
+// Metrics that fail to register, like metrics registered by another peer list
+// with the same name and scope, do nothing.
+func newListMetrics(meter *metrics.Scope, name string) *listMetrics {
+	var m listMetrics
+	if meter == nil {
+		return &m
+	}
+
+	tags := metrics.Tags{"peer_list": name}
+	m.pending, _ = meter.Gauge(metrics.Spec{
+		Name:      "peer_list_pending_requests",
+		Help:      "Number of pending requests to the peers of the peer list.",
+		ConstTags: tags,
+	})
+	m.peerPending, _ = meter.GaugeVector(metrics.Spec{
+		Name:      "peer_list_peer_pending_requests",
+		Help:      "Number of pending requests to each peer of the peer list.",
+		ConstTags: tags,
+		VarTags:   []string{"peer"},
+	})
+	m.peers = make(map[string]struct{})
+	m.chooseLatency, _ = meter.Histogram(metrics.HistogramSpec{
+		Spec: metrics.Spec{
+			Name:      "peer_list_choose_latency_ms",
+			Help:      "Latency of choosing a peer, including waiting for an available peer.",
+			ConstTags: tags,
+		},
+		Unit:    time.Millisecond,
+		Buckets: _chooseLatencyBuckets,
+	})
+	m.available, _ = meter.Gauge(metrics.Spec{
+		Name:      "peer_list_available_peers",
+		Help:      "Number of available peers in the peer list.",
+		ConstTags: tags,
+	})
+	m.unavailable, _ = meter.Gauge(metrics.Spec{
+		Name:      "peer_list_unavailable_peers",
+		Help:      "Number of unavailable peers in the peer list.",
+		ConstTags: tags,
+	})
+	m.added, _ = meter.Counter(metrics.Spec{
+		Name:      "peer_list_peers_added",
+		Help:      "Number of peers added to the peer list.",
+		ConstTags: tags,
+	})
+	m.removed, _ = meter.Counter(metrics.Spec{
+		Name:      "peer_list_peers_removed",
+		Help:      "Number of peers removed from the peer list.",
+		ConstTags: tags,
+	})
+	m.timeouts, _ = meter.Counter(metrics.Spec{
+		Name:      "peer_list_timeouts",
+		Help:      "Number of requests that timed out waiting for an available peer.",
+		ConstTags: tags,
+	})
+	return &m
+}
+
+// peerPendingGauge returns the gauge of pending requests to the peer with the
+// given identifier. Peers keep their gauge if they are removed and added
+// again. Once _maxPeerPendingSeries peers have a gauge, further peers share
+// the gauge of _otherPeers.
+func (m *listMetrics) peerPendingGauge(id string) *metrics.Gauge {
+	if m.peerPending == nil {
+		return nil
+	}
+
+	m.peersMu.Lock()
+	if _, ok := m.peers[id]; !ok {
+		if len(m.peers) < _maxPeerPendingSeries {
+			m.peers[id] = struct{}{}
+		} else {
+			id = _otherPeers
+		}
+	}
+	m.peersMu.Unlock()
+
+	g, _ := m.peerPending.Get("peer", id)
+	return g
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerlist
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+// metricValues returns the values of the counters and gauges of the given
+// root by name.
+func metricValues(root *metrics.Root) map[string]int64 {
+	values := make(map[string]int64)
+	snap := root.Snapshot()
+	for _, s := range append(snap.Counters, snap.Gauges...) {
+		values[s.Name] = s.Value
+	}
+	return values
+}
+
+// peerPendingValues returns the pending requests to each peer reported to
+// the given root, by peer.
+func peerPendingValues(root *metrics.Root) map[string]int64 {
+	values := make(map[string]int64)
+	for _, s := range root.Snapshot().Gauges {
+		if s.Name == "peer_list_peer_pending_requests" {
+			values[s.Tags["peer"]] = s.Value
+		}
+	}
+	return values
+}
+
+func TestListMetrics(t *testing.T) {
+	root := metrics.New()
+	fake := yarpctest.NewFakeTransport()
+	list := New("rotating", fake, &rotatingList{}, NoShuffle(), Meter(root.Scope()))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			hostport.Identify("1.1.1.1:1111"),
+			hostport.Identify("2.2.2.2:2222"),
+		},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p, onFinish, err := list.Choose(ctx, &transport.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1:1111", p.Identifier())
+
+	values := metricValues(root)
+	assert.Equal(t, int64(2), values["peer_list_available_peers"])
+	assert.Equal(t, int64(0), values["peer_list_unavailable_peers"])
+	assert.Equal(t, int64(2), values["peer_list_peers_added"])
+	assert.Equal(t, int64(1), values["peer_list_pending_requests"])
+	assert.Equal(t, map[string]int64{"1.1.1.1:1111": 1, "2.2.2.2:2222": 0}, peerPendingValues(root))
+
+	onFinish(nil)
+	fake.SimulateDisconnect(hostport.Identify("2.2.2.2:2222"))
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Removals: []peer.Identifier{hostport.Identify("1.1.1.1:1111")},
+	}))
+
+	values = metricValues(root)
+	assert.Equal(t, int64(0), values["peer_list_pending_requests"])
+	assert.Equal(t, int64(0), values["peer_list_available_peers"])
+	assert.Equal(t, int64(1), values["peer_list_unavailable_peers"])
+	assert.Equal(t, int64(1), values["peer_list_peers_removed"])
+	assert.Equal(t, map[string]int64{"1.1.1.1:1111": 0, "2.2.2.2:2222": 0}, peerPendingValues(root))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = list.Choose(ctx, &transport.Request{})
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), metricValues(root)["peer_list_timeouts"])
+
+	histograms := root.Snapshot().Histograms
+	require.Len(t, histograms, 1)
+	assert.Equal(t, "peer_list_choose_latency_ms", histograms[0].Name)
+	assert.Equal(t, "rotating", histograms[0].Tags["peer_list"])
+	assert.Len(t, histograms[0].Values, 2)
+}
+
+func TestListMetricsSharedScope(t *testing.T) {
+	root := metrics.New()
+	fake := yarpctest.NewFakeTransport()
+
+	// Lists with the same name can't register the same metrics, but still
+	// work.
+	first := New("rotating", fake, &rotatingList{}, Meter(root.Scope()))
+	second := New("rotating", fake, &rotatingList{}, Meter(root.Scope()))
+	require.NoError(t, first.Start())
+	defer first.Stop()
+	require.NoError(t, second.Start())
+	defer second.Stop()
+
+	require.NoError(t, second.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{hostport.Identify("1.1.1.1:1111")},
+	}))
+	assert.Equal(t, int64(0), metricValues(root)["peer_list_peers_added"])
+}
+
+func TestListMetricsBoundedPeers(t *testing.T) {
+	root := metrics.New()
+	m := newListMetrics(root.Scope(), "rotating")
+
+	for i := 0; i < _maxPeerPendingSeries; i++ {
+		m.peerPendingGauge(fmt.Sprintf("10.0.0.%d:80", i)).Inc()
+	}
+	// Known peers keep their gauge, and further peers share one.
+	m.peerPendingGauge("10.0.0.0:80").Inc()
+	m.peerPendingGauge("192.168.0.1:80").Inc()
+	m.peerPendingGauge("192.168.0.2:80").Inc()
+
+	values := peerPendingValues(root)
+	assert.Len(t, values, _maxPeerPendingSeries+1)
+	assert.Equal(t, int64(2), values["10.0.0.0:80"])
+	assert.Equal(t, int64(1), values["10.0.0.1:80"])
+	assert.Equal(t, int64(2), values[_otherPeers])
+}
+
+func TestListMetricsWithoutScope(t *testing.T) {
+	m := newListMetrics(nil, "rotating")
+	assert.Nil(t, m.peerPendingGauge("1.1.1.1:1111"))
+}
@@ -23,6 +23,7 @@ package peerlist
 import (
 	"sync"
 
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/peer"
 )
 
@@ -35,10 +36,16 @@ type peerThunk struct {
 	peer          peer.Peer
 	subscriber    peer.Subscriber
 	boundOnFinish func(error)
+	pending       *metrics.Gauge
 }
 
-func (t *peerThunk) onFinish(error) {
+func (t *peerThunk) onFinish(err error) {
 	t.peer.EndRequest()
+	t.list.metrics.pending.Dec()
+	t.pending.Dec()
+	if o, ok := t.peer.(OutcomeObserver); ok {
+		o.ObserveOutcome(err)
+	}
 }
 
 func (t *peerThunk) Identifier() string {
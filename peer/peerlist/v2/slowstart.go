@@ -0,0 +1,177 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerlist
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// SlowStartRamp maps the progress of a peer through its slow-start window,
+// from 0 when the peer becomes available to 1 when the window elapses, to the
+// fraction of its usual share of requests the peer receives.
+type SlowStartRamp func(progress float64) float64
+
+// LinearRamp is a SlowStartRamp that increases a peer's share of requests
+// linearly over the slow-start window.
+func LinearRamp(progress float64) float64 {
+	return progress
+}
+
+// ExponentialRamp is a SlowStartRamp that doubles a peer's share of requests
+// every tenth of the slow-start window, so the peer receives about a
+// thousandth of its usual share when it becomes available and about a
+// thirtieth halfway through the window.
+func ExponentialRamp(progress float64) float64 {
+	return math.Pow(2, 10*(progress-1))
+}
+
+// SlowStart ramps up the share of requests the list sends to each peer that
+// becomes available, whether it was just added or recovered, over the given
+// window.
+// While a peer is in its slow-start window, the list skips it when the
+// underlying Implementation chooses it, with a probability of one less the
+// fraction given by the ramp, and asks the Implementation to choose again, a
+// bounded number of times.
+//
+// Peers are not slowed down when every available peer is in its slow-start
+// window, like when the list starts, since there are no other peers to send
+// requests to.
+//
+// Slow start is disabled by default.
+func SlowStart(window time.Duration, ramp SlowStartRamp) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.slowStartWindow = window
+		options.slowStartRamp = ramp
+	})
+}
+
+func withClock(c clock.Clock) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.clock = c
+	})
+}
+
+// SlowStartConfig describes the slow start of peers in the configuration of
+// peer lists that support it.
+//
+//  slowStart:
+//    window: 30s
+//    ramp: exponential
+//
+// The ramp may be "linear", the default, or "exponential".
+type SlowStartConfig struct {
+	Window time.Duration `config:"window"`
+	Ramp   string        `config:"ramp"`
+}
+
+// Build validates the configuration, returning the window and ramp to pass
+// to the list's slow start option.
+func (c SlowStartConfig) Build() (time.Duration, SlowStartRamp, error) {
+	if c.Window <= 0 {
+		return 0, nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, "slow start window must be greater than 0. Got: %v.", c.Window)
+	}
+	switch c.Ramp {
+	case "", "linear":
+		return c.Window, LinearRamp, nil
+	case "exponential":
+		return c.Window, ExponentialRamp, nil
+	default:
+		return 0, nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, `slow start ramp must be "linear" or "exponential". Got: %q.`, c.Ramp)
+	}
+}
+
+// slowStart tracks when peers in their slow-start window became available.
+type slowStart struct {
+	window  time.Duration
+	ramp    SlowStartRamp
+	clock   clock.Clock
+	rand    *rand.Rand
+	warming map[*peerThunk]time.Time
+}
+
+func newSlowStart(window time.Duration, ramp SlowStartRamp, c clock.Clock, src rand.Source) *slowStart {
+	if ramp == nil {
+		ramp = LinearRamp
+	}
+	return &slowStart{
+		window:  window,
+		ramp:    ramp,
+		clock:   c,
+		rand:    rand.New(src),
+		warming: make(map[*peerThunk]time.Time),
+	}
+}
+
+// Must be run in a mutex.Lock()
+func (s *slowStart) add(t *peerThunk) {
+	s.warming[t] = s.clock.Now()
+}
+
+// Must be run in a mutex.Lock()
+func (pl *List) removeFromSlowStart(t *peerThunk) {
+	if pl.slowStart != nil {
+		delete(pl.slowStart.warming, t)
+	}
+}
+
+// slowStartChoose returns the peer the implementation chose, or another peer
+// if it skips the chosen peer because it is in its slow-start window.
+// Must be run in a mutex.Lock()
+func (pl *List) slowStartChoose(ctx context.Context, req *transport.Request, p peer.StatusPeer) peer.StatusPeer {
+	s := pl.slowStart
+	if s == nil || p == nil || len(s.warming) == 0 {
+		return p
+	}
+
+	now := s.clock.Now()
+	for t, since := range s.warming {
+		if now.Sub(since) >= s.window {
+			delete(s.warming, t)
+		}
+	}
+	if len(s.warming) == 0 || len(s.warming) >= len(pl.availablePeers) {
+		return p
+	}
+
+	// Ask the implementation to choose again instead of taking skipped peers
+	// out of it, which would reset the state it keeps for them. One attempt
+	// more than there are warming peers is enough for implementations that
+	// go through peers in turn to reach a peer that is not warming.
+	first := p
+	for attempts := len(s.warming) + 1; attempts > 0; attempts-- {
+		since, ok := s.warming[p.(*peerThunk)]
+		if !ok || s.rand.Float64() < s.ramp(float64(now.Sub(since))/float64(s.window)) {
+			return p
+		}
+		if p = pl.availableChooser.Choose(ctx, req); p == nil {
+			break
+		}
+	}
+	return first
+}
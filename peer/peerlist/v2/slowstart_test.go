@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerlist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+// rotating peer list implementation for the test, choosing the peer at the
+// front of the queue and moving it to the back.
+type rotatingList struct {
+	mraList
+
+	peers   []peer.StatusPeer
+	added   int
+	removed int
+}
+
+func (l *rotatingList) Add(p peer.StatusPeer, pid peer.Identifier) peer.Subscriber {
+	l.added++
+	l.peers = append(l.peers, p)
+	return &mraSub{}
+}
+
+func (l *rotatingList) Remove(p peer.StatusPeer, pid peer.Identifier, ps peer.Subscriber) {
+	l.removed++
+	for i, q := range l.peers {
+		if q == p {
+			l.peers = append(l.peers[:i], l.peers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (l *rotatingList) Choose(ctx context.Context, req *transport.Request) peer.StatusPeer {
+	if len(l.peers) == 0 {
+		return nil
+	}
+	p := l.peers[0]
+	l.peers = append(l.peers[1:], p)
+	return p
+}
+
+func countChoices(t *testing.T, list *List, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		p, onFinish, err := list.Choose(context.Background(), &transport.Request{})
+		require.NoError(t, err)
+		onFinish(nil)
+		counts[p.Identifier()]++
+	}
+	return counts
+}
+
+func TestSlowStart(t *testing.T) {
+	fake := yarpctest.NewFakeTransport()
+	clk := clock.NewFake()
+	list := New("rotating", fake, &rotatingList{}, NoShuffle(), Seed(0),
+		SlowStart(time.Minute, LinearRamp), withClock(clk))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			hostport.Identify("1.1.1.1:1111"),
+			hostport.Identify("2.2.2.2:2222"),
+		},
+	}))
+
+	// Every available peer is warming, so none is slowed down.
+	counts := countChoices(t, list, 100)
+	assert.Equal(t, 50, counts["1.1.1.1:1111"])
+	assert.Equal(t, 50, counts["2.2.2.2:2222"])
+
+	clk.Add(time.Minute)
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{hostport.Identify("3.3.3.3:3333")},
+	}))
+
+	counts = countChoices(t, list, 300)
+	assert.Equal(t, 0, counts["3.3.3.3:3333"], "new peer must not be chosen when it becomes available")
+	assert.Equal(t, 300, counts["1.1.1.1:1111"]+counts["2.2.2.2:2222"])
+
+	clk.Add(30 * time.Second)
+	counts = countChoices(t, list, 3000)
+	// Halfway through the window, the new peer has half the weight of the
+	// others: 0.5 / 2.5 of the requests.
+	assert.InDelta(t, 600, counts["3.3.3.3:3333"], 100, "new peer must receive half its share halfway through the window")
+
+	clk.Add(30 * time.Second)
+	counts = countChoices(t, list, 300)
+	assert.Equal(t, 100, counts["3.3.3.3:3333"], "new peer must receive its share after the window")
+
+	// A peer that recovers warms up again.
+	fake.SimulateDisconnect(hostport.Identify("3.3.3.3:3333"))
+	fake.SimulateConnect(hostport.Identify("3.3.3.3:3333"))
+	counts = countChoices(t, list, 300)
+	assert.Equal(t, 0, counts["3.3.3.3:3333"], "recovered peer must not be chosen when it becomes available")
+}
+
+func TestSlowStartKeepsPeersInImplementation(t *testing.T) {
+	impl := &rotatingList{}
+	clk := clock.NewFake()
+	list := New("rotating", yarpctest.NewFakeTransport(), impl, NoShuffle(), Seed(0),
+		SlowStart(time.Minute, LinearRamp), withClock(clk))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{hostport.Identify("1.1.1.1:1111")},
+	}))
+	clk.Add(time.Minute)
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{hostport.Identify("2.2.2.2:2222")},
+	}))
+	require.Equal(t, 2, impl.added)
+
+	// Skipping the warming peer must not take it out of the implementation,
+	// which would reset the state the implementation keeps for it.
+	counts := countChoices(t, list, 100)
+	assert.Equal(t, 100, counts["1.1.1.1:1111"])
+	assert.Equal(t, 2, impl.added)
+	assert.Equal(t, 0, impl.removed)
+}
+
+func TestSlowStartRamps(t *testing.T) {
+	assert.Equal(t, 0.0, LinearRamp(0))
+	assert.Equal(t, 0.25, LinearRamp(0.25))
+	assert.Equal(t, 1.0, LinearRamp(1))
+
+	assert.InDelta(t, 0.001, ExponentialRamp(0), 0.0001)
+	assert.InDelta(t, 0.03125, ExponentialRamp(0.5), 0.0001)
+	assert.Equal(t, 1.0, ExponentialRamp(1))
+}
+
+func TestSlowStartConfig(t *testing.T) {
+	window, ramp, err := SlowStartConfig{Window: time.Second}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, window)
+	assert.Equal(t, 0.5, ramp(0.5))
+
+	_, ramp, err = SlowStartConfig{Window: time.Second, Ramp: "exponential"}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0/32, ramp(0.5))
+
+	_, _, err = SlowStartConfig{}.Build()
+	assert.Contains(t, err.Error(), "slow start window must be greater than 0. Got: 0s.")
+
+	_, _, err = SlowStartConfig{Window: time.Second, Ramp: "quadratic"}.Build()
+	assert.Contains(t, err.Error(), `slow start ramp must be "linear" or "exponential". Got: "quadratic".`)
+}
@@ -22,10 +22,12 @@ package pendingheap
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/peerlist/v2"
 	"go.uber.org/yarpc/yarpcconfig"
 	"go.uber.org/yarpc/yarpctest"
 )
@@ -60,6 +62,19 @@ func TestPendingHeapConfig(t *testing.T) {
 				Capacity: &twenty,
 			},
 		},
+		{
+			name: "slow start",
+			cfg: Configuration{
+				SlowStart: &peerlist.SlowStartConfig{Window: time.Minute, Ramp: "exponential"},
+			},
+		},
+		{
+			name: "invalid slow start",
+			cfg: Configuration{
+				SlowStart: &peerlist.SlowStartConfig{Window: time.Minute, Ramp: "quadratic"},
+			},
+			wantErr: true,
+		},
 	}
 
 	s := Spec()
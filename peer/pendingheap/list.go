@@ -24,15 +24,19 @@ import (
 	"math/rand"
 	"time"
 
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/peer/peerlist/v2"
 )
 
 type listConfig struct {
-	capacity int
-	shuffle  bool
-	seed     int64
-	nextRand func(int) int
+	capacity        int
+	shuffle         bool
+	seed            int64
+	slowStartWindow time.Duration
+	slowStartRamp   peerlist.SlowStartRamp
+	meter           *metrics.Scope
+	nextRand        func(int) int
 }
 
 var defaultListConfig = listConfig{
@@ -63,6 +67,26 @@ func Seed(seed int64) ListOption {
 	}
 }
 
+// SlowStart ramps up the share of requests the list sends to peers that
+// become available over the given window. See peerlist.SlowStart.
+func SlowStart(window time.Duration, ramp peerlist.SlowStartRamp) ListOption {
+	return func(c *listConfig) {
+		c.slowStartWindow = window
+		c.slowStartRamp = ramp
+	}
+}
+
+// Meter specifies a metrics scope for the list's metrics, like the number of
+// pending requests to each peer and the latency of choosing a peer. See
+// peerlist.Meter for all metrics.
+//
+// Metrics are disabled by default.
+func Meter(meter *metrics.Scope) ListOption {
+	return func(c *listConfig) {
+		c.meter = meter
+	}
+}
+
 // New creates a new pending heap.
 func New(transport peer.Transport, opts ...ListOption) *List {
 	cfg := defaultListConfig
@@ -76,6 +100,12 @@ func New(transport peer.Transport, opts ...ListOption) *List {
 	if !cfg.shuffle {
 		plOpts = append(plOpts, peerlist.NoShuffle())
 	}
+	if cfg.slowStartWindow > 0 {
+		plOpts = append(plOpts, peerlist.SlowStart(cfg.slowStartWindow, cfg.slowStartRamp))
+	}
+	if cfg.meter != nil {
+		plOpts = append(plOpts, peerlist.Meter(cfg.meter))
+	}
 
 	nextRandFn := nextRand(cfg.seed)
 	if cfg.nextRand != nil {
@@ -23,6 +23,7 @@ package randpeer
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/yarpc/yarpcconfig"
 	"go.uber.org/yarpc/yarpctest"
@@ -53,3 +54,37 @@ func TestConfig(t *testing.T) {
 	require.NotNil(t, config.Outbounds["their-service"])
 	require.NotNil(t, config.Outbounds["their-service"].Unary)
 }
+
+func TestConfigSlowStart(t *testing.T) {
+	cfg := yarpcconfig.New()
+	cfg.RegisterPeerList(Spec())
+	cfg.RegisterTransport(yarpctest.FakeTransportSpec())
+	_, err := cfg.LoadConfig("our-service", attrs{
+		"outbounds": attrs{
+			"their-service": attrs{
+				"fake-transport": attrs{
+					"random": attrs{
+						"peers":     []string{"1.1.1.1:1111"},
+						"slowStart": attrs{"window": "30s", "ramp": "exponential"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = cfg.LoadConfig("our-service", attrs{
+		"outbounds": attrs{
+			"their-service": attrs{
+				"fake-transport": attrs{
+					"random": attrs{
+						"peers":     []string{"1.1.1.1:1111"},
+						"slowStart": attrs{"window": "30s", "ramp": "quadratic"},
+					},
+				},
+			},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `slow start ramp must be "linear" or "exponential". Got: "quadratic".`)
+}
@@ -24,13 +24,17 @@ import (
 	"math/rand"
 	"time"
 
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/peer/peerlist/v2"
 )
 
 type listOptions struct {
-	capacity int
-	source   rand.Source
+	capacity        int
+	source          rand.Source
+	slowStartWindow time.Duration
+	slowStartRamp   peerlist.SlowStartRamp
+	meter           *metrics.Scope
 }
 
 var defaultListOptions = listOptions{
@@ -70,6 +74,26 @@ func Source(source rand.Source) ListOption {
 	})
 }
 
+// SlowStart ramps up the share of requests the list sends to peers that
+// become available over the given window. See peerlist.SlowStart.
+func SlowStart(window time.Duration, ramp peerlist.SlowStartRamp) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.slowStartWindow = window
+		options.slowStartRamp = ramp
+	})
+}
+
+// Meter specifies a metrics scope for the list's metrics, like the number of
+// pending requests to each peer and the latency of choosing a peer. See
+// peerlist.Meter for all metrics.
+//
+// Metrics are disabled by default.
+func Meter(meter *metrics.Scope) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.meter = meter
+	})
+}
+
 // New creates a new random peer list.
 func New(transport peer.Transport, opts ...ListOption) *List {
 	options := defaultListOptions
@@ -85,6 +109,12 @@ func New(transport peer.Transport, opts ...ListOption) *List {
 		peerlist.Capacity(options.capacity),
 		peerlist.NoShuffle(),
 	}
+	if options.slowStartWindow > 0 {
+		plOpts = append(plOpts, peerlist.SlowStart(options.slowStartWindow, options.slowStartRamp))
+	}
+	if options.meter != nil {
+		plOpts = append(plOpts, peerlist.Meter(options.meter))
+	}
 
 	return &List{
 		List: peerlist.New(
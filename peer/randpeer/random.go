@@ -32,6 +32,9 @@ import (
 type randomList struct {
 	subscribers []*subscriber
 	random      *rand.Rand
+
+	// Sum of the weights of all subscribers.
+	totalWeight int
 }
 
 func newRandomList(cap int, source rand.Source) *randomList {
@@ -41,22 +44,36 @@ func newRandomList(cap int, source rand.Source) *randomList {
 	}
 }
 
-var _ peerlist.Implementation = (*randomList)(nil)
+var _ peerlist.ChangeableImplementation = (*randomList)(nil)
 
-func (r *randomList) Add(peer peer.StatusPeer, _ peer.Identifier) peer.Subscriber {
+func (r *randomList) Add(p peer.StatusPeer, pid peer.Identifier) peer.Subscriber {
 	index := len(r.subscribers)
 	r.subscribers = append(r.subscribers, &subscriber{
-		index: index,
-		peer:  peer,
+		index:  index,
+		peer:   p,
+		weight: peer.WeightOf(pid),
 	})
+	r.totalWeight += r.subscribers[index].weight
 	return r.subscribers[index]
 }
 
+// Change updates the weight of the peer.
+func (r *randomList) Change(_ peer.StatusPeer, pid peer.Identifier, ps peer.Subscriber) {
+	sub, ok := ps.(*subscriber)
+	if !ok {
+		return
+	}
+	weight := peer.WeightOf(pid)
+	r.totalWeight += weight - sub.weight
+	sub.weight = weight
+}
+
 func (r *randomList) Remove(peer peer.StatusPeer, _ peer.Identifier, ps peer.Subscriber) {
 	sub, ok := ps.(*subscriber)
 	if !ok || len(r.subscribers) == 0 {
 		return
 	}
+	r.totalWeight -= sub.weight
 	index := sub.index
 	last := len(r.subscribers) - 1
 	r.subscribers[index] = r.subscribers[last]
@@ -68,8 +85,20 @@ func (r *randomList) Choose(_ context.Context, _ *transport.Request) peer.Status
 	if len(r.subscribers) == 0 {
 		return nil
 	}
-	index := r.random.Intn(len(r.subscribers))
-	return r.subscribers[index].peer
+	if r.totalWeight == len(r.subscribers) {
+		// All peers have the default weight.
+		index := r.random.Intn(len(r.subscribers))
+		return r.subscribers[index].peer
+	}
+
+	n := r.random.Intn(r.totalWeight)
+	for _, sub := range r.subscribers {
+		if n < sub.weight {
+			return sub.peer
+		}
+		n -= sub.weight
+	}
+	return r.subscribers[len(r.subscribers)-1].peer
 }
 
 func (r *randomList) Start() error {
@@ -85,8 +114,9 @@ func (r *randomList) IsRunning() bool {
 }
 
 type subscriber struct {
-	index int
-	peer  peer.StatusPeer
+	index  int
+	peer   peer.StatusPeer
+	weight int
 }
 
 var _ peer.Subscriber = (*subscriber)(nil)
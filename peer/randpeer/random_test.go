@@ -0,0 +1,66 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package randpeer
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+func TestRandomListWeights(t *testing.T) {
+	list := newRandomList(3, rand.NewSource(0))
+
+	add := func(addr string, weight int) peer.Subscriber {
+		pid := hostport.IdentifyWithAttributes(addr, hostport.PeerAttributes{Weight: weight})
+		return list.Add(hostport.NewPeer(hostport.PeerIdentifier(addr), nil), pid)
+	}
+	counts := func() map[string]int {
+		counts := make(map[string]int)
+		for i := 0; i < 10000; i++ {
+			counts[list.Choose(context.Background(), &transport.Request{}).Identifier()]++
+		}
+		return counts
+	}
+
+	add("1.1.1.1:80", 0)
+	sub := add("2.2.2.2:80", 3)
+	removed := add("3.3.3.3:80", 4)
+	assert.Equal(t, 8, list.totalWeight)
+
+	list.Remove(nil, nil, removed)
+	assert.Equal(t, 4, list.totalWeight)
+	c := counts()
+	assert.InDelta(t, 2500, c["1.1.1.1:80"], 300)
+	assert.InDelta(t, 7500, c["2.2.2.2:80"], 300)
+
+	// Changing the weight to the default restores uniform choices.
+	list.Change(nil, hostport.PeerIdentifier("2.2.2.2:80"), sub)
+	assert.Equal(t, 2, list.totalWeight)
+	c = counts()
+	assert.InDelta(t, 5000, c["1.1.1.1:80"], 300)
+	assert.InDelta(t, 5000, c["2.2.2.2:80"], 300)
+}
@@ -21,16 +21,17 @@
 package roundrobin
 
 import (
-	"fmt"
-
 	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/peerlist/v2"
 	"go.uber.org/yarpc/yarpcconfig"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // Configuration descripes how to build a round-robin peer list.
 type Configuration struct {
-	Capacity *int `config:"capacity"`
+	Capacity  *int                      `config:"capacity"`
+	SlowStart *peerlist.SlowStartConfig `config:"slowStart"`
+	Metrics   bool                      `config:"metrics"`
 }
 
 // Spec returns a configuration specification for the round-robin peer list
@@ -51,20 +52,51 @@ type Configuration struct {
 //            peers:
 //              - 127.0.0.1:8080
 //              - 127.0.0.1:8081
+//
+// Peers may be ramped up when they become available with slow start, over a
+// window and with a "linear" (default) or "exponential" ramp:
+//
+//          round-robin:
+//            peers:
+//              - 127.0.0.1:8080
+//              - 127.0.0.1:8081
+//            slowStart:
+//              window: 30s
+//              ramp: exponential
+//
+// Setting metrics to true reports the list's metrics to the metrics scope
+// given to the Configurator with yarpcconfig.Metrics, tagged with the
+// outbound. Enabling metrics without such a scope is an error.
 func Spec() yarpcconfig.PeerListSpec {
 	return yarpcconfig.PeerListSpec{
 		Name: "round-robin",
 		BuildPeerList: func(cfg Configuration, t peer.Transport, k *yarpcconfig.Kit) (peer.ChooserList, error) {
-			if cfg.Capacity == nil {
-				return New(t), nil
+			var opts []ListOption
+			if cfg.Capacity != nil {
+				if *cfg.Capacity <= 0 {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						"Capacity must be greater than 0. Got: %d.", *cfg.Capacity)
+				}
+				opts = append(opts, Capacity(*cfg.Capacity))
+			}
+
+			if cfg.SlowStart != nil {
+				window, ramp, err := cfg.SlowStart.Build()
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, SlowStart(window, ramp))
 			}
 
-			if *cfg.Capacity <= 0 {
-				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
-					fmt.Sprintf("Capacity must be greater than 0. Got: %d.", *cfg.Capacity))
+			if cfg.Metrics {
+				meter, err := k.RequireMeter()
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, Meter(meter))
 			}
 
-			return New(t, Capacity(*cfg.Capacity)), nil
+			return New(t, opts...), nil
 		},
 	}
 }
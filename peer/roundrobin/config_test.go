@@ -22,10 +22,14 @@ package roundrobin
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/peerlist/v2"
 	"go.uber.org/yarpc/yarpcconfig"
 	"go.uber.org/yarpc/yarpctest"
 )
@@ -60,6 +64,19 @@ func TestPendingHeapConfig(t *testing.T) {
 				Capacity: &twenty,
 			},
 		},
+		{
+			name: "slow start",
+			cfg: Configuration{
+				SlowStart: &peerlist.SlowStartConfig{Window: time.Minute, Ramp: "exponential"},
+			},
+		},
+		{
+			name: "invalid slow start",
+			cfg: Configuration{
+				SlowStart: &peerlist.SlowStartConfig{Window: time.Minute, Ramp: "quadratic"},
+			},
+			wantErr: true,
+		},
 	}
 
 	s := Spec()
@@ -78,3 +95,62 @@ func TestPendingHeapConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigMetrics(t *testing.T) {
+	root := metrics.New()
+	cfg := yarpcconfig.New(yarpcconfig.Metrics(root.Scope()))
+	require.NoError(t, cfg.RegisterPeerList(Spec()))
+	require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+	config, err := cfg.LoadConfig("our-service", map[string]interface{}{
+		"outbounds": map[string]interface{}{
+			"their-service": map[string]interface{}{
+				"fake-transport": map[string]interface{}{
+					"round-robin": map[string]interface{}{
+						"metrics": true,
+						"peers":   []string{"1.1.1.1:1111"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	chooser := config.Outbounds["their-service"].Unary.(*yarpctest.FakeOutbound).Chooser()
+	require.NoError(t, chooser.Start())
+	defer chooser.Stop()
+
+	var found bool
+	for _, g := range root.Snapshot().Gauges {
+		// The fake transport builds unary, oneway and stream outbounds.
+		if g.Name == "peer_list_available_peers" && g.Tags["rpc_type"] == "unary" {
+			found = true
+			assert.Equal(t, int64(1), g.Value)
+			assert.Equal(t, metrics.Tags{
+				"outbound":  "their-service",
+				"rpc_type":  "unary",
+				"peer_list": "roundrobin",
+			}, g.Tags)
+		}
+	}
+	assert.True(t, found, "must report available peers")
+}
+
+func TestConfigMetricsWithoutScope(t *testing.T) {
+	cfg := yarpcconfig.New()
+	require.NoError(t, cfg.RegisterPeerList(Spec()))
+	require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+	_, err := cfg.LoadConfig("our-service", map[string]interface{}{
+		"outbounds": map[string]interface{}{
+			"their-service": map[string]interface{}{
+				"fake-transport": map[string]interface{}{
+					"round-robin": map[string]interface{}{
+						"metrics": true,
+						"peers":   []string{"1.1.1.1:1111"},
+					},
+				},
+			},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Metrics require a metrics scope")
+}
@@ -23,14 +23,18 @@ package roundrobin
 import (
 	"time"
 
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/peer/peerlist/v2"
 )
 
 type listConfig struct {
-	capacity int
-	shuffle  bool
-	seed     int64
+	capacity        int
+	shuffle         bool
+	seed            int64
+	slowStartWindow time.Duration
+	slowStartRamp   peerlist.SlowStartRamp
+	meter           *metrics.Scope
 }
 
 var defaultListConfig = listConfig{
@@ -52,6 +56,26 @@ func Capacity(capacity int) ListOption {
 	}
 }
 
+// SlowStart ramps up the share of requests the list sends to peers that
+// become available over the given window. See peerlist.SlowStart.
+func SlowStart(window time.Duration, ramp peerlist.SlowStartRamp) ListOption {
+	return func(c *listConfig) {
+		c.slowStartWindow = window
+		c.slowStartRamp = ramp
+	}
+}
+
+// Meter specifies a metrics scope for the list's metrics, like the number of
+// pending requests to each peer and the latency of choosing a peer. See
+// peerlist.Meter for all metrics.
+//
+// Metrics are disabled by default.
+func Meter(meter *metrics.Scope) ListOption {
+	return func(c *listConfig) {
+		c.meter = meter
+	}
+}
+
 // New creates a new round robin peer list.
 func New(transport peer.Transport, opts ...ListOption) *List {
 	cfg := defaultListConfig
@@ -66,6 +90,12 @@ func New(transport peer.Transport, opts ...ListOption) *List {
 	if !cfg.shuffle {
 		plOpts = append(plOpts, peerlist.NoShuffle())
 	}
+	if cfg.slowStartWindow > 0 {
+		plOpts = append(plOpts, peerlist.SlowStart(cfg.slowStartWindow, cfg.slowStartRamp))
+	}
+	if cfg.meter != nil {
+		plOpts = append(plOpts, peerlist.Meter(cfg.meter))
+	}
 
 	return &List{
 		List: peerlist.New(
@@ -0,0 +1,89 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package subset
+
+import (
+	"fmt"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+)
+
+// Configuration describes how to build a subset of the peers from another
+// peer list updater.
+type Configuration struct {
+	// Maximum number of peers in the subset.
+	Size int `config:"size"`
+
+	// Identity of this client. Defaults to a random ID.
+	ClientID string `config:"clientID,interpolate"`
+
+	// Static peers or the configuration of another peer list updater,
+	// providing the peers to choose the subset from.
+	Updater map[string]interface{} `config:",squash"`
+}
+
+// Spec returns a configuration specification for subsets of peers, making it
+// possible to connect to a subset of the peers from another peer list
+// updater with transports that use outbound peer list configuration (like
+// HTTP).
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerListUpdater(subset.Spec())
+//
+// This enables the subset peer list updater, which wraps static peers or
+// another peer list updater:
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: http://host/rpc
+//          round-robin:
+//            subset:
+//              size: 20
+//              clientID: ${HOSTNAME}
+//              dns:
+//                name: otherservice.example.com
+//                port: 8080
+func Spec() yarpcconfig.PeerListUpdaterSpec {
+	return yarpcconfig.PeerListUpdaterSpec{
+		Name:                 "subset",
+		BuildPeerListUpdater: buildPeerListUpdater,
+	}
+}
+
+func buildPeerListUpdater(c Configuration, kit *yarpcconfig.Kit) (peer.Binder, error) {
+	if c.Size <= 0 {
+		return nil, fmt.Errorf("subset peer list updater config requires a positive size, got %d", c.Size)
+	}
+
+	binder, err := kit.BuildPeerListUpdater(c.Updater, nil)
+	if err != nil {
+		return nil, fmt.Errorf("subset peer list updater config is invalid: %v", err)
+	}
+
+	var opts []Option
+	if c.ClientID != "" {
+		opts = append(opts, ClientID(c.ClientID))
+	}
+	return NewBinder(binder, c.Size, opts...), nil
+}
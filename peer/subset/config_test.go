@@ -0,0 +1,102 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package subset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+type attrs map[string]interface{}
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		desc    string
+		give    attrs
+		wantErr string
+	}{
+		{
+			desc: "static peers",
+			give: attrs{
+				"size":     2,
+				"clientID": "me",
+				"peers":    []string{"1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80"},
+			},
+		},
+		{
+			desc: "peer list updater",
+			give: attrs{
+				"size":         2,
+				"fake-updater": attrs{"watch": true},
+			},
+		},
+		{
+			desc:    "missing size",
+			give:    attrs{"peers": []string{"1.1.1.1:80"}},
+			wantErr: "subset peer list updater config requires a positive size, got 0",
+		},
+		{
+			desc:    "missing updater",
+			give:    attrs{"size": 2},
+			wantErr: "no recognized peer list updater in config",
+		},
+		{
+			desc: "unrecognized attributes",
+			give: attrs{
+				"size":  2,
+				"peers": []string{"1.1.1.1:80"},
+				"foo":   "bar",
+			},
+			wantErr: "unrecognized attributes in peer list updater config: foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := yarpcconfig.New()
+			require.NoError(t, cfg.RegisterPeerListUpdater(Spec()))
+			require.NoError(t, cfg.RegisterPeerListUpdater(yarpctest.FakePeerListUpdaterSpec()))
+			require.NoError(t, cfg.RegisterPeerList(roundrobin.Spec()))
+			require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+			config, err := cfg.LoadConfig("our-service", attrs{
+				"outbounds": attrs{
+					"their-service": attrs{
+						"fake-transport": attrs{
+							"round-robin": attrs{"subset": tt.give},
+						},
+					},
+				},
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, config.Outbounds["their-service"].Unary)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package subset limits the peers a peer list connects to to a stable subset
+// of the peers from its peer list updater.
+//
+// When many clients send requests to many servers, connecting every client
+// to every server costs each server a connection per client. A subset sits
+// between a peer list updater and a peer list, passing on only a fixed number
+// of the peers it receives. Peers are chosen by rendezvous hashing: each
+// peer is ranked by a hash of the client ID and its identifier, and the
+// highest ranked peers form the subset. Different clients rank peers
+// differently, spreading clients evenly across servers, and adding or
+// removing a peer changes at most one peer of each subset.
+//
+// 	list := roundrobin.New(transport)
+// 	chooser := peer.Bind(list, subset.NewBinder(dns.NewBinder(name, dns.Port(8080)), 20))
+//
+// See Spec for configuring subsets with yarpcconfig.
+package subset
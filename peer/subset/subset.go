@@ -0,0 +1,207 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package subset
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/peerdiff"
+)
+
+type options struct {
+	clientID string
+}
+
+// Option customizes the behavior of a subset.
+type Option func(*options)
+
+// ClientID specifies the identity of this client, which determines the
+// peers in its subset. Clients with the same ID choose the same subset of
+// the same peers, so each client should have a distinct, stable ID, like its
+// host name.
+//
+// Defaults to a random ID.
+func ClientID(id string) Option {
+	return func(o *options) {
+		o.clientID = id
+	}
+}
+
+// NewBinder wraps a peer list updater binder so that the peer list receives
+// a subset of at most size of the peers from the updater.
+func NewBinder(binder peer.Binder, size int, opts ...Option) peer.Binder {
+	return func(pl peer.List) transport.Lifecycle {
+		return binder(New(pl, size, opts...))
+	}
+}
+
+// New creates a subset that updates the given peer list with at most size
+// of the peers it receives. If size is not positive, all peers are passed
+// on.
+func New(pl peer.List, size int, opts ...Option) *List {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.clientID == "" {
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		options.clientID = strconv.FormatUint(r.Uint64(), 36)
+	}
+
+	return &List{
+		list:     pl,
+		size:     size,
+		clientID: options.clientID,
+		peers:    make(map[string]peer.Identifier),
+		subset:   make(map[string]peer.Identifier),
+	}
+}
+
+// List is a peer list that passes a subset of its peers on to another peer
+// list.
+type List struct {
+	list     peer.List
+	size     int
+	clientID string
+
+	lock sync.Mutex
+	// All peers, and the subset of them in the underlying list, by
+	// identifier.
+	peers  map[string]peer.Identifier
+	subset map[string]peer.Identifier
+}
+
+var (
+	_ peer.List            = (*List)(nil)
+	_ peer.ReadinessWaiter = (*List)(nil)
+)
+
+// Update updates the peers in the subset, updating the underlying peer list
+// with the peers that enter or leave the subset.
+func (l *List) Update(updates peer.ListUpdates) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var err error
+	for _, pid := range updates.Removals {
+		if _, ok := l.peers[pid.Identifier()]; !ok {
+			err = multierr.Append(err, peer.ErrPeerRemoveNotInList(pid.Identifier()))
+			continue
+		}
+		delete(l.peers, pid.Identifier())
+	}
+	for _, pid := range updates.Additions {
+		if _, ok := l.peers[pid.Identifier()]; ok {
+			err = multierr.Append(err, peer.ErrPeerAddAlreadyInList(pid.Identifier()))
+			continue
+		}
+		l.peers[pid.Identifier()] = pid
+	}
+	for _, pid := range updates.Changes {
+		if _, ok := l.peers[pid.Identifier()]; !ok {
+			err = multierr.Append(err, peer.ErrPeerChangeNotInList(pid.Identifier()))
+			continue
+		}
+		l.peers[pid.Identifier()] = pid
+	}
+
+	subset := l.choose()
+	if diff := peerdiff.Diff(l.subset, subset); !peerdiff.IsEmpty(diff) {
+		err = multierr.Append(err, l.list.Update(diff))
+	}
+	l.subset = subset
+	return err
+}
+
+// WaitUntilReady waits until the underlying peer list is ready, counting only
+// the peers in the subset, if the peer list supports readiness. Peer lists
+// that do not are considered ready.
+func (l *List) WaitUntilReady(ctx context.Context, r peer.Readiness) error {
+	if w, ok := l.list.(peer.ReadinessWaiter); ok {
+		return w.WaitUntilReady(ctx, r)
+	}
+	return nil
+}
+
+type rankedPeer struct {
+	rank uint64
+	pid  peer.Identifier
+}
+
+// choose returns the subset of peers with the highest rank.
+// Must be called under the lock.
+func (l *List) choose() map[string]peer.Identifier {
+	if l.size <= 0 || len(l.peers) <= l.size {
+		subset := make(map[string]peer.Identifier, len(l.peers))
+		for id, pid := range l.peers {
+			subset[id] = pid
+		}
+		return subset
+	}
+
+	ranked := make([]rankedPeer, 0, len(l.peers))
+	for id, pid := range l.peers {
+		ranked = append(ranked, rankedPeer{rank: rank(l.clientID, id), pid: pid})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank > ranked[j].rank
+		}
+		return ranked[i].pid.Identifier() < ranked[j].pid.Identifier()
+	})
+
+	subset := make(map[string]peer.Identifier, l.size)
+	for _, p := range ranked[:l.size] {
+		subset[p.pid.Identifier()] = p.pid
+	}
+	return subset
+}
+
+// rank returns the rendezvous hash of a peer for a client.
+func rank(clientID, id string) uint64 {
+	h := fnv.New64a()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(clientID)))
+	h.Write(n[:])
+	h.Write([]byte(clientID))
+	h.Write([]byte(id))
+	return mix(h.Sum64())
+}
+
+// mix finalizes a hash so that similar inputs produce unrelated outputs.
+func mix(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
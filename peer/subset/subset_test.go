@@ -0,0 +1,223 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package subset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+// fakeList is a peer list that records its peers.
+type fakeList struct {
+	peers   map[string]peer.Identifier
+	updates []peer.ListUpdates
+	err     error
+}
+
+func newFakeList() *fakeList {
+	return &fakeList{peers: make(map[string]peer.Identifier)}
+}
+
+func (l *fakeList) Update(updates peer.ListUpdates) error {
+	l.updates = append(l.updates, updates)
+	for _, pid := range updates.Removals {
+		delete(l.peers, pid.Identifier())
+	}
+	for _, pid := range updates.Additions {
+		l.peers[pid.Identifier()] = pid
+	}
+	for _, pid := range updates.Changes {
+		l.peers[pid.Identifier()] = pid
+	}
+	return l.err
+}
+
+// waitingList is a fakeList that supports readiness.
+type waitingList struct {
+	*fakeList
+
+	err error
+}
+
+func (l *waitingList) WaitUntilReady(context.Context, peer.Readiness) error {
+	return l.err
+}
+
+func pids(n int) []peer.Identifier {
+	out := make([]peer.Identifier, n)
+	for i := range out {
+		out[i] = hostport.PeerIdentifier(fmt.Sprintf("10.0.0.%d:80", i))
+	}
+	return out
+}
+
+func TestSubsetSize(t *testing.T) {
+	pl := newFakeList()
+	l := New(pl, 3, ClientID("me"))
+
+	require.NoError(t, l.Update(peer.ListUpdates{Additions: pids(2)}))
+	assert.Len(t, pl.peers, 2, "all peers must be passed on while there are few")
+
+	require.NoError(t, l.Update(peer.ListUpdates{Additions: pids(10)[2:]}))
+	assert.Len(t, pl.peers, 3)
+
+	unlimited := newFakeList()
+	require.NoError(t, New(unlimited, 0).Update(peer.ListUpdates{Additions: pids(10)}))
+	assert.Len(t, unlimited.peers, 10, "all peers must be passed on without a size")
+}
+
+func TestSubsetDeterministic(t *testing.T) {
+	subset := func(clientID string, peers []peer.Identifier) map[string]peer.Identifier {
+		pl := newFakeList()
+		require.NoError(t, New(pl, 5, ClientID(clientID)).Update(peer.ListUpdates{Additions: peers}))
+		return pl.peers
+	}
+
+	peers := pids(50)
+	reversed := make([]peer.Identifier, len(peers))
+	for i, pid := range peers {
+		reversed[len(peers)-1-i] = pid
+	}
+	assert.Equal(t, subset("a", peers), subset("a", reversed),
+		"the subset must not depend on the order of peers")
+	assert.NotEqual(t, subset("a", peers), subset("b", peers),
+		"different clients must choose different subsets")
+}
+
+func TestSubsetChurn(t *testing.T) {
+	pl := newFakeList()
+	l := New(pl, 10, ClientID("me"))
+	peers := pids(100)
+	require.NoError(t, l.Update(peer.ListUpdates{Additions: peers[:50]}))
+	before := len(pl.updates)
+
+	// Adding peers replaces at most one member of the subset each.
+	for _, pid := range peers[50:] {
+		require.NoError(t, l.Update(peer.ListUpdates{Additions: []peer.Identifier{pid}}))
+	}
+	for _, u := range pl.updates[before:] {
+		assert.True(t, len(u.Additions) == 1 && len(u.Removals) == 1,
+			"unexpected update: %+v", u)
+	}
+
+	// Removing a peer outside the subset does not change it, and removing a
+	// peer in the subset replaces only it.
+	for _, pid := range peers {
+		before := len(pl.updates)
+		_, inSubset := pl.peers[pid.Identifier()]
+		require.NoError(t, l.Update(peer.ListUpdates{Removals: []peer.Identifier{pid}}))
+		if !inSubset {
+			assert.Len(t, pl.updates, before)
+			continue
+		}
+		require.Len(t, pl.updates, before+1)
+		u := pl.updates[before]
+		assert.Equal(t, []peer.Identifier{pid}, u.Removals)
+		assert.True(t, len(u.Additions) <= 1, "unexpected update: %+v", u)
+	}
+	assert.Empty(t, pl.peers)
+}
+
+func TestSubsetDistribution(t *testing.T) {
+	const clients, servers, size = 200, 50, 10
+
+	peers := pids(servers)
+	load := make(map[string]int)
+	for c := 0; c < clients; c++ {
+		pl := newFakeList()
+		require.NoError(t, New(pl, size, ClientID(fmt.Sprintf("client-%d", c))).Update(
+			peer.ListUpdates{Additions: peers}))
+		for id := range pl.peers {
+			load[id]++
+		}
+	}
+
+	// Each server expects clients*size/servers = 40 clients.
+	require.Len(t, load, servers, "every server must have clients")
+	for id, n := range load {
+		assert.True(t, n >= 15 && n <= 70, "server %v has %v clients", id, n)
+	}
+}
+
+func TestSubsetChanges(t *testing.T) {
+	pl := newFakeList()
+	l := New(pl, 1, ClientID("me"))
+	require.NoError(t, l.Update(peer.ListUpdates{Additions: pids(2)}))
+	require.Len(t, pl.peers, 1)
+
+	var member, other string
+	for _, pid := range pids(2) {
+		if _, ok := pl.peers[pid.Identifier()]; ok {
+			member = pid.Identifier()
+		} else {
+			other = pid.Identifier()
+		}
+	}
+
+	before := len(pl.updates)
+	require.NoError(t, l.Update(peer.ListUpdates{
+		Changes: []peer.Identifier{
+			hostport.IdentifyWithAttributes(other, hostport.PeerAttributes{Weight: 2}),
+		},
+	}))
+	assert.Len(t, pl.updates, before, "changes outside the subset must not be passed on")
+
+	changed := hostport.IdentifyWithAttributes(member, hostport.PeerAttributes{Weight: 2})
+	require.NoError(t, l.Update(peer.ListUpdates{Changes: []peer.Identifier{changed}}))
+	require.Len(t, pl.updates, before+1)
+	assert.Equal(t, peer.ListUpdates{Changes: []peer.Identifier{changed}}, pl.updates[before])
+}
+
+func TestSubsetErrors(t *testing.T) {
+	pl := newFakeList()
+	l := New(pl, 1)
+	require.NoError(t, l.Update(peer.ListUpdates{Additions: pids(1)}))
+
+	err := l.Update(peer.ListUpdates{
+		Additions: pids(1),
+		Removals:  []peer.Identifier{hostport.PeerIdentifier("1.1.1.1:80")},
+		Changes:   []peer.Identifier{hostport.PeerIdentifier("2.2.2.2:80")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), peer.ErrPeerAddAlreadyInList("10.0.0.0:80").Error())
+	assert.Contains(t, err.Error(), peer.ErrPeerRemoveNotInList("1.1.1.1:80").Error())
+	assert.Contains(t, err.Error(), peer.ErrPeerChangeNotInList("2.2.2.2:80").Error())
+
+	pl.err = errors.New("great sadness")
+	assert.EqualError(t, l.Update(peer.ListUpdates{Removals: pids(1)}), "great sadness")
+	assert.Empty(t, pl.peers)
+}
+
+func TestSubsetWaitUntilReady(t *testing.T) {
+	assert.NoError(t, New(newFakeList(), 3).WaitUntilReady(context.Background(), peer.Readiness{}),
+		"peer lists without readiness must be considered ready")
+
+	err := errors.New("not ready")
+	pl := &waitingList{fakeList: newFakeList(), err: err}
+	assert.Equal(t, err, New(pl, 3).WaitUntilReady(context.Background(), peer.Readiness{}))
+}
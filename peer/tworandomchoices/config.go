@@ -22,9 +22,16 @@ package tworandomchoices
 
 import (
 	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/peer/peerlist/v2"
 	"go.uber.org/yarpc/yarpcconfig"
 )
 
+// Configuration describes how to build a two-random-choices peer list.
+type Configuration struct {
+	SlowStart *peerlist.SlowStartConfig `config:"slowStart"`
+	Metrics   bool                      `config:"metrics"`
+}
+
 // Spec returns a configuration specification for the "fewest pending requests
 // of two random peers" implementation, making it possible to select the better
 // of two random peer with transports that use outbound peer list configuration
@@ -44,11 +51,43 @@ import (
 //            peers:
 //              - 127.0.0.1:8080
 //              - 127.0.0.1:8081
+//
+// Peers may be ramped up when they become available with slow start, over a
+// window and with a "linear" (default) or "exponential" ramp:
+//
+//          two-random-choices:
+//            peers:
+//              - 127.0.0.1:8080
+//              - 127.0.0.1:8081
+//            slowStart:
+//              window: 30s
+//              ramp: exponential
+//
+// Setting metrics to true reports the list's metrics to the metrics scope
+// given to the Configurator with yarpcconfig.Metrics, tagged with the
+// outbound. Enabling metrics without such a scope is an error.
 func Spec() yarpcconfig.PeerListSpec {
 	return yarpcconfig.PeerListSpec{
 		Name: "two-random-choices",
-		BuildPeerList: func(c struct{}, t peer.Transport, k *yarpcconfig.Kit) (peer.ChooserList, error) {
-			return New(t), nil
+		BuildPeerList: func(cfg Configuration, t peer.Transport, k *yarpcconfig.Kit) (peer.ChooserList, error) {
+			var opts []ListOption
+			if cfg.SlowStart != nil {
+				window, ramp, err := cfg.SlowStart.Build()
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, SlowStart(window, ramp))
+			}
+
+			if cfg.Metrics {
+				meter, err := k.RequireMeter()
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, Meter(meter))
+			}
+
+			return New(t, opts...), nil
 		},
 	}
 }
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+)
+
+var (
+	_typeOfKit      = reflect.TypeOf((*yarpcconfig.Kit)(nil))
+	_typeOfWatcher  = reflect.TypeOf((*peer.Watcher)(nil)).Elem()
+	_typeOfBinder   = reflect.TypeOf((*peer.Binder)(nil)).Elem()
+	_typeOfError    = reflect.TypeOf((*error)(nil)).Elem()
+	_typeOfDuration = reflect.TypeOf(time.Duration(0))
+	_typeOfBool     = reflect.TypeOf(false)
+)
+
+// WatcherSpec specifies how to build a peer.Watcher from configuration.
+type WatcherSpec struct {
+	// Name of the peer list updater in configuration.
+	Name string
+
+	// BuildWatcher is a function in the shape,
+	//
+	//  func(C, *yarpcconfig.Kit) (peer.Watcher, error)
+	//
+	// Where C is a struct or pointer to a struct describing the
+	// configuration of the watcher.
+	BuildWatcher interface{}
+}
+
+// Spec returns a configuration specification for a peer list updater that
+// keeps peer lists up to date with the watcher built by the given
+// WatcherSpec. The given options apply to every updater built from
+// configuration.
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerListUpdater(watch.Spec(watch.WatcherSpec{
+//    Name:         "consul",
+//    BuildWatcher: buildConsulWatcher,
+//  }))
+//
+// Besides the attributes of the watcher's configuration, the updater accepts
+// the following attributes, which must not conflict with those of the
+// watcher.
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: http://host/rpc
+//          round-robin:
+//            consul:
+//              service: otherservice
+//              debounce: 500ms
+//              initialSyncTimeout: 2s
+//              allowEmpty: false
+//
+// Spec panics if BuildWatcher does not have the expected signature.
+func Spec(spec WatcherSpec, opts ...Option) yarpcconfig.PeerListUpdaterSpec {
+	build := reflect.ValueOf(spec.BuildWatcher)
+	if err := validateBuildWatcher(build); err != nil {
+		panic(fmt.Sprintf("invalid BuildWatcher for %q: %v", spec.Name, err))
+	}
+
+	watcherConfig := build.Type().In(0)
+	squashed := watcherConfig
+	if squashed.Kind() == reflect.Ptr {
+		squashed = squashed.Elem()
+	}
+	configType := reflect.StructOf([]reflect.StructField{
+		{Name: "Debounce", Type: _typeOfDuration, Tag: `config:"debounce"`},
+		{Name: "InitialSyncTimeout", Type: reflect.PtrTo(_typeOfDuration), Tag: `config:"initialSyncTimeout"`},
+		{Name: "AllowEmpty", Type: _typeOfBool, Tag: `config:"allowEmpty"`},
+		{Name: "Watcher", Type: squashed, Tag: `config:",squash"`},
+	})
+
+	buildUpdater := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{configType, _typeOfKit}, []reflect.Type{_typeOfBinder, _typeOfError}, false),
+		func(args []reflect.Value) []reflect.Value {
+			binder, err := buildPeerListUpdater(spec.Name, build, args[0], args[1], opts)
+			return []reflect.Value{reflect.ValueOf(&binder).Elem(), reflect.ValueOf(&err).Elem()}
+		},
+	)
+
+	return yarpcconfig.PeerListUpdaterSpec{
+		Name:                 spec.Name,
+		BuildPeerListUpdater: buildUpdater.Interface(),
+	}
+}
+
+func validateBuildWatcher(v reflect.Value) error {
+	if !v.IsValid() || v.Kind() != reflect.Func {
+		return fmt.Errorf("must be a function, found %v", v.Kind())
+	}
+
+	t := v.Type()
+	switch {
+	case t.NumIn() != 2:
+		return fmt.Errorf("must accept exactly two arguments, found %v", t.NumIn())
+	case !isStruct(t.In(0)):
+		return fmt.Errorf("must accept a struct or struct pointer as its first argument, found %v", t.In(0))
+	case t.In(1) != _typeOfKit:
+		return fmt.Errorf("must accept a %v as its second argument, found %v", _typeOfKit, t.In(1))
+	case t.NumOut() != 2:
+		return fmt.Errorf("must return exactly two results, found %v", t.NumOut())
+	case t.Out(0) != _typeOfWatcher:
+		return fmt.Errorf("must return a peer.Watcher as its first result, found %v", t.Out(0))
+	case t.Out(1) != _typeOfError:
+		return fmt.Errorf("must return an error as its second result, found %v", t.Out(1))
+	}
+	return nil
+}
+
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)
+}
+
+func buildPeerListUpdater(name string, build, c, kit reflect.Value, opts []Option) (peer.Binder, error) {
+	// Don't modify the caller's options.
+	opts = opts[:len(opts):len(opts)]
+
+	debounce := c.FieldByName("Debounce").Interface().(time.Duration)
+	if debounce < 0 {
+		return nil, fmt.Errorf("%v peer list updater config debounce must not be negative", name)
+	}
+	if debounce > 0 {
+		opts = append(opts, Debounce(debounce))
+	}
+
+	if timeout := c.FieldByName("InitialSyncTimeout").Interface().(*time.Duration); timeout != nil {
+		if *timeout < 0 {
+			return nil, fmt.Errorf("%v peer list updater config initialSyncTimeout must not be negative", name)
+		}
+		opts = append(opts, InitialSyncTimeout(*timeout))
+	}
+
+	if c.FieldByName("AllowEmpty").Bool() {
+		opts = append(opts, AllowEmpty())
+	}
+
+	watcherConfig := c.FieldByName("Watcher")
+	if build.Type().In(0).Kind() == reflect.Ptr {
+		ptr := reflect.New(watcherConfig.Type())
+		ptr.Elem().Set(watcherConfig)
+		watcherConfig = ptr
+	}
+
+	results := build.Call([]reflect.Value{watcherConfig, kit})
+	if err, _ := results[1].Interface().(error); err != nil {
+		return nil, err
+	}
+	watcher, _ := results[0].Interface().(peer.Watcher)
+	return NewBinder(watcher, opts...), nil
+}
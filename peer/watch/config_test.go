@@ -0,0 +1,185 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package watch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/peer"
+	yarpcpeer "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+type testWatcherConfig struct {
+	Service string `config:"service"`
+}
+
+func TestSpec(t *testing.T) {
+	watchers := map[string]*MemoryWatcher{"their-service": NewMemoryWatcher()}
+	watchers["their-service"].Set(a, b)
+
+	spec := Spec(WatcherSpec{
+		Name: "memory",
+		BuildWatcher: func(c testWatcherConfig, kit *yarpcconfig.Kit) (peer.Watcher, error) {
+			w, ok := watchers[c.Service]
+			if !ok {
+				return nil, errors.New("unknown service")
+			}
+			return w, nil
+		},
+	})
+	assert.Equal(t, "memory", spec.Name)
+
+	cfg := yarpcconfig.New()
+	require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+	require.NoError(t, cfg.RegisterPeerList(roundrobin.Spec()))
+	require.NoError(t, cfg.RegisterPeerListUpdater(spec))
+
+	load := func(attrs map[string]interface{}) (yarpc.Outbounds, error) {
+		config, err := cfg.LoadConfig("our-service", map[string]interface{}{
+			"outbounds": map[string]interface{}{
+				"their-service": map[string]interface{}{
+					"unary": map[string]interface{}{
+						"fake-transport": map[string]interface{}{
+							"round-robin": map[string]interface{}{
+								"memory": attrs,
+							},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return yarpc.Outbounds{}, err
+		}
+		return config.Outbounds, nil
+	}
+
+	t.Run("peers", func(t *testing.T) {
+		outbounds, err := load(map[string]interface{}{
+			"service":            "their-service",
+			"debounce":           "100ms",
+			"initialSyncTimeout": "1s",
+		})
+		require.NoError(t, err)
+
+		chooser := outbounds["their-service"].Unary.(*yarpctest.FakeOutbound).Chooser()
+		require.NoError(t, chooser.Start())
+		defer chooser.Stop()
+
+		list := chooser.(*yarpcpeer.BoundChooser).ChooserList().(interface{ NumAvailable() int })
+		assert.Equal(t, 2, list.NumAvailable())
+	})
+
+	t.Run("watcher error", func(t *testing.T) {
+		_, err := load(map[string]interface{}{"service": "other-service"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown service")
+	})
+
+	t.Run("negative debounce", func(t *testing.T) {
+		_, err := load(map[string]interface{}{"service": "their-service", "debounce": "-1s"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "memory peer list updater config debounce must not be negative")
+	})
+
+	t.Run("negative initial sync timeout", func(t *testing.T) {
+		_, err := load(map[string]interface{}{"service": "their-service", "initialSyncTimeout": "-1s"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "memory peer list updater config initialSyncTimeout must not be negative")
+	})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		_, err := load(map[string]interface{}{"service": "their-service", "region": "west"})
+		assert.Error(t, err)
+	})
+}
+
+func TestSpecInvalidBuildWatcher(t *testing.T) {
+	tests := []struct {
+		name         string
+		buildWatcher interface{}
+		wantPanic    string
+	}{
+		{
+			name:         "not a function",
+			buildWatcher: 42,
+			wantPanic:    `invalid BuildWatcher for "memory": must be a function, found int`,
+		},
+		{
+			name:         "not a struct",
+			buildWatcher: func(string, *yarpcconfig.Kit) (peer.Watcher, error) { return nil, nil },
+			wantPanic:    `invalid BuildWatcher for "memory": must accept a struct or struct pointer as its first argument, found string`,
+		},
+		{
+			name:         "wrong result",
+			buildWatcher: func(testWatcherConfig, *yarpcconfig.Kit) (peer.Binder, error) { return nil, nil },
+			wantPanic:    `invalid BuildWatcher for "memory": must return a peer.Watcher as its first result, found peer.Binder`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.PanicsWithValue(t, tt.wantPanic, func() {
+				Spec(WatcherSpec{Name: "memory", BuildWatcher: tt.buildWatcher})
+			})
+		})
+	}
+}
+
+func TestSpecPointerConfig(t *testing.T) {
+	watcher := NewMemoryWatcher()
+	var got *testWatcherConfig
+	spec := Spec(WatcherSpec{
+		Name: "memory",
+		BuildWatcher: func(c *testWatcherConfig, kit *yarpcconfig.Kit) (peer.Watcher, error) {
+			got = c
+			return watcher, nil
+		},
+	})
+
+	cfg := yarpcconfig.New()
+	require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+	require.NoError(t, cfg.RegisterPeerList(roundrobin.Spec()))
+	require.NoError(t, cfg.RegisterPeerListUpdater(spec))
+	_, err := cfg.LoadConfig("our-service", map[string]interface{}{
+		"outbounds": map[string]interface{}{
+			"their-service": map[string]interface{}{
+				"unary": map[string]interface{}{
+					"fake-transport": map[string]interface{}{
+						"round-robin": map[string]interface{}{
+							"memory": map[string]interface{}{"service": "their-service"},
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "their-service", got.Service)
+}
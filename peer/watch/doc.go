@@ -0,0 +1,42 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package watch keeps peer lists up to date with a peer.Watcher, the generic
+// interface for service discovery systems like Consul, etcd or ZooKeeper.
+//
+// To bind a peer list to a watcher,
+//
+// 	list := roundrobin.New(transport)
+// 	chooser := peer.Bind(list, watch.NewBinder(watcher))
+//
+// The updater applies the full and delta snapshots from the watcher to the
+// peer list, pushing only the differences. It can debounce bursts of
+// snapshots so that the peer list is updated at most once per interval, and
+// blocks Start until the first snapshot arrives or the initial sync timeout
+// elapses.
+//
+// When the watcher reports an error, or a snapshot would leave the service
+// without peers, the last known peers are left in place until the watcher
+// recovers.
+//
+// MemoryWatcher is a watcher whose peers are set by hand, for tests.
+//
+// See Spec for registering a watcher as a yarpcconfig peer list updater.
+package watch
@@ -0,0 +1,168 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package watch
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/yarpc/api/peer"
+)
+
+var _ peer.Watcher = (*MemoryWatcher)(nil)
+
+// MemoryWatcher is a peer.Watcher whose snapshots are sent by hand, for
+// tests.
+//
+// New watches receive a full snapshot of the peers most recently set, if
+// any. Sends block until every active watch has received the snapshot.
+type MemoryWatcher struct {
+	lock  sync.Mutex
+	peers []peer.Identifier
+	isSet bool
+	subs  map[*memorySubscription]struct{}
+}
+
+// NewMemoryWatcher builds a new MemoryWatcher with no peers.
+func NewMemoryWatcher() *MemoryWatcher {
+	return &MemoryWatcher{
+		subs: make(map[*memorySubscription]struct{}),
+	}
+}
+
+// Watch begins a new watch, sending snapshots until the context is
+// cancelled.
+func (w *MemoryWatcher) Watch(ctx context.Context) (<-chan peer.Snapshot, error) {
+	sub := &memorySubscription{
+		ctx: ctx,
+		ch:  make(chan peer.Snapshot, 1),
+	}
+
+	w.lock.Lock()
+	if w.isSet {
+		sub.ch <- peer.Snapshot{Full: true, Peers: w.peers}
+	}
+	w.subs[sub] = struct{}{}
+	w.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.lock.Lock()
+		delete(w.subs, sub)
+		w.lock.Unlock()
+		sub.close()
+	}()
+	return sub.ch, nil
+}
+
+// Set sends a full snapshot with the given peers.
+func (w *MemoryWatcher) Set(pids ...peer.Identifier) {
+	w.send(peer.Snapshot{Full: true, Peers: pids})
+}
+
+// Update sends a snapshot with the given changes.
+func (w *MemoryWatcher) Update(updates peer.ListUpdates) {
+	w.send(peer.Snapshot{Updates: updates})
+}
+
+// Fail sends a snapshot reporting the given error.
+func (w *MemoryWatcher) Fail(err error) {
+	w.send(peer.Snapshot{Err: err})
+}
+
+func (w *MemoryWatcher) send(s peer.Snapshot) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if s.Err == nil {
+		w.peers = apply(w.peers, s)
+		w.isSet = true
+	}
+	for sub := range w.subs {
+		sub.send(s)
+	}
+}
+
+// apply returns the peers that result from applying the snapshot to the
+// given peers.
+func apply(pids []peer.Identifier, s peer.Snapshot) []peer.Identifier {
+	if s.Full {
+		return s.Peers
+	}
+
+	byID := make(map[string]peer.Identifier, len(pids))
+	var order []string
+	for _, pid := range pids {
+		byID[pid.Identifier()] = pid
+		order = append(order, pid.Identifier())
+	}
+	for _, pid := range s.Updates.Removals {
+		delete(byID, pid.Identifier())
+	}
+	for _, pid := range s.Updates.Additions {
+		if _, ok := byID[pid.Identifier()]; !ok {
+			order = append(order, pid.Identifier())
+		}
+		byID[pid.Identifier()] = pid
+	}
+	for _, pid := range s.Updates.Changes {
+		if _, ok := byID[pid.Identifier()]; ok {
+			byID[pid.Identifier()] = pid
+		}
+	}
+
+	result := make([]peer.Identifier, 0, len(byID))
+	for _, id := range order {
+		if pid, ok := byID[id]; ok {
+			result = append(result, pid)
+			delete(byID, id)
+		}
+	}
+	return result
+}
+
+type memorySubscription struct {
+	lock   sync.Mutex
+	ctx    context.Context
+	ch     chan peer.Snapshot
+	closed bool
+}
+
+func (s *memorySubscription) send(snapshot peer.Snapshot) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- snapshot:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *memorySubscription) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	close(s.ch)
+}
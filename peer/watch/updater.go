@@ -0,0 +1,313 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/internal/peerdiff"
+	"go.uber.org/yarpc/pkg/lifecycle"
+	"go.uber.org/zap"
+)
+
+var errNoPeers = errors.New("no peers found")
+
+type updaterOptions struct {
+	debounce           time.Duration
+	initialSyncTimeout time.Duration
+	allowEmpty         bool
+	logger             *zap.Logger
+	clock              clock.Clock
+}
+
+// Option customizes the behavior of a watch peer list updater.
+type Option func(*updaterOptions)
+
+// Debounce coalesces snapshots that arrive in quick succession so that the
+// peer list is updated at most once per interval.
+//
+// Defaults to 0, applying every snapshot as soon as it arrives. The first
+// snapshot is always applied immediately.
+func Debounce(d time.Duration) Option {
+	return func(o *updaterOptions) {
+		o.debounce = d
+	}
+}
+
+// InitialSyncTimeout specifies how long Start waits for the watcher to report
+// the initial peers. If the timeout elapses, Start returns and the updater
+// adds the peers when they arrive.
+//
+// Defaults to 5 seconds. A timeout of 0 does not wait.
+func InitialSyncTimeout(d time.Duration) Option {
+	return func(o *updaterOptions) {
+		o.initialSyncTimeout = d
+	}
+}
+
+// AllowEmpty allows snapshots that leave the service without peers to remove
+// every peer from the peer list.
+//
+// By default, such snapshots are treated as failures of the discovery system
+// and the last known peers are left in place.
+func AllowEmpty() Option {
+	return func(o *updaterOptions) {
+		o.allowEmpty = true
+	}
+}
+
+// Logger specifies a logger for failures of the watcher.
+func Logger(logger *zap.Logger) Option {
+	return func(o *updaterOptions) {
+		o.logger = logger
+	}
+}
+
+func withClock(c clock.Clock) Option {
+	return func(o *updaterOptions) {
+		o.clock = c
+	}
+}
+
+// NewBinder returns a peer.Binder that binds a peer list to the peers
+// reported by the given watcher.
+func NewBinder(w peer.Watcher, opts ...Option) peer.Binder {
+	return func(pl peer.List) transport.Lifecycle {
+		return NewUpdater(pl, w, opts...)
+	}
+}
+
+// NewUpdater builds a peer list updater that keeps the peer list up to date
+// with the peers reported by the given watcher while it is running.
+func NewUpdater(pl peer.List, w peer.Watcher, opts ...Option) *Updater {
+	options := updaterOptions{
+		initialSyncTimeout: 5 * time.Second,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.logger == nil {
+		options.logger = zap.NewNop()
+	}
+	if options.clock == nil {
+		options.clock = clock.NewReal()
+	}
+
+	return &Updater{
+		once:    lifecycle.NewOnce(),
+		list:    pl,
+		watcher: w,
+		opts:    options,
+		log:     options.logger,
+		synced:  make(chan struct{}),
+		stopped: make(chan struct{}),
+		desired: make(map[string]peer.Identifier),
+		peers:   make(map[string]peer.Identifier),
+	}
+}
+
+// Updater is a peer list updater that adds and removes peers as a watcher
+// reports changes.
+type Updater struct {
+	once    *lifecycle.Once
+	list    peer.List
+	watcher peer.Watcher
+	opts    updaterOptions
+	log     *zap.Logger
+
+	cancel  context.CancelFunc
+	synced  chan struct{}
+	stopped chan struct{}
+
+	// The following are accessed only by the watching loop and by Stop
+	// after the watching loop has ended.
+
+	// Peers reported by the watcher, including changes not yet pushed to
+	// the list.
+	desired map[string]peer.Identifier
+
+	// Whether desired has changed since it was last pushed.
+	dirty bool
+
+	// Whether the initial peers have been pushed to the list.
+	isSynced bool
+
+	// Error from the previous snapshot, used to log only changes.
+	lastErr error
+
+	// Peers most recently pushed to the list.
+	peers map[string]peer.Identifier
+}
+
+// Start begins watching for peers and waits for the initial peers to be
+// added to the peer list, up to the initial sync timeout.
+//
+// Start fails if the watcher fails to begin watching.
+func (u *Updater) Start() error {
+	return u.once.Start(u.start)
+}
+
+func (u *Updater) start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	snapshots, err := u.watcher.Watch(ctx)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to watch for peers: %v", err)
+	}
+	u.cancel = cancel
+	go u.run(ctx, snapshots)
+
+	if u.opts.initialSyncTimeout <= 0 {
+		return nil
+	}
+	select {
+	case <-u.synced:
+	case <-u.opts.clock.After(u.opts.initialSyncTimeout):
+		u.log.Warn("Timed out waiting for initial peers, continuing to watch.",
+			zap.Duration("timeout", u.opts.initialSyncTimeout))
+	}
+	return nil
+}
+
+// Stop stops watching for peers and removes all peers it added from the peer
+// list.
+func (u *Updater) Stop() error {
+	return u.once.Stop(u.stopUpdater)
+}
+
+func (u *Updater) stopUpdater() error {
+	u.cancel()
+	<-u.stopped
+
+	updates := peerdiff.Diff(u.peers, nil)
+	u.peers = make(map[string]peer.Identifier)
+	return u.list.Update(updates)
+}
+
+// IsRunning returns whether the updater is running.
+func (u *Updater) IsRunning() bool {
+	return u.once.IsRunning()
+}
+
+func (u *Updater) run(ctx context.Context, snapshots <-chan peer.Snapshot) {
+	defer close(u.stopped)
+
+	// Fires when debounced changes are due to be pushed.
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s, ok := <-snapshots:
+			if !ok {
+				u.log.Error("Watcher stopped unexpectedly, retaining last known peers.")
+				snapshots = nil
+				continue
+			}
+			u.receive(s)
+			switch {
+			case !u.dirty:
+			case !u.isSynced || u.opts.debounce <= 0:
+				u.push()
+			case flush == nil:
+				flush = u.opts.clock.After(u.opts.debounce)
+			}
+		case <-flush:
+			flush = nil
+			u.push()
+		}
+	}
+}
+
+// receive applies a snapshot to the desired peers.
+func (u *Updater) receive(s peer.Snapshot) {
+	if s.Err != nil {
+		u.observe(s.Err)
+		return
+	}
+
+	if s.Full {
+		desired := make(map[string]peer.Identifier, len(s.Peers))
+		for _, pid := range s.Peers {
+			desired[pid.Identifier()] = pid
+		}
+		u.desired = desired
+	} else {
+		for _, pid := range s.Updates.Removals {
+			delete(u.desired, pid.Identifier())
+		}
+		for _, pid := range s.Updates.Additions {
+			u.desired[pid.Identifier()] = pid
+		}
+		for _, pid := range s.Updates.Changes {
+			u.desired[pid.Identifier()] = pid
+		}
+	}
+	u.dirty = true
+}
+
+// push pushes the differences between the desired peers and the peers in the
+// peer list to the peer list, unless doing so would leave the list empty.
+func (u *Updater) push() {
+	u.dirty = false
+	if len(u.desired) == 0 && !u.opts.allowEmpty {
+		u.observe(errNoPeers)
+		return
+	}
+	u.observe(nil)
+
+	// The peers are only recorded once the list has them, so that the next
+	// push retries a failed update.
+	var err error
+	if updates := peerdiff.Diff(u.peers, u.desired); !peerdiff.IsEmpty(updates) {
+		err = u.list.Update(updates)
+	}
+	if err != nil {
+		u.log.Error("Failed to update peer list.", zap.Error(err))
+	} else {
+		u.peers = make(map[string]peer.Identifier, len(u.desired))
+		for id, pid := range u.desired {
+			u.peers[id] = pid
+		}
+	}
+
+	if !u.isSynced {
+		u.isSynced = true
+		close(u.synced)
+	}
+}
+
+// observe logs changes in the health of the watcher.
+func (u *Updater) observe(err error) {
+	switch {
+	case err != nil && (u.lastErr == nil || u.lastErr.Error() != err.Error()):
+		u.log.Warn("Failed to watch for peers, retaining last known peers.", zap.Error(err))
+	case err == nil && u.lastErr != nil:
+		u.log.Info("Watching for peers after previous failure.")
+	}
+	u.lastErr = err
+}
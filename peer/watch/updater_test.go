@@ -0,0 +1,222 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/internal/clock"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+// fakeList records the updates it receives, and fails them with the errors
+// sent on errs.
+type fakeList struct {
+	updates chan peer.ListUpdates
+	errs    chan error
+}
+
+func newFakeList() *fakeList {
+	return &fakeList{
+		updates: make(chan peer.ListUpdates, 10),
+		errs:    make(chan error, 1),
+	}
+}
+
+func (l *fakeList) Update(updates peer.ListUpdates) error {
+	l.updates <- updates
+	select {
+	case err := <-l.errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (l *fakeList) expect(t *testing.T, want peer.ListUpdates) {
+	select {
+	case updates := <-l.updates:
+		assert.Equal(t, want, updates)
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for peer list update")
+	}
+}
+
+func (l *fakeList) expectNone(t *testing.T) {
+	select {
+	case updates := <-l.updates:
+		t.Fatalf("unexpected peer list update: %v", updates)
+	default:
+	}
+}
+
+// waitForSnapshots waits for the updater to process every snapshot sent before it. The
+// watcher buffers one snapshot, so the second of two empty updates is
+// received only after the snapshots before the first have been processed.
+func waitForSnapshots(w *MemoryWatcher) {
+	w.Update(peer.ListUpdates{})
+	w.Update(peer.ListUpdates{})
+}
+
+var (
+	a        = hostport.PeerIdentifier("127.0.0.1:8080")
+	b        = hostport.PeerIdentifier("127.0.0.1:8081")
+	weightyB = hostport.IdentifyWithAttributes("127.0.0.1:8081", hostport.PeerAttributes{Weight: 2})
+	c        = hostport.PeerIdentifier("127.0.0.1:8082")
+)
+
+func TestUpdater(t *testing.T) {
+	watcher := NewMemoryWatcher()
+	watcher.Set(a, b)
+
+	list := newFakeList()
+	updater := NewUpdater(list, watcher)
+	require.NoError(t, updater.Start())
+	assert.True(t, updater.IsRunning())
+
+	// Start waits for the initial peers.
+	select {
+	case updates := <-list.updates:
+		assert.Equal(t, peer.ListUpdates{Additions: []peer.Identifier{a, b}}, updates)
+	default:
+		t.Fatalf("Start must wait for the initial peers")
+	}
+
+	watcher.Set(b, c)
+	list.expect(t, peer.ListUpdates{
+		Additions: []peer.Identifier{c},
+		Removals:  []peer.Identifier{a},
+	})
+
+	watcher.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{a},
+		Changes:   []peer.Identifier{weightyB},
+	})
+	list.expect(t, peer.ListUpdates{
+		Additions: []peer.Identifier{a},
+		Changes:   []peer.Identifier{weightyB},
+	})
+
+	// Failures and snapshots without peers retain the current peers.
+	watcher.Fail(errors.New("great sadness"))
+	watcher.Set()
+	waitForSnapshots(watcher)
+	list.expectNone(t)
+
+	// The watcher recovers.
+	watcher.Set(a, b)
+	list.expect(t, peer.ListUpdates{
+		Removals: []peer.Identifier{c},
+		Changes:  []peer.Identifier{b},
+	})
+
+	require.NoError(t, updater.Stop())
+	list.expect(t, peer.ListUpdates{Removals: []peer.Identifier{a, b}})
+	assert.False(t, updater.IsRunning())
+}
+
+func TestUpdaterRetriesFailedUpdate(t *testing.T) {
+	watcher := NewMemoryWatcher()
+	watcher.Set(a)
+
+	list := newFakeList()
+	updater := NewUpdater(list, watcher)
+	require.NoError(t, updater.Start())
+	defer updater.Stop()
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{a}})
+
+	list.errs <- errors.New("great sadness")
+	watcher.Set(a, b)
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{b}})
+
+	// The failed addition is retried with the next snapshot.
+	watcher.Set(a, b, c)
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{b, c}})
+}
+
+func TestUpdaterAllowEmpty(t *testing.T) {
+	watcher := NewMemoryWatcher()
+	watcher.Set(a)
+
+	list := newFakeList()
+	updater := NewUpdater(list, watcher, AllowEmpty())
+	require.NoError(t, updater.Start())
+	defer updater.Stop()
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{a}})
+
+	watcher.Update(peer.ListUpdates{Removals: []peer.Identifier{a}})
+	list.expect(t, peer.ListUpdates{Removals: []peer.Identifier{a}})
+}
+
+func TestUpdaterInitialSyncTimeout(t *testing.T) {
+	watcher := NewMemoryWatcher()
+	list := newFakeList()
+	updater := NewUpdater(list, watcher, InitialSyncTimeout(10*time.Millisecond))
+	require.NoError(t, updater.Start())
+	defer updater.Stop()
+	list.expectNone(t)
+
+	// Peers that arrive after the timeout are added.
+	watcher.Set(a)
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{a}})
+}
+
+func TestUpdaterDebounce(t *testing.T) {
+	clock := clock.NewFake()
+	watcher := NewMemoryWatcher()
+	watcher.Set(a)
+
+	list := newFakeList()
+	updater := NewUpdater(list, watcher, Debounce(time.Second), withClock(clock))
+	require.NoError(t, updater.Start())
+	defer updater.Stop()
+
+	// The initial peers are not debounced.
+	list.expect(t, peer.ListUpdates{Additions: []peer.Identifier{a}})
+
+	watcher.Set(a, b)
+	watcher.Set(b, c)
+	waitForSnapshots(watcher)
+	list.expectNone(t)
+
+	clock.Add(time.Second)
+	list.expect(t, peer.ListUpdates{
+		Additions: []peer.Identifier{b, c},
+		Removals:  []peer.Identifier{a},
+	})
+}
+
+type failingWatcher struct{}
+
+func (failingWatcher) Watch(ctx context.Context) (<-chan peer.Snapshot, error) {
+	return nil, errors.New("great sadness")
+}
+
+func TestUpdaterWatchFailure(t *testing.T) {
+	updater := NewUpdater(newFakeList(), failingWatcher{})
+	assert.EqualError(t, updater.Start(), "failed to watch for peers: great sadness")
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package weightedroundrobin provides a peer list that spreads requests
+// across peers in proportion to their weights, using the smooth weighted
+// round-robin algorithm popularized by nginx.
+//
+// Peers are weighted by peer list updaters that provide identifiers
+// implementing peer.WeightedIdentifier, like static peers configured as
+// "127.0.0.1:8080;weight=3". Peers without a weight have a weight of 1.
+//
+// Rather than sending a burst of consecutive requests to the heaviest peer,
+// smooth weighted round-robin interleaves peers. With weights 5, 1 and 1 for
+// peers a, b and c, requests are sent in the order a a b a c a a.
+//
+// Changing the weight of a peer with peer.ListUpdates.Changes takes effect
+// immediately, without removing and adding the peer.
+package weightedroundrobin
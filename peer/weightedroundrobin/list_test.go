@@ -0,0 +1,73 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package weightedroundrobin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+func TestList(t *testing.T) {
+	fake := yarpctest.NewFakeTransport()
+	list := New(fake)
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			weighted("1.1.1.1:80", 2),
+			hostport.PeerIdentifier("2.2.2.2:80"),
+		},
+	}))
+
+	choose := func() string {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		p, onFinish, err := list.Choose(ctx, &transport.Request{})
+		require.NoError(t, err)
+		onFinish(nil)
+		return p.Identifier()
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		counts[choose()]++
+	}
+	assert.Equal(t, map[string]int{"1.1.1.1:80": 20, "2.2.2.2:80": 10}, counts)
+
+	// Weights change without removing the peer.
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Changes: []peer.Identifier{weighted("2.2.2.2:80", 4)},
+	}))
+	counts = make(map[string]int)
+	for i := 0; i < 30; i++ {
+		counts[choose()]++
+	}
+	assert.Equal(t, map[string]int{"1.1.1.1:80": 10, "2.2.2.2:80": 20}, counts)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package weightedroundrobin
+
+import (
+	"context"
+
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/peerlist/v2"
+)
+
+type subscriber struct {
+	index   int
+	peer    peer.StatusPeer
+	weight  int
+	current int
+}
+
+func (*subscriber) NotifyStatusChanged(peer.Identifier) {}
+
+// smoothWeightedRoundRobin chooses the peer with the highest current weight,
+// after increasing the current weight of every peer by its weight, and then
+// decreases the current weight of the chosen peer by the total weight.
+//
+// smoothWeightedRoundRobin is NOT thread-safe; the peer list calls it under
+// a lock.
+type smoothWeightedRoundRobin struct {
+	subscribers []*subscriber
+	totalWeight int
+}
+
+var _ peerlist.ChangeableImplementation = (*smoothWeightedRoundRobin)(nil)
+
+func newSmoothWeightedRoundRobin(capacity int) *smoothWeightedRoundRobin {
+	return &smoothWeightedRoundRobin{
+		subscribers: make([]*subscriber, 0, capacity),
+	}
+}
+
+func (l *smoothWeightedRoundRobin) Add(p peer.StatusPeer, pid peer.Identifier) peer.Subscriber {
+	sub := &subscriber{
+		index:  len(l.subscribers),
+		peer:   p,
+		weight: peer.WeightOf(pid),
+	}
+	l.subscribers = append(l.subscribers, sub)
+	l.totalWeight += sub.weight
+	return sub
+}
+
+func (l *smoothWeightedRoundRobin) Remove(_ peer.StatusPeer, _ peer.Identifier, ps peer.Subscriber) {
+	sub, ok := ps.(*subscriber)
+	if !ok || len(l.subscribers) == 0 {
+		return
+	}
+
+	l.totalWeight -= sub.weight
+	last := len(l.subscribers) - 1
+	l.subscribers[sub.index] = l.subscribers[last]
+	l.subscribers[sub.index].index = sub.index
+	l.subscribers[last] = nil
+	l.subscribers = l.subscribers[:last]
+}
+
+// Change updates the weight of the peer, keeping its current weight so that
+// the rotation continues smoothly.
+func (l *smoothWeightedRoundRobin) Change(_ peer.StatusPeer, pid peer.Identifier, ps peer.Subscriber) {
+	sub, ok := ps.(*subscriber)
+	if !ok {
+		return
+	}
+
+	weight := peer.WeightOf(pid)
+	l.totalWeight += weight - sub.weight
+	sub.weight = weight
+}
+
+func (l *smoothWeightedRoundRobin) Choose(_ context.Context, _ *transport.Request) peer.StatusPeer {
+	var best *subscriber
+	for _, sub := range l.subscribers {
+		sub.current += sub.weight
+		if best == nil || sub.current > best.current {
+			best = sub
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	best.current -= l.totalWeight
+	return best.peer
+}
+
+func (l *smoothWeightedRoundRobin) Start() error {
+	return nil
+}
+
+func (l *smoothWeightedRoundRobin) Stop() error {
+	return nil
+}
+
+func (l *smoothWeightedRoundRobin) IsRunning() bool {
+	return true
+}
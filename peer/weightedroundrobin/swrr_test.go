@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package weightedroundrobin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+)
+
+type swrrPeers struct {
+	list *smoothWeightedRoundRobin
+	subs map[string]peer.Subscriber
+}
+
+func newSWRRPeers() *swrrPeers {
+	return &swrrPeers{
+		list: newSmoothWeightedRoundRobin(0),
+		subs: make(map[string]peer.Subscriber),
+	}
+}
+
+func weighted(id string, weight int) peer.Identifier {
+	return hostport.IdentifyWithAttributes(id, hostport.PeerAttributes{Weight: weight})
+}
+
+func (p *swrrPeers) add(id string, weight int) {
+	p.subs[id] = p.list.Add(hostport.NewPeer(hostport.PeerIdentifier(id), nil), weighted(id, weight))
+}
+
+func (p *swrrPeers) remove(id string) {
+	p.list.Remove(nil, nil, p.subs[id])
+	delete(p.subs, id)
+}
+
+func (p *swrrPeers) change(id string, weight int) {
+	p.list.Change(nil, weighted(id, weight), p.subs[id])
+}
+
+// choose returns the identifiers of the next n choices.
+func (p *swrrPeers) choose(n int) string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = p.list.Choose(context.Background(), &transport.Request{}).Identifier()
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestSmoothWeightedRoundRobin(t *testing.T) {
+	p := newSWRRPeers()
+	assert.Nil(t, p.list.Choose(context.Background(), &transport.Request{}))
+
+	p.add("a", 5)
+	p.add("b", 1)
+	p.add("c", 1)
+	assert.Equal(t, "a a b a c a a", p.choose(7))
+	assert.Equal(t, "a a b a c a a", p.choose(7), "rotation repeats")
+
+	// Peers without a weight have a weight of 1.
+	p.change("a", 0)
+	assert.Equal(t, 3, p.list.totalWeight)
+	assert.Equal(t, "a b c a b c", p.choose(6))
+
+	p.change("c", 2)
+	assert.Equal(t, "c a b c", p.choose(4))
+
+	p.remove("c")
+	assert.Equal(t, 2, p.list.totalWeight)
+	assert.Equal(t, "a b a b", p.choose(4))
+
+	p.remove("a")
+	p.remove("b")
+	assert.Equal(t, 0, p.list.totalWeight)
+	assert.Nil(t, p.list.Choose(context.Background(), &transport.Request{}))
+}
+
+func TestSmoothWeightedRoundRobinRemoveUnknownSubscriber(t *testing.T) {
+	p := newSWRRPeers()
+	p.add("a", 2)
+	p.list.Remove(nil, nil, nil)
+	p.list.Change(nil, weighted("a", 3), nil)
+	assert.Equal(t, 2, p.list.totalWeight)
+}
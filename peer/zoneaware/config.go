@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zoneaware
+
+import (
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// Configuration describes how to build a zone-aware peer list.
+type Configuration struct {
+	LocalZone         string   `config:"localZone"`
+	FailoverThreshold float64  `config:"failoverThreshold"`
+	FailoverZones     []string `config:"failoverZones"`
+
+	// Name of the registered peer list that chooses among the peers of each
+	// zone. Defaults to "round-robin".
+	ZoneList string `config:"zoneList"`
+}
+
+// Spec returns a configuration specification for the zone-aware peer list
+// implementation, making it possible to prefer peers in the local zone with
+// transports that use outbound peer list configuration (like HTTP).
+//
+//  cfg := yarpcconfig.New()
+//  cfg.MustRegisterPeerList(zoneaware.Spec())
+//
+// This enables the zone-aware peer list:
+//
+//  outbounds:
+//    otherservice:
+//      unary:
+//        http:
+//          url: https://host:port/rpc
+//          zone-aware:
+//            localZone: us-east-1a
+//            failoverThreshold: 0.5
+//            failoverZones: [us-east-1b]
+//            zoneList: least-pending
+//            peers:
+//              - 127.0.0.1:8080;zone=us-east-1a
+//              - 127.0.0.1:8081;zone=us-east-1b
+//
+// The peer list for each zone must be registered with the Configurator.
+func Spec() yarpcconfig.PeerListSpec {
+	return yarpcconfig.PeerListSpec{
+		Name: _name,
+		BuildPeerList: func(cfg Configuration, t peer.Transport, k *yarpcconfig.Kit) (peer.ChooserList, error) {
+			if cfg.FailoverThreshold < 0 || cfg.FailoverThreshold > 1 {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"FailoverThreshold must be between 0 and 1. Got: %v.", cfg.FailoverThreshold)
+			}
+
+			zoneList := cfg.ZoneList
+			if zoneList == "" {
+				zoneList = "round-robin"
+			}
+			if zoneList == _name {
+				return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+					"ZoneList must not be %q.", _name)
+			}
+
+			newList := func(string) (ZoneList, error) {
+				list, err := k.BuildPeerList(zoneList, nil, t)
+				if err != nil {
+					return nil, err
+				}
+				zl, ok := list.(ZoneList)
+				if !ok {
+					return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+						"peer list %q does not report the number of available peers", zoneList)
+				}
+				return zl, nil
+			}
+
+			// Build a peer list now to report configuration errors early.
+			if _, err := newList(cfg.LocalZone); err != nil {
+				return nil, err
+			}
+
+			return New(cfg.LocalZone, newList,
+				FailoverThreshold(cfg.FailoverThreshold),
+				FailoverZones(cfg.FailoverZones...),
+			), nil
+		},
+	}
+}
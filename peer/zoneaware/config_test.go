@@ -0,0 +1,146 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zoneaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcconfig"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+type attrs map[string]interface{}
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		desc    string
+		give    attrs
+		wantErr string
+	}{
+		{desc: "defaults", give: attrs{}},
+		{
+			desc: "all options",
+			give: attrs{
+				"localZone":         "a",
+				"failoverThreshold": 0.5,
+				"failoverZones":     []string{"b"},
+				"zoneList":          "round-robin",
+			},
+		},
+		{
+			desc:    "zone list without availability",
+			give:    attrs{"zoneList": "fake-list"},
+			wantErr: `peer list "fake-list" does not report the number of available peers`,
+		},
+		{
+			desc:    "negative threshold",
+			give:    attrs{"failoverThreshold": -0.5},
+			wantErr: "FailoverThreshold must be between 0 and 1. Got: -0.5.",
+		},
+		{
+			desc:    "threshold above 1",
+			give:    attrs{"failoverThreshold": 2},
+			wantErr: "FailoverThreshold must be between 0 and 1. Got: 2.",
+		},
+		{
+			desc:    "unknown zone list",
+			give:    attrs{"zoneList": "least-pending"},
+			wantErr: `no recognized peer list or chooser "least-pending"`,
+		},
+		{
+			desc:    "zone-aware zone list",
+			give:    attrs{"zoneList": "zone-aware"},
+			wantErr: `ZoneList must not be "zone-aware".`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			listCfg := attrs{"peers": []string{"1.1.1.1:1111;zone=a", "2.2.2.2:2222;zone=b"}}
+			for k, v := range tt.give {
+				listCfg[k] = v
+			}
+
+			cfg := yarpcconfig.New()
+			require.NoError(t, cfg.RegisterPeerList(Spec()))
+			require.NoError(t, cfg.RegisterPeerList(roundrobin.Spec()))
+			require.NoError(t, cfg.RegisterPeerList(yarpctest.FakePeerListSpec()))
+			require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+			_, err := cfg.LoadConfig("our-service", attrs{
+				"outbounds": attrs{
+					"their-service": attrs{
+						"fake-transport": attrs{
+							"zone-aware": listCfg,
+						},
+					},
+				},
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfigLocalZone(t *testing.T) {
+	cfg := yarpcconfig.New()
+	require.NoError(t, cfg.RegisterPeerList(Spec()))
+	require.NoError(t, cfg.RegisterPeerList(roundrobin.Spec()))
+	require.NoError(t, cfg.RegisterTransport(yarpctest.FakeTransportSpec()))
+	config, err := cfg.LoadConfig("our-service", attrs{
+		"outbounds": attrs{
+			"their-service": attrs{
+				"fake-transport": attrs{
+					"zone-aware": attrs{
+						"localZone": "b",
+						"peers": []string{
+							"1.1.1.1:1111;zone=a",
+							"2.2.2.2:2222;zone=b",
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	chooser := config.Outbounds["their-service"].Unary.(*yarpctest.FakeOutbound).Chooser()
+	require.NoError(t, chooser.Start())
+	defer chooser.Stop()
+
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		p, onFinish, err := chooser.Choose(ctx, &transport.Request{})
+		cancel()
+		require.NoError(t, err)
+		onFinish(nil)
+		assert.Equal(t, "2.2.2.2:2222", p.Identifier())
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zoneaware provides a peer list that prefers peers in the local
+// zone, failing over to peers in other zones when too few local peers are
+// available.
+//
+// The zone of each peer comes from its identifier, if it implements
+// peer.ZonedIdentifier, as identifiers from peer list updaters that support
+// zones do. Peers without a zone are in the zone named by the empty string.
+// The peers of each zone are kept in their own peer list, like a round-robin
+// list, which chooses among them.
+//
+// Requests go to the local zone while at least the failover threshold
+// fraction of its peers are available, and otherwise to the first zone that
+// meets the threshold, trying failover zones in the order given and then the
+// remaining zones by name.
+//
+// 	list := zoneaware.New("us-east-1a", func(string) (zoneaware.ZoneList, error) {
+// 		return roundrobin.New(transport), nil
+// 	}, zoneaware.FailoverThreshold(0.5))
+//
+// See Spec for configuring the list with yarpcconfig.
+package zoneaware
@@ -0,0 +1,518 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zoneaware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/introspection"
+	"go.uber.org/yarpc/internal/readiness"
+	intyarpcerrors "go.uber.org/yarpc/internal/yarpcerrors"
+	"go.uber.org/yarpc/pkg/lifecycle"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const _name = "zone-aware"
+
+// ZoneList is a peer list for the peers of a single zone. The peer lists in
+// the peer and peer/peerlist/v2 packages, like round-robin, satisfy this
+// interface.
+type ZoneList interface {
+	peer.ChooserList
+
+	// NumAvailable returns how many peers in the list are available.
+	NumAvailable() int
+
+	// NumUnavailable returns how many peers in the list are unavailable.
+	NumUnavailable() int
+}
+
+// NewZoneListFunc builds an empty peer list for the peers of the given zone.
+type NewZoneListFunc func(zone string) (ZoneList, error)
+
+type listOptions struct {
+	failoverThreshold float64
+	failoverZones     []string
+}
+
+// ListOption customizes the behavior of a zone-aware peer list.
+type ListOption interface {
+	apply(*listOptions)
+}
+
+type listOptionFunc func(*listOptions)
+
+func (f listOptionFunc) apply(options *listOptions) { f(options) }
+
+// FailoverThreshold specifies the fraction of the peers in a zone, between 0
+// and 1, that must be available for the zone to receive requests. Requests
+// fail over from the local zone to other zones when fewer of its peers are
+// available.
+//
+// Defaults to 0, failing over only when no local peers are available.
+func FailoverThreshold(threshold float64) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.failoverThreshold = threshold
+	})
+}
+
+// FailoverZones specifies the order in which requests fail over to other
+// zones. Zones not listed are tried after these, in order of their names.
+func FailoverZones(zones ...string) ListOption {
+	return listOptionFunc(func(options *listOptions) {
+		options.failoverZones = zones
+	})
+}
+
+// New creates a zone-aware peer list that sends requests to peers in the
+// local zone, failing over to the peers of other zones. The peers of each
+// zone are kept in their own peer list, built with newList.
+func New(localZone string, newList NewZoneListFunc, opts ...ListOption) *List {
+	var options listOptions
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	preference := map[string]int{localZone: 0}
+	for _, zone := range options.failoverZones {
+		if _, ok := preference[zone]; !ok {
+			preference[zone] = len(preference)
+		}
+	}
+
+	return &List{
+		once:       lifecycle.NewOnce(),
+		localZone:  localZone,
+		newList:    newList,
+		threshold:  options.failoverThreshold,
+		preference: preference,
+		zones:      make(map[string]*zone),
+		peers:      make(map[string]string),
+		zoneAdded:  make(chan struct{}),
+	}
+}
+
+// List is a peer list that prefers peers in the local zone, failing over to
+// peers in other zones when too few local peers are available.
+type List struct {
+	once *lifecycle.Once
+
+	localZone  string
+	newList    NewZoneListFunc
+	threshold  float64
+	preference map[string]int
+
+	lock    sync.RWMutex
+	running bool
+	zones   map[string]*zone
+	// Zones in the order in which they receive requests.
+	order []*zone
+	// Zones of the peers in the list, by identifier.
+	peers map[string]string
+
+	// Closed and replaced whenever a zone is added, waking all goroutines
+	// waiting for a zone.
+	zoneAdded chan struct{}
+}
+
+var (
+	_ peer.ChooserList                    = (*List)(nil)
+	_ peer.ReadinessWaiter                = (*List)(nil)
+	_ introspection.IntrospectableChooser = (*List)(nil)
+)
+
+type zone struct {
+	name string
+	list ZoneList
+	size int
+}
+
+// zoneOf returns the zone of a peer, or the empty string if it has none.
+func zoneOf(pid peer.Identifier) string {
+	if z, ok := pid.(peer.ZonedIdentifier); ok {
+		return z.Zone()
+	}
+	return ""
+}
+
+// Update partitions the added, removed and changed peers by zone and updates
+// the peer lists of their zones. A peer that changes zones moves between
+// them.
+func (l *List) Update(updates peer.ListUpdates) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var err error
+	byZone := make(map[string]*peer.ListUpdates)
+	zoneUpdates := func(name string) *peer.ListUpdates {
+		u, ok := byZone[name]
+		if !ok {
+			u = &peer.ListUpdates{}
+			byZone[name] = u
+		}
+		return u
+	}
+
+	for _, pid := range updates.Removals {
+		name, ok := l.peers[pid.Identifier()]
+		if !ok {
+			err = multierr.Append(err, peer.ErrPeerRemoveNotInList(pid.Identifier()))
+			continue
+		}
+		delete(l.peers, pid.Identifier())
+		u := zoneUpdates(name)
+		u.Removals = append(u.Removals, pid)
+	}
+
+	for _, pid := range updates.Additions {
+		if _, ok := l.peers[pid.Identifier()]; ok {
+			err = multierr.Append(err, peer.ErrPeerAddAlreadyInList(pid.Identifier()))
+			continue
+		}
+		name := zoneOf(pid)
+		l.peers[pid.Identifier()] = name
+		u := zoneUpdates(name)
+		u.Additions = append(u.Additions, pid)
+	}
+
+	for _, pid := range updates.Changes {
+		oldName, ok := l.peers[pid.Identifier()]
+		if !ok {
+			err = multierr.Append(err, peer.ErrPeerChangeNotInList(pid.Identifier()))
+			continue
+		}
+		name := zoneOf(pid)
+		if name == oldName {
+			u := zoneUpdates(name)
+			u.Changes = append(u.Changes, pid)
+			continue
+		}
+		l.peers[pid.Identifier()] = name
+		old := zoneUpdates(oldName)
+		old.Removals = append(old.Removals, pid)
+		u := zoneUpdates(name)
+		u.Additions = append(u.Additions, pid)
+	}
+
+	names := make([]string, 0, len(byZone))
+	for name := range byZone {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Remove peers before adding them so that peers moving between zones are
+	// released by one zone before they are retained by the other.
+	for _, name := range names {
+		u := byZone[name]
+		if len(u.Removals) == 0 {
+			continue
+		}
+		z := l.zones[name]
+		z.size -= len(u.Removals)
+		err = multierr.Append(err, z.list.Update(peer.ListUpdates{Removals: u.Removals}))
+		u.Removals = nil
+	}
+
+	for _, name := range names {
+		u := byZone[name]
+		if len(u.Additions) == 0 && len(u.Changes) == 0 {
+			continue
+		}
+		z, zerr := l.getOrCreateZone(name)
+		if zerr != nil {
+			err = multierr.Append(err, zerr)
+			for _, pid := range u.Additions {
+				delete(l.peers, pid.Identifier())
+			}
+			continue
+		}
+		z.size += len(u.Additions)
+		err = multierr.Append(err, z.list.Update(*u))
+	}
+
+	for _, name := range names {
+		if z := l.zones[name]; z != nil && z.size <= 0 {
+			err = multierr.Append(err, l.removeZone(z))
+		}
+	}
+
+	return err
+}
+
+// getOrCreateZone returns the zone with the given name, creating and starting
+// its peer list if necessary.
+// Must be called under the write lock.
+func (l *List) getOrCreateZone(name string) (*zone, error) {
+	if z := l.zones[name]; z != nil {
+		return z, nil
+	}
+
+	list, err := l.newList(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build peer list for zone %q: %v", name, err)
+	}
+	if l.running {
+		if err := list.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start peer list for zone %q: %v", name, err)
+		}
+	}
+
+	z := &zone{name: name, list: list}
+	l.zones[name] = z
+	l.reorder()
+
+	close(l.zoneAdded)
+	l.zoneAdded = make(chan struct{})
+	return z, nil
+}
+
+// removeZone removes a zone that no longer has peers, stopping its peer list.
+// Must be called under the write lock.
+func (l *List) removeZone(z *zone) error {
+	delete(l.zones, z.name)
+	l.reorder()
+	if l.running {
+		return z.list.Stop()
+	}
+	return nil
+}
+
+// reorder sorts zones in the order in which they receive requests: the local
+// zone, followed by failover zones in the order given, followed by the
+// remaining zones by name.
+// Must be called under the write lock.
+func (l *List) reorder() {
+	order := make([]*zone, 0, len(l.zones))
+	for _, z := range l.zones {
+		order = append(order, z)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		pi, iok := l.preference[order[i].name]
+		pj, jok := l.preference[order[j].name]
+		switch {
+		case iok && jok:
+			return pi < pj
+		case iok != jok:
+			return iok
+		default:
+			return order[i].name < order[j].name
+		}
+	})
+	l.order = order
+}
+
+// Start starts the peer lists of all zones.
+func (l *List) Start() error {
+	return l.once.Start(l.start)
+}
+
+func (l *List) start() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var err error
+	for _, z := range l.order {
+		err = multierr.Append(err, z.list.Start())
+	}
+	l.running = true
+	return err
+}
+
+// Stop stops the peer lists of all zones.
+func (l *List) Stop() error {
+	return l.once.Stop(l.stop)
+}
+
+func (l *List) stop() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var err error
+	for _, z := range l.order {
+		err = multierr.Append(err, z.list.Stop())
+	}
+	l.running = false
+	return err
+}
+
+// IsRunning returns whether the peer list is running.
+func (l *List) IsRunning() bool {
+	return l.once.IsRunning()
+}
+
+// Choose chooses a peer from the first zone, starting with the local zone,
+// with enough available peers to meet the failover threshold. If no zone
+// meets the threshold, it chooses a peer from the first zone with any
+// available peers. If no peers are available at all, it waits for a peer in
+// the most preferred zone to become available.
+func (l *List) Choose(ctx context.Context, req *transport.Request) (peer.Peer, func(error), error) {
+	if err := l.once.WaitUntilRunning(ctx); err != nil {
+		return nil, nil, intyarpcerrors.AnnotateWithInfo(yarpcerrors.FromError(err), "%s peer list is not running", _name)
+	}
+
+	for {
+		list, zoneAdded := l.pick()
+		if list != nil {
+			return list.Choose(ctx, req)
+		}
+		if err := l.waitForZoneAdded(ctx, zoneAdded); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// pick returns the peer list of the zone to choose a peer from, or nil if
+// there are no zones. In that case, the returned channel is closed when a
+// zone is added.
+func (l *List) pick() (ZoneList, <-chan struct{}) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	var fallback ZoneList
+	for _, z := range l.order {
+		available := z.list.NumAvailable()
+		if available == 0 {
+			continue
+		}
+		total := available + z.list.NumUnavailable()
+		if float64(available) >= l.threshold*float64(total) {
+			return z.list, nil
+		}
+		if fallback == nil {
+			fallback = z.list
+		}
+	}
+
+	if fallback == nil && len(l.order) > 0 {
+		fallback = l.order[0].list
+	}
+	return fallback, l.zoneAdded
+}
+
+func (l *List) waitForZoneAdded(ctx context.Context, zoneAdded <-chan struct{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+			"can't wait for peer without a context deadline for a %s peer list", _name)
+	}
+
+	select {
+	case <-zoneAdded:
+		return nil
+	case <-ctx.Done():
+		return yarpcerrors.Newf(yarpcerrors.CodeUnavailable,
+			"%s peer list timed out waiting for peer: %s", _name, ctx.Err().Error())
+	}
+}
+
+// WaitUntilReady blocks until the peer list of any zone meets the given
+// readiness criteria, since requests fail over to it, or returns an error if
+// the context finishes first. Zones added while waiting are waited for too.
+// Zones whose peer lists do not support readiness are considered ready.
+func (l *List) WaitUntilReady(ctx context.Context, r peer.Readiness) error {
+	for {
+		waiters, zoneAdded, ok := l.readinessWaiters()
+		if !ok {
+			return nil
+		}
+
+		// Wait again, including the new zone, when a zone is added.
+		waitCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-zoneAdded:
+				cancel()
+			case <-waitCtx.Done():
+			}
+		}()
+		err := readiness.WaitForAny(waitCtx, waiters, r)
+		cancel()
+
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			return yarpcerrors.Newf(yarpcerrors.CodeUnavailable,
+				"%s peer list timed out waiting for readiness: %v", _name, err)
+		}
+	}
+}
+
+// readinessWaiters returns the peer lists of all zones, and a channel that
+// is closed when a zone is added. It returns false if a peer list does not
+// support readiness.
+func (l *List) readinessWaiters() ([]peer.ReadinessWaiter, <-chan struct{}, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	waiters := make([]peer.ReadinessWaiter, 0, len(l.order))
+	for _, z := range l.order {
+		w, ok := z.list.(peer.ReadinessWaiter)
+		if !ok {
+			return nil, nil, false
+		}
+		waiters = append(waiters, w)
+	}
+	return waiters, l.zoneAdded, true
+}
+
+// Introspect returns a ChooserStatus with the availability of each zone and
+// a summary of its peers.
+func (l *List) Introspect() introspection.ChooserStatus {
+	state := "Stopped"
+	if l.IsRunning() {
+		state = "Running"
+	}
+
+	l.lock.RLock()
+	order := l.order
+	l.lock.RUnlock()
+
+	zones := make([]string, 0, len(order))
+	var peers []introspection.PeerStatus
+	for _, z := range order {
+		available := z.list.NumAvailable()
+		zones = append(zones, fmt.Sprintf("%q: %d/%d available",
+			z.name, available, available+z.list.NumUnavailable()))
+
+		ic, ok := z.list.(introspection.IntrospectableChooser)
+		if !ok {
+			continue
+		}
+		for _, ps := range ic.Introspect().Peers {
+			ps.State = fmt.Sprintf("zone %q, %s", z.name, ps.State)
+			peers = append(peers, ps)
+		}
+	}
+
+	return introspection.ChooserStatus{
+		Name: "ZoneAware",
+		State: fmt.Sprintf("%s (local zone %q; %s)",
+			state, l.localZone, strings.Join(zones, ", ")),
+		Peers: peers,
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zoneaware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/peer/roundrobin"
+	"go.uber.org/yarpc/yarpcerrors"
+	"go.uber.org/yarpc/yarpctest"
+)
+
+func zoned(id, zone string) peer.Identifier {
+	return hostport.IdentifyWithAttributes(id, hostport.PeerAttributes{Zone: zone})
+}
+
+func newRoundRobin(trans peer.Transport) NewZoneListFunc {
+	return func(string) (ZoneList, error) {
+		return roundrobin.New(trans), nil
+	}
+}
+
+func choose(t *testing.T, list *List) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	p, onFinish, err := list.Choose(ctx, &transport.Request{})
+	require.NoError(t, err)
+	onFinish(nil)
+	return p.Identifier()
+}
+
+func chooseAll(t *testing.T, list *List, n int) map[string]bool {
+	chosen := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		chosen[choose(t, list)] = true
+	}
+	return chosen
+}
+
+func TestFailover(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []ListOption
+
+		// Peers to disconnect.
+		disconnect []string
+
+		want []string
+	}{
+		{
+			desc: "local zone",
+			want: []string{"1.1.1.1:80", "1.1.1.2:80"},
+		},
+		{
+			desc:       "local zone partially available",
+			disconnect: []string{"1.1.1.1:80"},
+			want:       []string{"1.1.1.2:80"},
+		},
+		{
+			desc:       "fail over by zone name",
+			disconnect: []string{"1.1.1.1:80", "1.1.1.2:80"},
+			want:       []string{"2.2.2.2:80"},
+		},
+		{
+			desc:       "fail over to failover zones",
+			opts:       []ListOption{FailoverZones("c")},
+			disconnect: []string{"1.1.1.1:80", "1.1.1.2:80"},
+			want:       []string{"3.3.3.3:80"},
+		},
+		{
+			desc:       "fail over below threshold",
+			opts:       []ListOption{FailoverThreshold(0.6)},
+			disconnect: []string{"1.1.1.1:80"},
+			want:       []string{"2.2.2.2:80"},
+		},
+		{
+			desc:       "no zone meets threshold",
+			opts:       []ListOption{FailoverThreshold(0.6), FailoverZones("c")},
+			disconnect: []string{"1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80"},
+			want:       []string{"1.1.1.2:80"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			trans := yarpctest.NewFakeTransport()
+			list := New("a", newRoundRobin(trans), tt.opts...)
+			require.NoError(t, list.Update(peer.ListUpdates{
+				Additions: []peer.Identifier{
+					zoned("1.1.1.1:80", "a"),
+					zoned("1.1.1.2:80", "a"),
+					zoned("2.2.2.2:80", "b"),
+					zoned("3.3.3.3:80", "c"),
+				},
+			}))
+			require.NoError(t, list.Start())
+			defer list.Stop()
+
+			for _, id := range tt.disconnect {
+				trans.SimulateDisconnect(hostport.PeerIdentifier(id))
+			}
+
+			want := make(map[string]bool)
+			for _, id := range tt.want {
+				want[id] = true
+			}
+			assert.Equal(t, want, chooseAll(t, list, 10))
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	trans := yarpctest.NewFakeTransport()
+	list := New("a", newRoundRobin(trans))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			zoned("1.1.1.1:80", "a"),
+			hostport.PeerIdentifier("2.2.2.2:80"),
+		},
+	}))
+	assert.Len(t, list.zones, 2)
+	assert.Equal(t, map[string]bool{"1.1.1.1:80": true}, chooseAll(t, list, 5))
+
+	// Moving the only peer out of the local zone removes the zone.
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Changes: []peer.Identifier{zoned("1.1.1.1:80", "b")},
+	}))
+	assert.Len(t, list.zones, 2)
+	assert.NotContains(t, list.zones, "a")
+	assert.Equal(t, 1, list.zones["b"].list.NumAvailable())
+
+	// Changes within a zone are passed on to the zone.
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Changes: []peer.Identifier{
+			hostport.IdentifyWithAttributes("1.1.1.1:80", hostport.PeerAttributes{Zone: "b", Weight: 2}),
+		},
+	}))
+	assert.Len(t, list.zones, 2)
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Removals: []peer.Identifier{
+			hostport.PeerIdentifier("1.1.1.1:80"),
+			hostport.PeerIdentifier("2.2.2.2:80"),
+		},
+	}))
+	assert.Empty(t, list.zones)
+	assert.Empty(t, list.order)
+	assert.Empty(t, list.peers)
+
+	err := list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			zoned("1.1.1.1:80", "a"),
+			zoned("1.1.1.1:80", "a"),
+		},
+		Removals: []peer.Identifier{hostport.PeerIdentifier("3.3.3.3:80")},
+		Changes:  []peer.Identifier{hostport.PeerIdentifier("4.4.4.4:80")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), peer.ErrPeerAddAlreadyInList("1.1.1.1:80").Error())
+	assert.Contains(t, err.Error(), peer.ErrPeerRemoveNotInList("3.3.3.3:80").Error())
+	assert.Contains(t, err.Error(), peer.ErrPeerChangeNotInList("4.4.4.4:80").Error())
+	assert.Equal(t, map[string]bool{"1.1.1.1:80": true}, chooseAll(t, list, 5))
+}
+
+func TestNewZoneListError(t *testing.T) {
+	list := New("a", func(zone string) (ZoneList, error) {
+		return nil, errors.New("great sadness")
+	})
+
+	err := list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{zoned("1.1.1.1:80", "a")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to build peer list for zone "a": great sadness`)
+	assert.Empty(t, list.peers)
+}
+
+func TestLifecycle(t *testing.T) {
+	trans := yarpctest.NewFakeTransport()
+	list := New("a", newRoundRobin(trans))
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{zoned("1.1.1.1:80", "a")},
+	}))
+	zoneA := list.zones["a"].list
+	assert.False(t, zoneA.IsRunning())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := list.Choose(ctx, &transport.Request{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "zone-aware peer list is not running")
+
+	require.NoError(t, list.Start())
+	assert.True(t, list.IsRunning())
+	assert.True(t, zoneA.IsRunning())
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{zoned("2.2.2.2:80", "b")},
+	}))
+	zoneB := list.zones["b"].list
+	assert.True(t, zoneB.IsRunning(), "zones added while running must be started")
+
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Removals: []peer.Identifier{hostport.PeerIdentifier("2.2.2.2:80")},
+	}))
+	assert.False(t, zoneB.IsRunning(), "zones without peers must be stopped")
+
+	require.NoError(t, list.Stop())
+	assert.False(t, list.IsRunning())
+	assert.False(t, zoneA.IsRunning())
+}
+
+func TestChooseWithoutPeers(t *testing.T) {
+	list := New("a", newRoundRobin(yarpctest.NewFakeTransport()))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	t.Run("no deadline", func(t *testing.T) {
+		_, _, err := list.Choose(context.Background(), &transport.Request{})
+		require.Error(t, err)
+		assert.Equal(t, yarpcerrors.CodeInvalidArgument, yarpcerrors.FromError(err).Code())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, _, err := list.Choose(ctx, &transport.Request{})
+		require.Error(t, err)
+		assert.Equal(t, yarpcerrors.CodeUnavailable, yarpcerrors.FromError(err).Code())
+	})
+
+	t.Run("peer added while waiting", func(t *testing.T) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			assert.NoError(t, list.Update(peer.ListUpdates{
+				Additions: []peer.Identifier{zoned("2.2.2.2:80", "b")},
+			}))
+		}()
+		assert.Equal(t, "2.2.2.2:80", choose(t, list))
+	})
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	trans := yarpctest.NewFakeTransport(yarpctest.InitialConnectionStatus(peer.Unavailable))
+	list := New("a", newRoundRobin(trans))
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{zoned("1.1.1.1:80", "a")},
+	}))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := list.WaitUntilReady(ctx, peer.Readiness{})
+		require.Error(t, err)
+		assert.Equal(t, yarpcerrors.CodeUnavailable, yarpcerrors.FromError(err).Code())
+	})
+
+	t.Run("zone added while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			assert.NoError(t, list.Update(peer.ListUpdates{
+				Additions: []peer.Identifier{zoned("2.2.2.2:80", "b")},
+			}))
+			trans.SimulateConnect(hostport.PeerIdentifier("2.2.2.2:80"))
+		}()
+		assert.NoError(t, list.WaitUntilReady(ctx, peer.Readiness{}))
+	})
+}
+
+func TestChooseWakesAllWaiters(t *testing.T) {
+	list := New("a", newRoundRobin(yarpctest.NewFakeTransport()))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+
+	const waiters = 2
+	chosen := make(chan string, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			p, onFinish, err := list.Choose(ctx, &transport.Request{})
+			if err != nil {
+				chosen <- err.Error()
+				return
+			}
+			onFinish(nil)
+			chosen <- p.Identifier()
+		}()
+	}
+
+	// Give the waiters time to block on the empty list.
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{zoned("1.1.1.1:80", "a")},
+	}))
+
+	for i := 0; i < waiters; i++ {
+		select {
+		case id := <-chosen:
+			assert.Equal(t, "1.1.1.1:80", id)
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("waiter %d was not woken by the new zone", i)
+		}
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	trans := yarpctest.NewFakeTransport()
+	list := New("a", newRoundRobin(trans))
+	require.NoError(t, list.Update(peer.ListUpdates{
+		Additions: []peer.Identifier{
+			zoned("1.1.1.1:80", "a"),
+			zoned("1.1.1.2:80", "a"),
+			zoned("2.2.2.2:80", "b"),
+		},
+	}))
+	require.NoError(t, list.Start())
+	defer list.Stop()
+	trans.SimulateDisconnect(hostport.PeerIdentifier("1.1.1.2:80"))
+
+	status := list.Introspect()
+	assert.Equal(t, "ZoneAware", status.Name)
+	assert.Equal(t, `Running (local zone "a"; "a": 1/2 available, "b": 1/1 available)`, status.State)
+	require.Len(t, status.Peers, 3)
+	states := make(map[string]string)
+	for _, ps := range status.Peers {
+		states[ps.Identifier] = ps.State
+	}
+	assert.Equal(t, map[string]string{
+		"1.1.1.1:80": `zone "a", Available, 0 pending request(s)`,
+		"1.1.1.2:80": `zone "a", Unavailable, 0 pending request(s)`,
+		"2.2.2.2:80": `zone "b", Available, 0 pending request(s)`,
+	}, states)
+}
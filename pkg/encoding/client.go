@@ -0,0 +1,125 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	"go.uber.org/yarpc"
+	encodingapi "go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/pkg/errors"
+)
+
+// Client makes requests to a single service with a Codec.
+type Client interface {
+	// Call performs a unary outbound request.
+	//
+	// resBodyOut is a pointer to a value that the Codec can unmarshal into.
+	Call(ctx context.Context, procedure string, reqBody interface{}, resBodyOut interface{}, opts ...yarpc.CallOption) error
+
+	// CallOneway performs a oneway outbound request.
+	CallOneway(ctx context.Context, procedure string, reqBody interface{}, opts ...yarpc.CallOption) (transport.Ack, error)
+}
+
+// NewClient builds a Client that makes requests with the given Codec's
+// encoding.
+func NewClient(c Codec, cc transport.ClientConfig) Client {
+	return codecClient{codec: c, cc: cc}
+}
+
+type codecClient struct {
+	codec Codec
+	cc    transport.ClientConfig
+}
+
+func (c codecClient) Call(ctx context.Context, procedure string, reqBody interface{}, resBodyOut interface{}, opts ...yarpc.CallOption) error {
+	call := encodingapi.NewOutboundCall(FromOptions(opts)...)
+	treq := transport.Request{
+		Caller:    c.cc.Caller(),
+		Service:   c.cc.Service(),
+		Procedure: procedure,
+		Encoding:  c.codec.Name(),
+	}
+
+	ctx, err := call.WriteToRequest(ctx, &treq)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := c.codec.Marshal(reqBody)
+	if err != nil {
+		return errors.RequestBodyEncodeError(&treq, err)
+	}
+
+	treq.Body = bytes.NewReader(encoded)
+	tres, appErr := c.cc.GetUnaryOutbound().Call(ctx, &treq)
+	if tres == nil {
+		return appErr
+	}
+
+	// we want to return the appErr if it exists as this is what
+	// the previous behavior was so we deprioritize this error
+	var decodeErr error
+	if _, err = call.ReadFromResponse(ctx, tres); err != nil {
+		decodeErr = err
+	}
+	if tres.Body != nil {
+		if body, err := ioutil.ReadAll(tres.Body); err != nil {
+			decodeErr = err
+		} else if err := c.codec.Unmarshal(body, resBodyOut); err != nil && decodeErr == nil {
+			decodeErr = errors.ResponseBodyDecodeError(&treq, err)
+		}
+		if err := tres.Body.Close(); err != nil && decodeErr == nil {
+			decodeErr = err
+		}
+	}
+
+	if appErr != nil {
+		return appErr
+	}
+	return decodeErr
+}
+
+func (c codecClient) CallOneway(ctx context.Context, procedure string, reqBody interface{}, opts ...yarpc.CallOption) (transport.Ack, error) {
+	call := encodingapi.NewOutboundCall(FromOptions(opts)...)
+	treq := transport.Request{
+		Caller:    c.cc.Caller(),
+		Service:   c.cc.Service(),
+		Procedure: procedure,
+		Encoding:  c.codec.Name(),
+	}
+
+	ctx, err := call.WriteToRequest(ctx, &treq)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := c.codec.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.RequestBodyEncodeError(&treq, err)
+	}
+	treq.Body = bytes.NewReader(encoded)
+
+	return c.cc.GetOnewayOutbound().CallOneway(ctx, &treq)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import "go.uber.org/yarpc/api/transport"
+
+// Codec marshals and unmarshals request and response bodies for an encoding.
+//
+// Procedures and clients for a new encoding may be built from a Codec with
+// Procedure, OnewayProcedure and NewClient, which take care of headers and
+// of reporting encoding failures with the errors from pkg/errors.
+type Codec interface {
+	// Name of the encoding, like "json".
+	Name() transport.Encoding
+
+	// Marshal encodes the given value.
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes the given data into v, which is a pointer to the
+	// value being decoded.
+	Unmarshal(data []byte, v interface{}) error
+}
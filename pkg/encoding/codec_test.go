@@ -0,0 +1,188 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/internal/clientconfig"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testCodec is a JSON codec under a different encoding name.
+type testCodec struct{ name transport.Encoding }
+
+func (c testCodec) Name() transport.Encoding                 { return c.name }
+func (testCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (testCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type echoRequest struct {
+	Message string `json:"message"`
+}
+
+type echoResponse struct {
+	Message string `json:"message"`
+}
+
+// handlerOutbound is an outbound that sends requests directly to a handler.
+type handlerOutbound struct {
+	transport.Outbound
+
+	spec transport.HandlerSpec
+}
+
+func (o handlerOutbound) Call(ctx context.Context, req *transport.Request) (*transport.Response, error) {
+	var rw transporttest.FakeResponseWriter
+	err := o.spec.Unary().Handle(ctx, req, &rw)
+	return &transport.Response{
+		Headers:          rw.Headers,
+		Body:             ioutil.NopCloser(&rw.Body),
+		ApplicationError: rw.IsApplicationError,
+	}, err
+}
+
+func (o handlerOutbound) CallOneway(ctx context.Context, req *transport.Request) (transport.Ack, error) {
+	return nil, o.spec.Oneway().HandleOneway(ctx, req)
+}
+
+func TestProcedure(t *testing.T) {
+	c := testCodec{name: "test"}
+	procedures := Procedure(c, "echo", func(ctx context.Context, req *echoRequest) (*echoResponse, error) {
+		call := yarpc.CallFromContext(ctx)
+		assert.Equal(t, "bar", call.Header("foo"))
+		if req.Message == "fail" {
+			return &echoResponse{Message: "failed"}, errors.New("great sadness")
+		}
+		return &echoResponse{Message: "echo " + req.Message}, nil
+	})
+	require.Len(t, procedures, 1)
+	assert.Equal(t, c.Name(), procedures[0].Encoding)
+
+	client := NewClient(c, clientconfig.MultiOutbound("caller", "service", transport.Outbounds{
+		Unary: handlerOutbound{spec: procedures[0].HandlerSpec},
+	}))
+
+	var res echoResponse
+	require.NoError(t, client.Call(context.Background(), "echo", &echoRequest{Message: "hello"}, &res, yarpc.WithHeader("foo", "bar")))
+	assert.Equal(t, "echo hello", res.Message)
+
+	res = echoResponse{}
+	err := client.Call(context.Background(), "echo", &echoRequest{Message: "fail"}, &res, yarpc.WithHeader("foo", "bar"))
+	assert.EqualError(t, err, "great sadness")
+	assert.Equal(t, "failed", res.Message)
+}
+
+func TestProcedureNonPointerRequest(t *testing.T) {
+	c := testCodec{name: "test"}
+	procedures := Procedure(c, "echo", func(ctx context.Context, req map[string]string) (map[string]string, error) {
+		return map[string]string{"message": "echo " + req["message"]}, nil
+	})
+
+	var rw transporttest.FakeResponseWriter
+	require.NoError(t, procedures[0].HandlerSpec.Unary().Handle(context.Background(), &transport.Request{
+		Caller:    "caller",
+		Service:   "service",
+		Procedure: "echo",
+		Encoding:  c.Name(),
+		Body:      bytes.NewReader([]byte(`{"message":"hello"}`)),
+	}, &rw))
+	assert.JSONEq(t, `{"message":"echo hello"}`, rw.Body.String())
+}
+
+func TestProcedureErrors(t *testing.T) {
+	c := testCodec{name: "test"}
+	handler := Procedure(c, "echo", func(ctx context.Context, req *echoRequest) (*echoResponse, error) {
+		t.Fatal("handler must not be called")
+		return nil, nil
+	})[0].HandlerSpec.Unary()
+
+	t.Run("wrong encoding", func(t *testing.T) {
+		err := handler.Handle(context.Background(), &transport.Request{
+			Caller:    "caller",
+			Service:   "service",
+			Procedure: "echo",
+			Encoding:  "json",
+			Body:      bytes.NewReader([]byte(`{}`)),
+		}, &transporttest.FakeResponseWriter{})
+		assert.Equal(t, yarpcerrors.CodeInvalidArgument, yarpcerrors.FromError(err).Code())
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		err := handler.Handle(context.Background(), &transport.Request{
+			Caller:    "caller",
+			Service:   "service",
+			Procedure: "echo",
+			Encoding:  c.Name(),
+			Body:      bytes.NewReader([]byte(`{`)),
+		}, &transporttest.FakeResponseWriter{})
+		assert.Equal(t, yarpcerrors.CodeInvalidArgument, yarpcerrors.FromError(err).Code())
+	})
+}
+
+func TestOnewayProcedure(t *testing.T) {
+	c := testCodec{name: "test"}
+	received := make(chan string, 1)
+	procedures := OnewayProcedure(c, "send", func(ctx context.Context, req *echoRequest) error {
+		received <- req.Message
+		return nil
+	})
+	require.Len(t, procedures, 1)
+	assert.Equal(t, transport.Oneway, procedures[0].HandlerSpec.Type())
+
+	client := NewClient(c, clientconfig.MultiOutbound("caller", "service", transport.Outbounds{
+		Oneway: handlerOutbound{spec: procedures[0].HandlerSpec},
+	}))
+	_, err := client.CallOneway(context.Background(), "send", &echoRequest{Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", <-received)
+}
+
+func TestProcedureInvalidSignature(t *testing.T) {
+	c := testCodec{name: "test"}
+	tests := []struct {
+		desc    string
+		handler interface{}
+	}{
+		{"not a function", 0},
+		{"no arguments", func() (*echoResponse, error) { return nil, nil }},
+		{"no context", func(string, *echoRequest) (*echoResponse, error) { return nil, nil }},
+		{"no error", func(context.Context, *echoRequest) (*echoResponse, string) { return nil, "" }},
+		{"one result", func(context.Context, *echoRequest) error { return nil }},
+	}
+
+	for _, tt := range tests {
+		assert.Panics(t, func() { Procedure(c, "foo", tt.handler) }, tt.desc)
+	}
+
+	assert.Panics(t, func() {
+		OnewayProcedure(c, "foo", func(context.Context, *echoRequest) (*echoResponse, error) { return nil, nil })
+	})
+}
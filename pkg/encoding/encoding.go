@@ -19,6 +19,21 @@
 // THE SOFTWARE.
 
 // Package encoding contains helper functionality for encoding implementations.
+//
+// A new encoding may be implemented with a Codec, which marshals and
+// unmarshals bodies.
+//
+// 	type msgpackCodec struct{}
+//
+// 	func (msgpackCodec) Name() transport.Encoding { return "msgpack" }
+// 	func (msgpackCodec) Marshal(v interface{}) ([]byte, error) { return msgpack.Marshal(v) }
+// 	func (msgpackCodec) Unmarshal(b []byte, v interface{}) error { return msgpack.Unmarshal(b, v) }
+//
+// Procedures and clients are then built from the Codec.
+//
+// 	dispatcher.Register(encoding.Procedure(msgpackCodec{}, "getValue", GetValue))
+// 	client := encoding.NewClient(msgpackCodec{}, dispatcher.ClientConfig("keyvalue"))
+// 	err := client.Call(ctx, "getValue", &GetValueRequest{...}, &resBody)
 package encoding
 
 import (
@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+
+	encodingapi "go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/pkg/errors"
+)
+
+var (
+	_ctxType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+	_errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Procedure builds a Procedure for the given Codec's encoding from a handler.
+// handler must be a function with a signature similar to,
+//
+// 	f(ctx context.Context, body $reqBody) ($resBody, error)
+//
+// Where $reqBody and $resBody are types that the Codec can unmarshal into
+// and marshal. Pointer request types receive a newly allocated value.
+func Procedure(c Codec, name string, handler interface{}) []transport.Procedure {
+	t := reflect.TypeOf(handler)
+	reqBodyType := verifyInputSignature(name, t)
+	verifyOutputSignature(name, t, 2)
+	return []transport.Procedure{
+		{
+			Name: name,
+			HandlerSpec: transport.NewUnaryHandlerSpec(
+				newCodecHandler(c, reqBodyType, handler)),
+			Encoding: c.Name(),
+		},
+	}
+}
+
+// OnewayProcedure builds a Procedure for the given Codec's encoding from a
+// oneway handler. handler must be a function with a signature similar to,
+//
+// 	f(ctx context.Context, body $reqBody) error
+//
+// Where $reqBody is a type that the Codec can unmarshal into.
+func OnewayProcedure(c Codec, name string, handler interface{}) []transport.Procedure {
+	t := reflect.TypeOf(handler)
+	reqBodyType := verifyInputSignature(name, t)
+	verifyOutputSignature(name, t, 1)
+	return []transport.Procedure{
+		{
+			Name: name,
+			HandlerSpec: transport.NewOnewayHandlerSpec(
+				newCodecHandler(c, reqBodyType, handler)),
+			Encoding: c.Name(),
+		},
+	}
+}
+
+// codecHandler adapts a user-provided handler into a transport-level Handler
+// using a Codec.
+type codecHandler struct {
+	codec       Codec
+	reqBodyType reflect.Type
+	handler     reflect.Value
+}
+
+func newCodecHandler(c Codec, reqBodyType reflect.Type, handler interface{}) codecHandler {
+	return codecHandler{
+		codec:       c,
+		reqBodyType: reqBodyType,
+		handler:     reflect.ValueOf(handler),
+	}
+}
+
+func (h codecHandler) Handle(ctx context.Context, treq *transport.Request, rw transport.ResponseWriter) error {
+	if err := errors.ExpectEncodings(treq, h.codec.Name()); err != nil {
+		return err
+	}
+
+	ctx, call := encodingapi.NewInboundCall(ctx)
+	if err := call.ReadFromRequest(treq); err != nil {
+		return err
+	}
+
+	reqBody, err := h.readRequestBody(treq)
+	if err != nil {
+		return err
+	}
+
+	results := h.handler.Call([]reflect.Value{reflect.ValueOf(ctx), reqBody})
+
+	if err := call.WriteToResponse(rw); err != nil {
+		return err
+	}
+
+	// we want to return the appErr if it exists as this is what
+	// the previous behavior was so we deprioritize this error
+	var encodeErr error
+	if result := results[0].Interface(); result != nil {
+		if body, err := h.codec.Marshal(result); err != nil {
+			encodeErr = errors.ResponseBodyEncodeError(treq, err)
+		} else if len(body) > 0 {
+			_, encodeErr = rw.Write(body)
+		}
+	}
+
+	if appErr, _ := results[1].Interface().(error); appErr != nil {
+		rw.SetApplicationError()
+		return appErr
+	}
+
+	return encodeErr
+}
+
+func (h codecHandler) HandleOneway(ctx context.Context, treq *transport.Request) error {
+	if err := errors.ExpectEncodings(treq, h.codec.Name()); err != nil {
+		return err
+	}
+
+	ctx, call := encodingapi.NewInboundCall(ctx)
+	if err := call.ReadFromRequest(treq); err != nil {
+		return err
+	}
+
+	reqBody, err := h.readRequestBody(treq)
+	if err != nil {
+		return err
+	}
+
+	results := h.handler.Call([]reflect.Value{reflect.ValueOf(ctx), reqBody})
+
+	if err, _ := results[0].Interface().(error); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readRequestBody decodes the request body into a new value of the type
+// expected by the handler. Failures to read the body are returned as-is.
+func (h codecHandler) readRequestBody(treq *transport.Request) (reflect.Value, error) {
+	var data []byte
+	if treq.Body != nil {
+		var err error
+		if data, err = ioutil.ReadAll(treq.Body); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	value := reflect.New(h.reqBodyType)
+	if h.reqBodyType.Kind() == reflect.Ptr {
+		value = reflect.New(h.reqBodyType.Elem())
+	}
+	if err := h.codec.Unmarshal(data, value.Interface()); err != nil {
+		return reflect.Value{}, errors.RequestBodyDecodeError(treq, err)
+	}
+	if h.reqBodyType.Kind() == reflect.Ptr {
+		return value, nil
+	}
+	return value.Elem(), nil
+}
+
+// verifyInputSignature verifies that the given handler type accepts a context
+// and a request body, and returns the request body type.
+func verifyInputSignature(n string, t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Func {
+		panic(fmt.Sprintf(
+			"handler for %q is not a function but a %v", n, t.Kind(),
+		))
+	}
+
+	if t.NumIn() != 2 {
+		panic(fmt.Sprintf(
+			"expected handler for %q to have 2 arguments but it had %v",
+			n, t.NumIn(),
+		))
+	}
+
+	if t.In(0) != _ctxType {
+		panic(fmt.Sprintf(
+			"the first argument of the handler for %q must be of type "+
+				"context.Context, and not: %v", n, t.In(0),
+		))
+	}
+
+	return t.In(1)
+}
+
+// verifyOutputSignature verifies that the given handler type returns the
+// given number of results, the last of which is an error.
+func verifyOutputSignature(n string, t reflect.Type, numOut int) {
+	if t.NumOut() != numOut {
+		panic(fmt.Sprintf(
+			"expected handler for %q to have %v results but it had %v",
+			n, numOut, t.NumOut(),
+		))
+	}
+
+	if t.Out(numOut-1) != _errorType {
+		panic(fmt.Sprintf(
+			"the last result of the handler for %q must be of type error, and not: %v",
+			n, t.Out(numOut-1),
+		))
+	}
+}
@@ -28,9 +28,11 @@ import (
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/internal/bufferpool"
+	intyarpcerrors "go.uber.org/yarpc/internal/yarpcerrors"
 	"go.uber.org/yarpc/yarpcerrors"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -43,6 +45,15 @@ var (
 	errInvalidGRPCMethod = yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, "invalid stream method name for request")
 )
 
+const (
+	// _plainCaller is the caller name of requests from gRPC clients that
+	// do not name their caller.
+	_plainCaller = "unknown"
+	// _plainEncoding is the encoding of requests from gRPC clients with a
+	// content-type without subtype, which gRPC defines as protobuf.
+	_plainEncoding transport.Encoding = "proto"
+)
+
 type handler struct {
 	i      *Inbound
 	logger *zap.Logger
@@ -97,12 +108,62 @@ func (h *handler) getBasicTransportRequest(ctx context.Context, streamMethod str
 	}
 
 	transportRequest.Procedure = procedure
+	if isPlainGRPCRequest(md) {
+		h.setPlainGRPCDefaults(transportRequest)
+	}
 	if err := transport.ValidateRequest(transportRequest); err != nil {
 		return nil, err
 	}
 	return transportRequest, nil
 }
 
+// isPlainGRPCRequest returns whether the request metadata is that of a gRPC
+// client that does not use YARPC, like grpc_health_probe or grpcurl, which
+// sends a content-type but none of the YARPC request headers.
+func isPlainGRPCRequest(md metadata.MD) bool {
+	if len(md[contentTypeHeader]) == 0 {
+		return false
+	}
+	for _, header := range []string{CallerHeader, ServiceHeader, EncodingHeader} {
+		if len(md[header]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// setPlainGRPCDefaults fills in the caller, service and encoding of a request
+// from a gRPC client that does not use YARPC. The service is the only one
+// with a procedure of the requested name, if any.
+func (h *handler) setPlainGRPCDefaults(req *transport.Request) {
+	if req.Caller == "" {
+		req.Caller = _plainCaller
+	}
+	if req.Encoding == "" {
+		req.Encoding = _plainEncoding
+	}
+	if req.Service == "" && h.i.router != nil {
+		req.Service = serviceForProcedure(h.i.router.Procedures(), req.Procedure, req.Encoding)
+	}
+}
+
+// serviceForProcedure returns the service of the given procedures that has a
+// procedure of the given name and encoding, or an empty string if none or
+// several services do.
+func serviceForProcedure(procedures []transport.Procedure, name string, encoding transport.Encoding) string {
+	var service string
+	for _, p := range procedures {
+		if p.Name != name || (p.Encoding != "" && p.Encoding != encoding) {
+			continue
+		}
+		if service != "" && service != p.Service {
+			return ""
+		}
+		service = p.Service
+	}
+	return service
+}
+
 // procedureFromStreamMethod converts a GRPC stream method into a yarpc
 // procedure name.  This is mostly copied from the GRPC-go server processing
 // logic here:
@@ -265,10 +326,26 @@ func handlerErrorToGRPCError(err error, responseWriter *responseWriter) error {
 			message = name + ": " + message
 		}
 	}
+	if retryAfter := intyarpcerrors.FormatRetryAfter(yarpcStatus.RetryAfter()); retryAfter != "" {
+		responseWriter.AddSystemHeader(ErrorRetryAfterHeader, retryAfter)
+	}
 	grpcCode, ok := _codeToGRPCCode[yarpcStatus.Code()]
 	// should only happen if _codeToGRPCCode does not cover all codes
 	if !ok {
 		grpcCode = codes.Unknown
 	}
-	return status.Error(grpcCode, message)
+	return newGRPCError(grpcCode, message, yarpcStatus.Details())
+}
+
+// newGRPCError returns a gRPC error with the given code and message, carrying
+// the given details in the grpc-status-details-bin trailer.
+func newGRPCError(code codes.Code, message string, details []yarpcerrors.Detail) error {
+	if len(details) == 0 {
+		return status.Error(code, message)
+	}
+	return status.ErrorProto(&spb.Status{
+		Code:    int32(code),
+		Message: message,
+		Details: intyarpcerrors.DetailsToProto(details),
+	})
 }
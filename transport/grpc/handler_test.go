@@ -26,7 +26,10 @@ import (
 	"net"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/encoding/raw"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -115,3 +118,39 @@ func TestInvalidStreamMultipleHeaders(t *testing.T) {
 	require.Contains(t, err.Error(), "code:invalid-argument")
 	require.Contains(t, err.Error(), "header has more than one value: rpc-caller")
 }
+
+func TestPlainGRPCRequest(t *testing.T) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:0"))
+	require.NoError(t, err)
+
+	tran := NewTransport()
+	i := tran.NewInbound(listener)
+	router := yarpc.NewMapRouter("test")
+	router.Register(raw.Procedure("keyvalue.KeyValue::Get", nil))
+	i.SetRouter(router)
+
+	h := handler{i: i}
+
+	t.Run("defaults", func(t *testing.T) {
+		md := metadata.Pairs(contentTypeHeader, baseContentType+"+raw", "user-agent", "grpc-go")
+		req, err := h.getBasicTransportRequest(metadata.NewIncomingContext(context.Background(), md), "/keyvalue.KeyValue/Get")
+		require.NoError(t, err)
+		assert.Equal(t, "unknown", req.Caller)
+		assert.Equal(t, "test", req.Service)
+		assert.Equal(t, raw.Encoding, req.Encoding)
+	})
+
+	t.Run("protobuf by default", func(t *testing.T) {
+		md := metadata.Pairs(contentTypeHeader, baseContentType)
+		_, err := h.getBasicTransportRequest(metadata.NewIncomingContext(context.Background(), md), "/keyvalue.KeyValue/Get")
+		require.Error(t, err, "raw procedures must not match protobuf requests")
+		assert.Contains(t, err.Error(), "missing service name")
+	})
+
+	t.Run("YARPC headers", func(t *testing.T) {
+		md := metadata.Pairs(contentTypeHeader, baseContentType, ServiceHeader, "test")
+		_, err := h.getBasicTransportRequest(metadata.NewIncomingContext(context.Background(), md), "/keyvalue.KeyValue/Get")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing caller name")
+	})
+}
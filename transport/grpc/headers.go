@@ -62,9 +62,17 @@ const (
 	EncodingHeader = "rpc-encoding"
 	// ErrorNameHeader is the header key for the error name.
 	ErrorNameHeader = "rpc-error-name"
+	// ErrorRetryAfterHeader is the header key for the number of milliseconds
+	// the caller should wait before retrying the request, if the error has a
+	// retry hint.
+	ErrorRetryAfterHeader = "rpc-error-retry-after"
 	// ApplicationErrorHeader is the header key that will contain a non-empty value
 	// if there was an application error.
 	ApplicationErrorHeader = "rpc-application-error"
+	// ApplicationErrorCodeHeader is the header key that will contain the
+	// string representation of the code of the application error, if the
+	// handler specified one.
+	ApplicationErrorCodeHeader = "rpc-application-error-code"
 
 	// ApplicationErrorHeaderValue is the value that will be set for
 	// ApplicationErrorHeader is there was an application error.
@@ -112,9 +112,10 @@ func (o *Outbound) Call(ctx context.Context, request *transport.Request) (*trans
 		return nil, err
 	}
 	return &transport.Response{
-		Body:             ioutil.NopCloser(bytes.NewBuffer(responseBody)),
-		Headers:          responseHeaders,
-		ApplicationError: metadataToIsApplicationError(responseMD),
+		Body:                 ioutil.NopCloser(bytes.NewBuffer(responseBody)),
+		Headers:              responseHeaders,
+		ApplicationError:     metadataToIsApplicationError(responseMD),
+		ApplicationErrorCode: metadataToApplicationErrorCode(responseMD),
 	}, invokeErr
 }
 
@@ -199,6 +200,20 @@ func metadataToIsApplicationError(responseMD metadata.MD) bool {
 	return ok && len(value) > 0 && len(value[0]) > 0
 }
 
+// metadataToApplicationErrorCode returns the code of the application error,
+// or CodeOK if there is none or it is unknown.
+func metadataToApplicationErrorCode(responseMD metadata.MD) yarpcerrors.Code {
+	value := responseMD[ApplicationErrorCodeHeader]
+	if len(value) != 1 {
+		return yarpcerrors.CodeOK
+	}
+	var code yarpcerrors.Code
+	if err := code.UnmarshalText([]byte(value[0])); err != nil {
+		return yarpcerrors.CodeOK
+	}
+	return code
+}
+
 func invokeErrorToYARPCError(err error, responseMD metadata.MD) error {
 	if err == nil {
 		return nil
@@ -216,12 +231,16 @@ func invokeErrorToYARPCError(err error, responseMD metadata.MD) error {
 		code = yarpcerrors.CodeUnknown
 	}
 	var name string
+	var retryAfter time.Duration
 	if responseMD != nil {
 		value, ok := responseMD[ErrorNameHeader]
 		// TODO: what to do if the length is > 1?
 		if ok && len(value) == 1 {
 			name = value[0]
 		}
+		if value := responseMD[ErrorRetryAfterHeader]; len(value) == 1 {
+			retryAfter = intyarpcerrors.ParseRetryAfter(value[0])
+		}
 	}
 	message := status.Message()
 	// we put the name as a prefix for grpc compatibility
@@ -231,7 +250,9 @@ func invokeErrorToYARPCError(err error, responseMD metadata.MD) error {
 	} else if name != "" && message == name {
 		message = ""
 	}
-	return intyarpcerrors.NewWithNamef(code, name, message)
+	return intyarpcerrors.NewWithNamef(code, name, message).
+		WithDetails(intyarpcerrors.DetailsFromProto(status.Proto().GetDetails())...).
+		WithRetryAfter(retryAfter)
 }
 
 // CallStream implements transport.StreamOutbound#CallStream.
@@ -25,6 +25,7 @@ import (
 
 	"go.uber.org/multierr"
 	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -56,6 +57,12 @@ func (r *responseWriter) SetApplicationError() {
 	r.AddSystemHeader(ApplicationErrorHeader, ApplicationErrorHeaderValue)
 }
 
+func (r *responseWriter) SetApplicationErrorCode(code yarpcerrors.Code) {
+	if text, err := code.MarshalText(); err == nil && code != yarpcerrors.CodeOK {
+		r.AddSystemHeader(ApplicationErrorCodeHeader, string(text))
+	}
+}
+
 func (r *responseWriter) AddSystemHeader(key string, value string) {
 	if r.md == nil {
 		r.md = metadata.New(nil)
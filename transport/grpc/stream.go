@@ -29,6 +29,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/atomic"
 	"go.uber.org/yarpc/api/transport"
+	intyarpcerrors "go.uber.org/yarpc/internal/yarpcerrors"
 	"go.uber.org/yarpc/yarpcerrors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -158,7 +159,8 @@ func toYARPCStreamError(err error) error {
 	if !ok {
 		code = yarpcerrors.CodeUnknown
 	}
-	return yarpcerrors.Newf(code, status.Message())
+	return yarpcerrors.Newf(code, status.Message()).
+		WithDetails(intyarpcerrors.DetailsFromProto(status.Proto().GetDetails())...)
 }
 
 func toGRPCStreamError(err error) error {
@@ -179,5 +181,5 @@ func toGRPCStreamError(err error) error {
 	if !ok {
 		grpcCode = codes.Unknown
 	}
-	return status.Error(grpcCode, message)
+	return newGRPCError(grpcCode, message, yarpcStatus.Details())
 }
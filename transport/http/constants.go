@@ -67,6 +67,11 @@ const (
 	// Whether the response body contains an application error.
 	ApplicationStatusHeader = "Rpc-Status"
 
+	// ApplicationErrorCodeHeader contains the string representation of the
+	// code of the application error in the response body, if the handler
+	// specified one.
+	ApplicationErrorCodeHeader = "Rpc-Application-Error-Code"
+
 	// ErrorCodeHeader contains the string representation of the error code.
 	ErrorCodeHeader = "Rpc-Error-Code"
 
@@ -77,6 +82,15 @@ const (
 	// BothResponseError feature is enabled.
 	ErrorMessageHeader = "Rpc-Error-Message"
 
+	// ErrorDetailsHeader contains the details of an error, if any, as a
+	// base64-encoded google.rpc.Status message holding only the details.
+	ErrorDetailsHeader = "Rpc-Error-Details"
+
+	// ErrorRetryAfterHeader contains the number of milliseconds the caller
+	// should wait before retrying the request, if the error has a retry hint.
+	// The standard Retry-After header is also set, rounded up to seconds.
+	ErrorRetryAfterHeader = "Rpc-Error-Retry-After"
+
 	// AcceptsBothResponseErrorHeader says that the BothResponseError
 	// feature is supported on the client. If the value is "true",
 	// this indicates true.
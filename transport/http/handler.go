@@ -34,6 +34,7 @@ import (
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/internal/bufferpool"
 	"go.uber.org/yarpc/internal/iopool"
+	intyarpcerrors "go.uber.org/yarpc/internal/yarpcerrors"
 	"go.uber.org/yarpc/pkg/errors"
 	"go.uber.org/yarpc/yarpcerrors"
 	"go.uber.org/zap"
@@ -75,6 +76,15 @@ func (h handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if status.Name() != "" {
 		responseWriter.AddSystemHeader(ErrorNameHeader, status.Name())
 	}
+	if details, err := intyarpcerrors.EncodeDetails(status.Details()); err != nil {
+		h.logger.Error("failed to encode error details", zap.Error(err))
+	} else if details != "" {
+		responseWriter.AddSystemHeader(ErrorDetailsHeader, details)
+	}
+	if retryAfter := intyarpcerrors.FormatRetryAfter(status.RetryAfter()); retryAfter != "" {
+		responseWriter.AddSystemHeader(ErrorRetryAfterHeader, retryAfter)
+		responseWriter.AddSystemHeader(retryAfterHeader, formatRetryAfterSeconds(status.RetryAfter()))
+	}
 	if bothResponseError && h.bothResponseError {
 		responseWriter.AddSystemHeader(BothResponseErrorHeader, AcceptTrue)
 		responseWriter.AddSystemHeader(ErrorMessageHeader, status.Message())
@@ -260,6 +270,12 @@ func (rw *responseWriter) SetApplicationError() {
 	rw.w.Header().Set(ApplicationStatusHeader, ApplicationErrorStatus)
 }
 
+func (rw *responseWriter) SetApplicationErrorCode(code yarpcerrors.Code) {
+	if text, err := code.MarshalText(); err == nil && code != yarpcerrors.CodeOK {
+		rw.w.Header().Set(ApplicationErrorCodeHeader, string(text))
+	}
+}
+
 func (rw *responseWriter) AddSystemHeader(key string, value string) {
 	rw.w.Header().Set(key, value)
 }
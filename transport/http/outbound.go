@@ -270,6 +270,9 @@ func (o *Outbound) call(ctx context.Context, treq *transport.Request) (*transpor
 		Body:             response.Body,
 		ApplicationError: response.Header.Get(ApplicationStatusHeader) == ApplicationErrorStatus,
 	}
+	if tres.ApplicationError {
+		tres.ApplicationErrorCode = getApplicationErrorCode(response.Header.Get(ApplicationErrorCodeHeader))
+	}
 
 	bothResponseError := response.Header.Get(BothResponseErrorHeader) == AcceptTrue
 	if bothResponseError && o.bothResponseError {
@@ -401,11 +404,32 @@ func getYARPCErrorFromResponse(response *http.Response, bothResponseError bool)
 			code = errorCode
 		}
 	}
-	return intyarpcerrors.NewWithNamef(
+	status := intyarpcerrors.NewWithNamef(
 		code,
 		response.Header.Get(ErrorNameHeader),
 		strings.TrimSuffix(contents, "\n"),
 	)
+	// Details that can't be decoded are dropped rather than masking the
+	// error itself.
+	if details, err := intyarpcerrors.DecodeDetails(response.Header.Get(ErrorDetailsHeader)); err == nil {
+		status = status.WithDetails(details...)
+	}
+	retryAfter := intyarpcerrors.ParseRetryAfter(response.Header.Get(ErrorRetryAfterHeader))
+	if retryAfter == 0 {
+		// Proxies and non-YARPC servers may only set the standard header.
+		retryAfter = parseRetryAfterSeconds(response.Header.Get(retryAfterHeader))
+	}
+	return status.WithRetryAfter(retryAfter)
+}
+
+// getApplicationErrorCode parses the code of an application error, returning
+// CodeOK if it is missing or unknown.
+func getApplicationErrorCode(text string) yarpcerrors.Code {
+	var code yarpcerrors.Code
+	if text == "" || code.UnmarshalText([]byte(text)) != nil {
+		return yarpcerrors.CodeOK
+	}
+	return code
 }
 
 // Only does verification if there is a response header
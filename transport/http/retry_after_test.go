@@ -0,0 +1,39 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, "2", formatRetryAfterSeconds(1500*time.Millisecond))
+	assert.Equal(t, "1", formatRetryAfterSeconds(time.Second))
+	assert.Equal(t, "1", formatRetryAfterSeconds(time.Millisecond))
+
+	assert.Equal(t, 3*time.Second, parseRetryAfterSeconds("3"))
+	assert.Equal(t, time.Duration(0), parseRetryAfterSeconds(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfterSeconds("0"))
+	assert.Equal(t, time.Duration(0), parseRetryAfterSeconds("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
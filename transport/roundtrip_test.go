@@ -143,7 +143,7 @@ func (tt tchannelTransport) WithRouter(r transport.Router, f func(transport.Unar
 	serverOpts := testutils.NewOpts().SetServiceName(testService)
 	clientOpts := testutils.NewOpts().SetServiceName(testCaller)
 	testutils.WithServer(tt.t, serverOpts, func(ch *tchannel.Channel, hostPort string) {
-		ix, err := tch.NewChannelTransport(tch.WithChannel(ch))
+		ix, err := tch.NewChannelTransport(tch.WithChannel(ch), tch.SendErrorMetadata())
 		require.NoError(tt.t, err)
 
 		i := ix.NewInbound()
@@ -242,6 +242,31 @@ func TestSimpleRoundTrip(t *testing.T) {
 				assert.True(t, yarpcerrors.FromError(err).Code() == yarpcerrors.CodeInvalidArgument, err.Error())
 			},
 		},
+		{
+			name:        "details",
+			requestBody: "qux",
+			responseError: yarpcerrors.Newf(yarpcerrors.CodeFailedPrecondition, "not yet").WithDetails(
+				yarpcerrors.Detail{TypeURL: "type.example.com/Precondition", Value: []byte("ready")},
+			),
+			wantError: func(err error) {
+				status := yarpcerrors.FromError(err)
+				assert.Equal(t, yarpcerrors.CodeFailedPrecondition, status.Code(), err.Error())
+				assert.Equal(t, "not yet", status.Message(), err.Error())
+				assert.Equal(t, []yarpcerrors.Detail{
+					{TypeURL: "type.example.com/Precondition", Value: []byte("ready")},
+				}, status.Details(), err.Error())
+			},
+		},
+		{
+			name:          "retry after",
+			requestBody:   "baz",
+			responseError: yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down").WithRetryAfter(1500 * time.Millisecond),
+			wantError: func(err error) {
+				status := yarpcerrors.FromError(err)
+				assert.Equal(t, yarpcerrors.CodeResourceExhausted, status.Code(), err.Error())
+				assert.Equal(t, 1500*time.Millisecond, status.RetryAfter(), err.Error())
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -310,6 +335,43 @@ func TestSimpleRoundTrip(t *testing.T) {
 	}
 }
 
+func TestApplicationErrorCodeRoundTrip(t *testing.T) {
+	transports := []roundTripTransport{
+		httpTransport{t},
+		tchannelTransport{t},
+		grpcTransport{t},
+	}
+
+	for _, trans := range transports {
+		t.Run(trans.Name(), func(t *testing.T) {
+			handler := unaryHandlerFunc(func(_ context.Context, r *transport.Request, w transport.ResponseWriter) error {
+				w.SetApplicationError()
+				setter, ok := w.(transport.ApplicationErrorCodeSetter)
+				require.True(t, ok, "%T must be able to set application error codes", w)
+				setter.SetApplicationErrorCode(yarpcerrors.CodeNotFound)
+				_, err := w.Write([]byte("not found"))
+				return err
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*testtime.Millisecond)
+			defer cancel()
+
+			trans.WithRouter(staticRouter{Handler: handler}, func(o transport.UnaryOutbound) {
+				res, err := o.Call(ctx, &transport.Request{
+					Caller:    testCaller,
+					Service:   testService,
+					Procedure: testProcedure,
+					Encoding:  raw.Encoding,
+					Body:      bytes.NewBufferString("foo"),
+				})
+				require.NoError(t, err)
+				assert.True(t, res.ApplicationError, "application error bit unset")
+				assert.Equal(t, yarpcerrors.CodeNotFound, res.ApplicationErrorCode)
+			})
+		})
+	}
+}
+
 func TestSimpleRoundTripOneway(t *testing.T) {
 	trans := httpTransport{t}
 
@@ -197,12 +197,14 @@ func (o *ChannelOutbound) Call(ctx context.Context, req *transport.Request) (*tr
 	}
 
 	err = getResponseError(headers)
+	applicationErrorCode := getApplicationErrorCode(headers)
 	deleteReservedHeaders(headers)
 
 	resp := &transport.Response{
-		Headers:          headers,
-		Body:             resBody,
-		ApplicationError: res.ApplicationError(),
+		Headers:              headers,
+		Body:                 resBody,
+		ApplicationError:     res.ApplicationError(),
+		ApplicationErrorCode: applicationErrorCode,
 	}
 	return resp, err
 }
@@ -255,5 +257,27 @@ func getResponseError(headers transport.Headers) error {
 	}
 	errorName, _ := headers.Get(ErrorNameHeaderKey)
 	errorMessage, _ := headers.Get(ErrorMessageHeaderKey)
-	return intyarpcerrors.NewWithNamef(errorCode, errorName, errorMessage)
+	status := intyarpcerrors.NewWithNamef(errorCode, errorName, errorMessage)
+	// Details that can't be decoded are dropped rather than masking the
+	// error itself.
+	errorDetails, _ := headers.Get(ErrorDetailsHeaderKey)
+	if details, err := intyarpcerrors.DecodeDetails(errorDetails); err == nil {
+		status = status.WithDetails(details...)
+	}
+	errorRetryAfter, _ := headers.Get(ErrorRetryAfterHeaderKey)
+	return status.WithRetryAfter(intyarpcerrors.ParseRetryAfter(errorRetryAfter))
+}
+
+// getApplicationErrorCode returns the code of the application error in the
+// response headers, or CodeOK if there is none or it is unknown.
+func getApplicationErrorCode(headers transport.Headers) yarpcerrors.Code {
+	text, ok := headers.Get(ApplicationErrorCodeHeaderKey)
+	if !ok {
+		return yarpcerrors.CodeOK
+	}
+	var code yarpcerrors.Code
+	if err := code.UnmarshalText([]byte(text)); err != nil {
+		return yarpcerrors.CodeOK
+	}
+	return code
 }
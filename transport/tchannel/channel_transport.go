@@ -88,6 +88,7 @@ func (options transportOptions) newChannelTransport() *ChannelTransport {
 		tracer:            options.tracer,
 		logger:            logger.Named("tchannel"),
 		originalHeaders:   options.originalHeaders,
+		errorMetadata:     options.errorMetadata,
 		newResponseWriter: newHandlerWriter,
 	}
 }
@@ -104,6 +105,7 @@ type ChannelTransport struct {
 	logger            *zap.Logger
 	router            transport.Router
 	originalHeaders   bool
+	errorMetadata     bool
 	newResponseWriter func(inboundCallResponse, tchannel.Format, headerCase) responseWriter
 }
 
@@ -139,7 +141,7 @@ func (t *ChannelTransport) start() error {
 		for s := range services {
 			sc := t.ch.GetSubChannel(s)
 			existing := sc.GetHandlers()
-			sc.SetHandler(handler{existing: existing, router: t.router, tracer: t.tracer, logger: t.logger, newResponseWriter: t.newResponseWriter})
+			sc.SetHandler(handler{existing: existing, router: t.router, tracer: t.tracer, logger: t.logger, errorMetadata: t.errorMetadata, newResponseWriter: t.newResponseWriter})
 		}
 	}
 
@@ -30,6 +30,7 @@ import (
 	"go.uber.org/multierr"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/internal/bufferpool"
+	intyarpcerrors "go.uber.org/yarpc/internal/yarpcerrors"
 	"go.uber.org/yarpc/pkg/errors"
 	"go.uber.org/yarpc/yarpcerrors"
 	"go.uber.org/zap"
@@ -98,6 +99,7 @@ type handler struct {
 	router            transport.Router
 	tracer            opentracing.Tracer
 	headerCase        headerCase
+	errorMetadata     bool
 	logger            *zap.Logger
 	newResponseWriter func(inboundCallResponse, tchannel.Format, headerCase) responseWriter
 }
@@ -115,18 +117,27 @@ func (h handler) handle(ctx context.Context, call inboundCall) {
 
 	err := h.callHandler(ctx, call, responseWriter)
 
-	// black-hole requests on resource exhausted errors
-	if yarpcerrors.FromError(err).Code() == yarpcerrors.CodeResourceExhausted {
+	// black-hole requests on resource exhausted errors, unless they tell
+	// the caller when to retry and we may send the retry hint
+	if status := yarpcerrors.FromError(err); status.Code() == yarpcerrors.CodeResourceExhausted &&
+		(!h.errorMetadata || status.RetryAfter() == 0) {
 		// all TChannel clients will time out instead of receiving an error
 		call.Response().Blackhole()
 		return
 	}
 	if err != nil && !responseWriter.IsApplicationError() {
-		if err := call.Response().SendSystemError(getSystemError(err)); err != nil {
-			h.logger.Error("SendSystemError failed", zap.Error(err))
-		}
 		h.logger.Error("handler failed", zap.Error(err))
-		return
+		if !h.errorMetadata || !hasErrorMetadata(err) {
+			if err := call.Response().SendSystemError(getSystemError(err)); err != nil {
+				h.logger.Error("SendSystemError failed", zap.Error(err))
+			}
+			return
+		}
+		// TChannel system errors carry only a code and a message, so if
+		// enabled, errors with more to them are sent as application errors
+		// with the error headers below, which YARPC callers turn back into
+		// the same error.
+		responseWriter.SetApplicationError()
 	}
 	if err != nil && responseWriter.IsApplicationError() {
 		// we have an error, so we're going to propagate it as a yarpc error,
@@ -142,6 +153,14 @@ func (h handler) handle(ctx context.Context, call inboundCall) {
 		if status.Message() != "" {
 			responseWriter.AddHeader(ErrorMessageHeaderKey, status.Message())
 		}
+		if details, err := intyarpcerrors.EncodeDetails(status.Details()); err != nil {
+			h.logger.Error("failed to encode error details", zap.Error(err))
+		} else if details != "" {
+			responseWriter.AddHeader(ErrorDetailsHeaderKey, details)
+		}
+		if retryAfter := intyarpcerrors.FormatRetryAfter(status.RetryAfter()); retryAfter != "" {
+			responseWriter.AddHeader(ErrorRetryAfterHeaderKey, retryAfter)
+		}
 	}
 	if err := responseWriter.Close(); err != nil {
 		if err := call.Response().SendSystemError(getSystemError(err)); err != nil {
@@ -261,6 +280,12 @@ func (hw *handlerWriter) SetApplicationError() {
 	hw.applicationError = true
 }
 
+func (hw *handlerWriter) SetApplicationErrorCode(code yarpcerrors.Code) {
+	if text, err := code.MarshalText(); err == nil && code != yarpcerrors.CodeOK {
+		hw.AddHeader(ApplicationErrorCodeHeaderKey, string(text))
+	}
+}
+
 func (hw *handlerWriter) IsApplicationError() bool {
 	return hw.applicationError
 }
@@ -309,6 +334,16 @@ func (hw *handlerWriter) Close() error {
 	return retErr
 }
 
+// hasErrorMetadata returns whether the given error carries information that
+// a TChannel system error cannot hold.
+func hasErrorMetadata(err error) bool {
+	if !yarpcerrors.IsStatus(err) {
+		return false
+	}
+	status := yarpcerrors.FromError(err)
+	return len(status.Details()) > 0 || status.RetryAfter() > 0
+}
+
 func getSystemError(err error) error {
 	if _, ok := err.(tchannel.SystemError); ok {
 		return err
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -170,6 +171,75 @@ func TestHandlerErrors(t *testing.T) {
 	}
 }
 
+func TestHandlerResourceExhausted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tests := []struct {
+		desc          string
+		errorMetadata bool
+		err           error
+		wantBlackhole bool
+	}{
+		{
+			desc:          "no retry hint",
+			err:           yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down"),
+			wantBlackhole: true,
+		},
+		{
+			desc:          "retry hint without error metadata",
+			err:           yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down").WithRetryAfter(time.Second),
+			wantBlackhole: true,
+		},
+		{
+			desc:          "no retry hint with error metadata",
+			errorMetadata: true,
+			err:           yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down"),
+			wantBlackhole: true,
+		},
+		{
+			desc:          "retry hint with error metadata",
+			errorMetadata: true,
+			err:           yarpcerrors.Newf(yarpcerrors.CodeResourceExhausted, "slow down").WithRetryAfter(time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			router := transporttest.NewMockRouter(mockCtrl)
+			router.EXPECT().Choose(gomock.Any(), gomock.Any()).
+				Return(transport.NewUnaryHandlerSpec(errorHandler{tt.err}), nil)
+			tchHandler := handler{
+				router:            router,
+				errorMetadata:     tt.errorMetadata,
+				logger:            zap.NewNop(),
+				newResponseWriter: newHandlerWriter,
+			}
+
+			resp := newResponseRecorder()
+			ctx, cancel := context.WithTimeout(context.Background(), testtime.Second)
+			defer cancel()
+			tchHandler.handle(ctx, &fakeInboundCall{
+				service: "service",
+				caller:  "caller",
+				format:  tchannel.Raw,
+				method:  "hello",
+				arg2:    []byte{0x00, 0x00},
+				resp:    resp,
+			})
+
+			assert.Equal(t, tt.wantBlackhole, resp.blackholed)
+			if !tt.wantBlackhole {
+				assert.True(t, resp.applicationError, "retry hints must be sent as application errors")
+				headers, err := decodeHeaders(bytes.NewReader(resp.arg2.Bytes()))
+				require.NoError(t, err)
+				retryAfter, _ := headers.Get(ErrorRetryAfterHeaderKey)
+				assert.Equal(t, "1000", retryAfter)
+			}
+		})
+	}
+}
+
 func TestHandlerFailures(t *testing.T) {
 	tests := []struct {
 		desc              string
@@ -39,15 +39,28 @@ const (
 	ErrorNameHeaderKey = "$rpc$-error-name"
 	// ErrorMessageHeaderKey is the response header key for the error message.
 	ErrorMessageHeaderKey = "$rpc$-error-message"
+	// ErrorDetailsHeaderKey is the response header key for the error
+	// details, a base64-encoded google.rpc.Status message holding only the
+	// details.
+	ErrorDetailsHeaderKey = "$rpc$-error-details"
+	// ErrorRetryAfterHeaderKey is the response header key for the number of
+	// milliseconds the caller should wait before retrying the request.
+	ErrorRetryAfterHeaderKey = "$rpc$-error-retry-after"
+	// ApplicationErrorCodeHeaderKey is the response header key for the code
+	// of the application error, if the handler specified one.
+	ApplicationErrorCodeHeaderKey = "$rpc$-application-error-code"
 	// ServiceHeaderKey is the response header key for the respond service
 	ServiceHeaderKey = "$rpc$-service"
 )
 
 var _reservedHeaderKeys = map[string]struct{}{
-	ErrorCodeHeaderKey:    {},
-	ErrorNameHeaderKey:    {},
-	ErrorMessageHeaderKey: {},
-	ServiceHeaderKey:      {},
+	ErrorCodeHeaderKey:            {},
+	ErrorNameHeaderKey:            {},
+	ErrorMessageHeaderKey:         {},
+	ErrorDetailsHeaderKey:         {},
+	ErrorRetryAfterHeaderKey:      {},
+	ApplicationErrorCodeHeaderKey: {},
+	ServiceHeaderKey:              {},
 }
 
 func isReservedHeaderKey(key string) bool {
@@ -28,11 +28,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/tchannel-go"
+	traw "github.com/uber/tchannel-go/raw"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/api/transport/transporttest"
 	"go.uber.org/yarpc/encoding/raw"
 	"go.uber.org/yarpc/internal/testtime"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 func TestInboundStartNew(t *testing.T) {
@@ -73,6 +76,60 @@ func (nophandler) Handle(ctx context.Context, req *transport.Request, resw trans
 	return nil
 }
 
+// errorHandler fails all requests with the same error.
+type errorHandler struct{ err error }
+
+func (h errorHandler) Handle(context.Context, *transport.Request, transport.ResponseWriter) error {
+	return h.err
+}
+
+func TestInboundErrorMetadataPlainClient(t *testing.T) {
+	handlerErr := yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument, "bad request").WithDetails(
+		yarpcerrors.Detail{TypeURL: "type.example.com/Field", Value: []byte("name")},
+	)
+
+	// call calls the procedure with a TChannel client that does not use
+	// YARPC.
+	call := func(t *testing.T, opts ...TransportOption) (*tchannel.OutboundCallResponse, error) {
+		it, err := NewTransport(append(opts, ServiceName("myservice"), ListenAddr("localhost:0"))...)
+		require.NoError(t, err)
+
+		router := yarpc.NewMapRouter("myservice")
+		router.Register([]transport.Procedure{
+			{Name: "hello", HandlerSpec: transport.NewUnaryHandlerSpec(errorHandler{handlerErr})},
+		})
+		i := it.NewInbound()
+		i.SetRouter(router)
+		require.NoError(t, i.Start())
+		require.NoError(t, it.Start())
+		defer it.Stop()
+		defer i.Stop()
+
+		ch, err := tchannel.NewChannel("caller", nil)
+		require.NoError(t, err)
+		defer ch.Close()
+
+		ctx, cancel := tchannel.NewContext(testtime.Second)
+		defer cancel()
+		// Raw arg2 holds the number of headers, none here.
+		_, _, res, err := traw.Call(ctx, ch, it.ListenAddr(), "myservice", "hello", []byte{0, 0}, nil)
+		return res, err
+	}
+
+	t.Run("system error", func(t *testing.T) {
+		_, err := call(t)
+		require.Error(t, err)
+		assert.Equal(t, tchannel.ErrCodeBadRequest, tchannel.GetSystemErrorCode(err))
+		assert.Contains(t, tchannel.GetSystemErrorMessage(err), "bad request")
+	})
+
+	t.Run("error metadata", func(t *testing.T) {
+		res, err := call(t, SendErrorMetadata())
+		require.NoError(t, err)
+		assert.True(t, res.ApplicationError(), "errors with metadata must be application errors")
+	})
+}
+
 func TestInboundSubServices(t *testing.T) {
 	it, err := NewTransport(ServiceName("myservice"), ListenAddr("localhost:0"))
 	require.NoError(t, err)
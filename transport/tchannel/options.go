@@ -57,6 +57,7 @@ type transportOptions struct {
 	connTimeout         time.Duration
 	connBackoffStrategy backoffapi.Strategy
 	originalHeaders     bool
+	errorMetadata       bool
 }
 
 // newTransportOptions constructs the default transport options struct
@@ -183,3 +184,18 @@ func OriginalHeaders() TransportOption {
 		options.originalHeaders = true
 	}
 }
+
+// SendErrorMetadata makes inbounds send errors with details or retry hints as
+// application errors with YARPC error headers, which YARPC callers turn back
+// into the same error. It also stops inbounds from black-holing requests
+// that fail with CodeResourceExhausted if the error has a retry hint.
+//
+// By default, these errors are sent as TChannel system errors, which carry
+// only a code and a message, so their details and retry hints are lost. Use
+// this option only if all callers use YARPC: other TChannel clients see an
+// application error with an empty body instead.
+func SendErrorMetadata() TransportOption {
+	return func(options *transportOptions) {
+		options.errorMetadata = true
+	}
+}
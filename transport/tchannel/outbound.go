@@ -171,12 +171,14 @@ func callWithPeer(ctx context.Context, req *transport.Request, peer *tchannel.Pe
 	}
 
 	err = getResponseError(headers)
+	applicationErrorCode := getApplicationErrorCode(headers)
 	deleteReservedHeaders(headers)
 
 	resp := &transport.Response{
-		Headers:          headers,
-		Body:             resBody,
-		ApplicationError: res.ApplicationError(),
+		Headers:              headers,
+		Body:                 resBody,
+		ApplicationError:     res.ApplicationError(),
+		ApplicationErrorCode: applicationErrorCode,
 	}
 	return resp, err
 }
@@ -65,6 +65,7 @@ type Transport struct {
 	connectorsGroup     sync.WaitGroup
 	connBackoffStrategy backoffapi.Strategy
 	headerCase          headerCase
+	errorMetadata       bool
 
 	peers map[string]*tchannelPeer
 }
@@ -110,6 +111,7 @@ func (o transportOptions) newTransport() *Transport {
 		tracer:              o.tracer,
 		logger:              logger,
 		headerCase:          headerCase,
+		errorMetadata:       o.errorMetadata,
 		newResponseWriter:   newHandlerWriter,
 	}
 }
@@ -201,6 +203,7 @@ func (t *Transport) start() error {
 			router:            t.router,
 			tracer:            t.tracer,
 			headerCase:        t.headerCase,
+			errorMetadata:     t.errorMetadata,
 			logger:            t.logger,
 			newResponseWriter: t.newResponseWriter,
 		},
@@ -113,21 +113,21 @@ func (b *builder) Build() (yarpc.Config, error) {
 		}
 
 		if o := c.Unary; o != nil {
-			ob.Unary, err = buildUnaryOutbound(o, transports[o.TransportSpec.Name], b.kit)
+			ob.Unary, err = buildUnaryOutbound(o, transports[o.TransportSpec.Name], b.kit.withOutbound(ccname, "unary"))
 			if err != nil {
 				errs = multierr.Append(errs, fmt.Errorf(`failed to configure unary outbound for %q: %v`, ccname, err))
 				continue
 			}
 		}
 		if o := c.Oneway; o != nil {
-			ob.Oneway, err = buildOnewayOutbound(o, transports[o.TransportSpec.Name], b.kit)
+			ob.Oneway, err = buildOnewayOutbound(o, transports[o.TransportSpec.Name], b.kit.withOutbound(ccname, "oneway"))
 			if err != nil {
 				errs = multierr.Append(errs, fmt.Errorf(`failed to configure oneway outbound for %q: %v`, ccname, err))
 				continue
 			}
 		}
 		if o := c.Stream; o != nil {
-			ob.Stream, err = buildStreamOutbound(o, transports[o.TransportSpec.Name], b.kit)
+			ob.Stream, err = buildStreamOutbound(o, transports[o.TransportSpec.Name], b.kit.withOutbound(ccname, "stream"))
 			if err != nil {
 				errs = multierr.Append(errs, fmt.Errorf(`failed to configure stream outbound for %q: %v`, ccname, err))
 				continue
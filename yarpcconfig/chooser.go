@@ -28,6 +28,7 @@ import (
 	"go.uber.org/yarpc/api/peer"
 	"go.uber.org/yarpc/internal/config"
 	peerbind "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/peer/hostport"
 )
 
 // PeerChooser facilitates decoding and building peer choosers. A peer chooser
@@ -69,6 +70,14 @@ import (
 // robin peer list. The only remaining key is the name of the peer list
 // updater: `peers` which is just a static list of peers.
 //
+// Static peers may specify a weight and a zone for peer lists that support
+// them.
+//
+// 	weighted-round-robin:
+// 	  peers:
+// 	    - 127.0.0.1:8080;weight=3
+// 	    - 127.0.0.1:8081;weight=1;zone=us-east-1a
+//
 // Integration
 //
 // To integrate peer choosers with your transport, embed this struct into your
@@ -175,7 +184,9 @@ func (pc PeerChooser) buildPeerChooser(transport peer.Transport, identify func(s
 		if err != nil {
 			return nil, err
 		}
-		result, err := chooserBuilder.Build(transport, kit)
+		// Peer choosers that build other peer choosers with
+		// Kit.BuildPeerChooser identify their peers the same way.
+		result, err := chooserBuilder.Build(transport, kit.withIdentify(identify))
 		if err != nil {
 			return nil, err
 		}
@@ -263,7 +274,11 @@ func buildPeerListUpdater(c config.AttributeMap, identify func(string) peer.Iden
 		return nil, err
 	}
 	if len(peers) > 0 {
-		return peerbind.BindPeers(identifyAll(identify, peers)), nil
+		pids, err := identifyAll(identify, peers)
+		if err != nil {
+			return nil, err
+		}
+		return peerbind.BindPeers(pids), nil
 	}
 	// TODO: Make peers a separate peer list updater that is registered by
 	// default instead of special casing here.
@@ -314,7 +329,9 @@ func buildPeerListUpdater(c config.AttributeMap, identify func(string) peer.Iden
 		return nil, err
 	}
 
-	result, err := peerListUpdaterBuilder.Build(kit)
+	// Peer list updaters that wrap other peer list updaters identify their
+	// peers the same way.
+	result, err := peerListUpdaterBuilder.Build(kit.withIdentify(identify))
 	if err != nil {
 		return nil, err
 	}
@@ -322,12 +339,18 @@ func buildPeerListUpdater(c config.AttributeMap, identify func(string) peer.Iden
 	return result.(peer.Binder), nil
 }
 
-func identifyAll(identify func(string) peer.Identifier, peers []string) []peer.Identifier {
+// identifyAll identifies each of the given peers. Peers may carry
+// attributes, as in "127.0.0.1:8080;weight=3".
+func identifyAll(identify func(string) peer.Identifier, peers []string) ([]peer.Identifier, error) {
 	pids := make([]peer.Identifier, len(peers))
 	for i, p := range peers {
-		pids[i] = identify(p)
+		addr, attrs, err := hostport.ParseAttributes(p)
+		if err != nil {
+			return nil, err
+		}
+		pids[i] = hostport.WithAttributes(identify(addr), attrs)
 	}
-	return pids
+	return pids, nil
 }
 
 func configNames(c config.AttributeMap) (names []string) {
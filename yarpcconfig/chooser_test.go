@@ -782,6 +782,43 @@ func TestChooserConfigurator(t *testing.T) {
 				`failed to read attribute "peers"`,
 			},
 		},
+		{
+			desc: "static peers with attributes",
+			given: whitespace.Expand(`
+				outbounds:
+					their-service:
+						unary:
+							fake-transport:
+								fake-list:
+									peers:
+										- 127.0.0.1:8080;weight=3
+										- 127.0.0.1:8081;weight=1;zone=west
+			`),
+			test: func(t *testing.T, c yarpc.Config) {
+				unary, ok := c.Outbounds["their-service"].Unary.(*yarpctest.FakeOutbound)
+				require.True(t, ok, "unary outbound must be fake outbound")
+				chooser, ok := unary.Chooser().(*peer.BoundChooser)
+				require.True(t, ok, "unary chooser must be a bound chooser")
+				_, ok = chooser.Updater().(*peer.PeersUpdater)
+				require.True(t, ok, "updater is a static peer list updater")
+			},
+		},
+		{
+			desc: "static peers with invalid attributes",
+			given: whitespace.Expand(`
+				outbounds:
+					their-service:
+						unary:
+							fake-transport:
+								fake-list:
+									peers:
+										- 127.0.0.1:8080;weight=-3
+			`),
+			wantErr: []string{
+				`failed to configure unary outbound for "their-service": `,
+				`invalid weight "-3" in "127.0.0.1:8080;weight=-3": expected a positive integer`,
+			},
+		},
 		{
 			desc: "extraneous config in combination with custom updater",
 			given: whitespace.Expand(`
@@ -28,6 +28,7 @@ import (
 	"os"
 
 	"go.uber.org/multierr"
+	"go.uber.org/net/metrics"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/internal/config"
 	"go.uber.org/yarpc/internal/interpolate"
@@ -46,6 +47,7 @@ type Configurator struct {
 	knownPeerLists        map[string]*compiledPeerListSpec
 	knownPeerListUpdaters map[string]*compiledPeerListUpdaterSpec
 	resolver              interpolate.VariableResolver
+	meter                 *metrics.Scope
 }
 
 // New sets up a new empty Configurator. The returned Configurator does not
@@ -283,6 +285,7 @@ func (c *Configurator) load(serviceName string, cfg *yarpcConfig) (_ yarpc.Confi
 	}
 
 	cfg.Logging.fill(&yc)
+	cfg.Authorization.fill(&yc)
 	return yc, nil
 }
 
@@ -30,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/api/transport/transporttest"
 	"go.uber.org/yarpc/internal/interpolate"
 	"go.uber.org/yarpc/internal/whitespace"
@@ -164,6 +165,105 @@ func TestConfigurator(t *testing.T) {
 				return
 			},
 		},
+		{
+			desc: "authorization rules",
+			test: func(*testing.T, *gomock.Controller) (tt testCase) {
+				tt.serviceName = "foo"
+				tt.give = whitespace.Expand(`
+					authorization:
+						default: allow
+						rules:
+							- action: allow
+							  callers: [bar, "baz-*"]
+							  procedures: ["KeyValue::get*"]
+							- action: deny
+							  services: [foo]
+							  encodings: [thrift]
+				`)
+				tt.wantConfig = yarpc.Config{
+					Name: "foo",
+					Authorization: yarpc.AuthorizationConfig{
+						Default: yarpc.AuthorizationAllow,
+						Rules: []yarpc.AuthorizationRule{
+							{
+								Action:     yarpc.AuthorizationAllow,
+								Callers:    []string{"bar", "baz-*"},
+								Procedures: []string{"KeyValue::get*"},
+							},
+							{
+								Action:    yarpc.AuthorizationDeny,
+								Services:  []string{"foo"},
+								Encodings: []transport.Encoding{"thrift"},
+							},
+						},
+					},
+				}
+				return
+			},
+		},
+		{
+			desc: "authorization default without rules",
+			test: func(*testing.T, *gomock.Controller) (tt testCase) {
+				tt.serviceName = "foo"
+				tt.give = whitespace.Expand(`
+					authorization:
+						default: deny
+				`)
+				tt.wantConfig = yarpc.Config{
+					Name: "foo",
+					Authorization: yarpc.AuthorizationConfig{
+						Default: yarpc.AuthorizationDeny,
+					},
+				}
+				return
+			},
+		},
+		{
+			desc: "authorization rule without action",
+			test: func(*testing.T, *gomock.Controller) (tt testCase) {
+				tt.give = whitespace.Expand(`
+					authorization:
+						rules:
+							- callers: [bar]
+				`)
+				tt.wantErr = []string{
+					"failed to decode authorization rule:",
+					`"action" is required`,
+				}
+				return
+			},
+		},
+		{
+			desc: "authorization rule, invalid action",
+			test: func(*testing.T, *gomock.Controller) (tt testCase) {
+				tt.give = whitespace.Expand(`
+					authorization:
+						rules:
+							- action: maybe
+				`)
+				tt.wantErr = []string{
+					"could not decode authorization action:",
+					`unrecognized action "maybe"`,
+				}
+				return
+			},
+		},
+		{
+			desc: "authorization rule, invalid pattern",
+			test: func(*testing.T, *gomock.Controller) (tt testCase) {
+				tt.give = whitespace.Expand(`
+					authorization:
+						rules:
+							- action: allow
+							  procedures: ["KeyValue::[get"]
+				`)
+				tt.wantErr = []string{
+					"failed to decode authorization rule:",
+					`invalid pattern "KeyValue::[get"`,
+				}
+				return
+			},
+		},
 		{
 			desc: "unknown inbound",
 			test: func(*testing.T, *gomock.Controller) (tt testCase) {
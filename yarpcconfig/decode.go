@@ -23,18 +23,21 @@ package yarpcconfig
 import (
 	"errors"
 	"fmt"
+	"path"
 
 	"github.com/uber-go/mapdecode"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/internal/config"
 	"go.uber.org/zap/zapcore"
 )
 
 type yarpcConfig struct {
-	Inbounds   inbounds                       `config:"inbounds"`
-	Outbounds  clientConfigs                  `config:"outbounds"`
-	Transports map[string]config.AttributeMap `config:"transports"`
-	Logging    logging                        `config:"logging"`
+	Inbounds      inbounds                       `config:"inbounds"`
+	Outbounds     clientConfigs                  `config:"outbounds"`
+	Transports    map[string]config.AttributeMap `config:"transports"`
+	Logging       logging                        `config:"logging"`
+	Authorization authorization                  `config:"authorization"`
 }
 
 // logging allows configuring the log levels from YAML.
@@ -66,6 +69,86 @@ func (l *zapLevel) Decode(into mapdecode.Into) error {
 	return err
 }
 
+// authorization allows configuring procedure-level authorization rules from
+// YAML.
+type authorization struct {
+	Default *authorizationAction `config:"default"`
+	Rules   []authorizationRule  `config:"rules"`
+}
+
+// Fills values from this object into the provided YARPC config.
+func (a *authorization) fill(cfg *yarpc.Config) {
+	if a.Default != nil {
+		cfg.Authorization.Default = yarpc.AuthorizationAction(*a.Default)
+	}
+	if len(a.Rules) == 0 {
+		return
+	}
+
+	rules := make([]yarpc.AuthorizationRule, len(a.Rules))
+	for i, r := range a.Rules {
+		rule := yarpc.AuthorizationRule{
+			Action:     yarpc.AuthorizationAction(*r.Action),
+			Callers:    r.Callers,
+			Services:   r.Services,
+			Procedures: r.Procedures,
+		}
+		for _, e := range r.Encodings {
+			rule.Encodings = append(rule.Encodings, transport.Encoding(e))
+		}
+		rules[i] = rule
+	}
+	cfg.Authorization.Rules = rules
+}
+
+type authorizationRule struct {
+	Action     *authorizationAction `config:"action"`
+	Callers    []string             `config:"callers"`
+	Services   []string             `config:"services"`
+	Procedures []string             `config:"procedures"`
+	Encodings  []string             `config:"encodings"`
+}
+
+func (r *authorizationRule) Decode(into mapdecode.Into) error {
+	// Decode into a type without a Decode method to avoid recursing.
+	type rule authorizationRule
+	if err := into((*rule)(r)); err != nil {
+		return fmt.Errorf("failed to decode authorization rule: %v", err)
+	}
+
+	if r.Action == nil {
+		return errors.New(`failed to decode authorization rule: "action" is required`)
+	}
+
+	for _, patterns := range [][]string{r.Callers, r.Services, r.Procedures} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("failed to decode authorization rule: invalid pattern %q: %v", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+type authorizationAction yarpc.AuthorizationAction
+
+func (a *authorizationAction) Decode(into mapdecode.Into) error {
+	var s string
+	if err := into(&s); err != nil {
+		return fmt.Errorf("could not decode authorization action: %v", err)
+	}
+
+	switch s {
+	case "allow":
+		*a = authorizationAction(yarpc.AuthorizationAllow)
+	case "deny":
+		*a = authorizationAction(yarpc.AuthorizationDeny)
+	default:
+		return fmt.Errorf(`could not decode authorization action: unrecognized action %q: expected "allow" or "deny"`, s)
+	}
+	return nil
+}
+
 type inbounds []inbound
 
 func (is *inbounds) Decode(into mapdecode.Into) error {
@@ -78,7 +78,7 @@
 // as long as the information provided is the same.
 //
 // The configuration accepts the following top-level attributes: transports,
-// inbounds, and outbounds.
+// inbounds, outbounds, logging, and authorization.
 //
 // 	inbounds:
 // 	  # ...
@@ -88,9 +88,11 @@
 // 	  # ...
 // 	logging:
 // 	  # ...
+// 	authorization:
+// 	  # ...
 //
-// See the following sections for details on the logging, transports,
-// inbounds, and outbounds keys in the configuration.
+// See the following sections for details on the logging, authorization,
+// transports, inbounds, and outbounds keys in the configuration.
 //
 // Inbound Configuration
 //
@@ -266,6 +268,41 @@
 // 	  levels:
 // 	    applicationError: info
 //
+// Authorization Configuration
+//
+// The 'authorization' attribute restricts which callers may invoke which
+// procedures of this service. Requests that are denied fail with a
+// permission-denied error and are logged at warn level.
+//
+// 	authorization:
+// 	  default: deny
+// 	  rules:
+// 	    - action: allow
+// 	      callers: [frontend, "batch-*"]
+// 	      procedures: ["KeyValue::get*"]
+// 	    - action: allow
+// 	      callers: [admin]
+// 	      encodings: [thrift, proto]
+//
+// Rules are evaluated in order and the first rule that matches a request
+// decides whether it is allowed. Each rule supports the following keys.
+//
+// 	action
+// 	  Required. Either "allow" or "deny".
+// 	callers, services, procedures
+// 	  Lists of glob patterns, as accepted by path.Match, matched against the
+// 	  caller name, service name, and procedure name of the request.
+// 	encodings
+// 	  List of encodings, such as "thrift" or "json", matched exactly.
+//
+// A key that is omitted from a rule matches all requests. The 'default' key
+// decides the outcome for requests that match none of the rules and may be
+// "allow" or "deny". Defaults to "deny".
+//
+// Authorization is enforced only if at least one rule is specified or the
+// default is explicitly set to "deny". An explicit "deny" default without
+// rules denies all requests.
+//
 // Customizing Configuration
 //
 // When building your own TransportSpec, PeerListSpec, or PeerListUpdaterSpec,
@@ -27,7 +27,12 @@ import (
 	"sort"
 	"strings"
 
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/internal/config"
 	"go.uber.org/yarpc/internal/interpolate"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // Kit is an opaque object that carries context for the Configurator. Build
@@ -42,6 +47,15 @@ type Kit struct {
 
 	// TransportSpec currently being used. This may or may not be set.
 	transportSpec *compiledTransportSpec
+
+	// Identifies peers of the peer chooser currently being built. This may or
+	// may not be set.
+	identify func(string) peer.Identifier
+
+	// Name and RPC type of the outbound currently being built. These may or
+	// may not be set.
+	outboundName string
+	rpcType      string
 }
 
 // Returns a shallow copy of this Kit with spec set to the given value.
@@ -51,10 +65,53 @@ func (k *Kit) withTransportSpec(spec *compiledTransportSpec) *Kit {
 	return &newK
 }
 
+// Returns a shallow copy of this Kit with identify set to the given value.
+func (k *Kit) withIdentify(identify func(string) peer.Identifier) *Kit {
+	newK := *k
+	newK.identify = identify
+	return &newK
+}
+
+// Returns a shallow copy of this Kit for building the outbound with the given
+// name and RPC type.
+func (k *Kit) withOutbound(name, rpcType string) *Kit {
+	newK := *k
+	newK.outboundName = name
+	newK.rpcType = rpcType
+	return &newK
+}
+
 // ServiceName returns the name of the service for which components are being
 // built.
 func (k *Kit) ServiceName() string { return k.name }
 
+// Meter returns the metrics scope specified with the Metrics option of the
+// Configurator, or nil if there is none. While building an outbound, the
+// scope is tagged with the name of the outbound and its RPC type, so that
+// components of different outbounds, like their peer lists, report metrics
+// separately.
+func (k *Kit) Meter() *metrics.Scope {
+	if k.c.meter == nil || k.outboundName == "" {
+		return k.c.meter
+	}
+	return k.c.meter.Tagged(metrics.Tags{
+		"outbound": k.outboundName,
+		"rpc_type": k.rpcType,
+	})
+}
+
+// RequireMeter returns the metrics scope like Meter, or an error if the
+// Configurator has no metrics scope. Components whose configuration enables
+// metrics use it to reject configurations that would report nothing.
+func (k *Kit) RequireMeter() (*metrics.Scope, error) {
+	meter := k.Meter()
+	if meter == nil {
+		return nil, yarpcerrors.Newf(yarpcerrors.CodeInvalidArgument,
+			"Metrics require a metrics scope given to the Configurator with yarpcconfig.Metrics.")
+	}
+	return meter, nil
+}
+
 var _typeOfKit = reflect.TypeOf((*Kit)(nil))
 
 func (k *Kit) maybePeerChooserSpec(name string) *compiledPeerChooserSpec {
@@ -74,6 +131,84 @@ func (k *Kit) peerListSpec(name string) (*compiledPeerListSpec, error) {
 	return nil, errors.New(msg)
 }
 
+// BuildPeerList builds a peer list registered with the Configurator under the
+// given name from the given attributes. The peer list has no peers; peer
+// lists that delegate to other peer lists use this to build them.
+func (k *Kit) BuildPeerList(name string, attrs map[string]interface{}, t peer.Transport) (peer.ChooserList, error) {
+	spec, err := k.peerListSpec(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+	builder, err := spec.PeerList.Decode(attrs, config.InterpolateWith(k.resolver))
+	if err != nil {
+		return nil, err
+	}
+	result, err := builder.Build(t, k)
+	if err != nil {
+		return nil, err
+	}
+	return result.(peer.ChooserList), nil
+}
+
+// BuildPeerListUpdater builds a peer list updater from attributes holding
+// either a static list of peers under "peers" or the configuration of a
+// registered peer list updater under its name, as they appear in the
+// configuration of a peer list. Peer list updaters that wrap other peer list
+// updaters use this to build them.
+//
+// If identify is nil, peers are identified like the peers of the peer chooser
+// being built, or as host:port pairs otherwise.
+func (k *Kit) BuildPeerListUpdater(attrs map[string]interface{}, identify func(string) peer.Identifier) (peer.Binder, error) {
+	if identify == nil {
+		identify = k.peerIdentify()
+	}
+
+	c := make(config.AttributeMap, len(attrs))
+	for name, v := range attrs {
+		c[name] = v
+	}
+
+	binder, err := buildPeerListUpdater(c, identify, k)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) > 0 {
+		return nil, fmt.Errorf("unrecognized attributes in peer list updater config: %s",
+			strings.Join(configNames(c), ", "))
+	}
+	return binder, nil
+}
+
+// BuildPeerChooser builds a peer chooser from attributes in the same format
+// as the peer chooser configuration of an outbound: a single "peer", a preset
+// "with", or the configuration of a registered peer list or peer chooser
+// under its name. Peer choosers that delegate to other peer choosers use this
+// to build them.
+//
+// Peers are identified like the peers of the peer chooser being built, or as
+// host:port pairs otherwise.
+func (k *Kit) BuildPeerChooser(attrs map[string]interface{}, t peer.Transport) (peer.Chooser, error) {
+	var pc PeerChooser
+	if err := config.DecodeInto(&pc, attrs, config.InterpolateWith(k.resolver)); err != nil {
+		return nil, err
+	}
+
+	return pc.BuildPeerChooser(t, k.peerIdentify(), k)
+}
+
+// Returns the function identifying peers of the peer chooser being built, or
+// hostport.Identify if there is none.
+func (k *Kit) peerIdentify() func(string) peer.Identifier {
+	if k.identify == nil {
+		return hostport.Identify
+	}
+	return k.identify
+}
+
 func (k *Kit) peerChooserPreset(name string) (*compiledPeerChooserPreset, error) {
 	if k.transportSpec == nil {
 		// Currently, transportspec is set only if we're inside build*Outbound.
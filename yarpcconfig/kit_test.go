@@ -21,9 +21,18 @@
 package yarpcconfig
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/net/metrics"
+	"go.uber.org/yarpc/api/peer"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/internal/config"
+	peerbind "go.uber.org/yarpc/peer"
+	"go.uber.org/yarpc/peer/hostport"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 func TestKitWithTransportSpec(t *testing.T) {
@@ -40,3 +49,215 @@ func TestKitWithTransportSpec(t *testing.T) {
 	assert.Equal(t, "foo", root.ServiceName())
 	assert.Equal(t, "bar", child.ServiceName())
 }
+
+type nopChooserList struct{ peer.ChooserList }
+
+type nopPeerTransport struct{ peer.Transport }
+
+func TestKitBuildPeerList(t *testing.T) {
+	type listConfig struct {
+		Capacity int `config:"capacity"`
+	}
+
+	var built []listConfig
+	c := New()
+	require.NoError(t, c.RegisterPeerList(PeerListSpec{
+		Name: "my-list",
+		BuildPeerList: func(cfg listConfig, _ peer.Transport, _ *Kit) (peer.ChooserList, error) {
+			if cfg.Capacity < 0 {
+				return nil, errors.New("capacity must not be negative")
+			}
+			built = append(built, cfg)
+			return nopChooserList{}, nil
+		},
+	}))
+	kit := &Kit{c: c, name: "foo"}
+	trans := nopPeerTransport{}
+
+	list, err := kit.BuildPeerList("my-list", nil, trans)
+	require.NoError(t, err)
+	assert.Equal(t, nopChooserList{}, list)
+
+	_, err = kit.BuildPeerList("my-list", map[string]interface{}{"capacity": 5}, trans)
+	require.NoError(t, err)
+	assert.Equal(t, []listConfig{{}, {Capacity: 5}}, built)
+
+	_, err = kit.BuildPeerList("my-list", map[string]interface{}{"capacity": -1}, trans)
+	assert.EqualError(t, err, "capacity must not be negative")
+
+	_, err = kit.BuildPeerList("my-list", map[string]interface{}{"size": 5}, trans)
+	assert.Error(t, err, "unknown attributes must fail")
+
+	_, err = kit.BuildPeerList("other-list", nil, trans)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no recognized peer list or chooser "other-list"`)
+}
+
+func TestKitBuildPeerListUpdater(t *testing.T) {
+	type updaterConfig struct {
+		Name string `config:"name"`
+	}
+
+	var built []updaterConfig
+	c := New()
+	require.NoError(t, c.RegisterPeerListUpdater(PeerListUpdaterSpec{
+		Name: "my-updater",
+		BuildPeerListUpdater: func(cfg updaterConfig, _ *Kit) (peer.Binder, error) {
+			built = append(built, cfg)
+			return func(peer.List) transport.Lifecycle { return nil }, nil
+		},
+	}))
+	kit := &Kit{c: c, name: "foo"}
+
+	attrs := map[string]interface{}{
+		"my-updater": map[string]interface{}{"name": "bar"},
+	}
+	binder, err := kit.BuildPeerListUpdater(attrs, hostport.Identify)
+	require.NoError(t, err)
+	assert.NotNil(t, binder)
+	assert.Equal(t, []updaterConfig{{Name: "bar"}}, built)
+	assert.Len(t, attrs, 1, "attributes must not be modified")
+
+	binder, err = kit.BuildPeerListUpdater(map[string]interface{}{
+		"peers": []string{"127.0.0.1:8080"},
+	}, hostport.Identify)
+	require.NoError(t, err)
+	assert.NotNil(t, binder)
+
+	_, err = kit.BuildPeerListUpdater(map[string]interface{}{
+		"peers": []string{"127.0.0.1:8080"},
+		"foo":   "bar",
+	}, hostport.Identify)
+	assert.EqualError(t, err, "unrecognized attributes in peer list updater config: foo")
+
+	_, err = kit.BuildPeerListUpdater(map[string]interface{}{}, hostport.Identify)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recognized peer list updater in config")
+}
+
+func TestKitBuildPeerListUpdaterIdentify(t *testing.T) {
+	type wrapperConfig struct {
+		Inner map[string]interface{} `config:"inner"`
+	}
+
+	c := New()
+	require.NoError(t, c.RegisterPeerListUpdater(PeerListUpdaterSpec{
+		Name: "wrapper",
+		BuildPeerListUpdater: func(cfg wrapperConfig, k *Kit) (peer.Binder, error) {
+			return k.BuildPeerListUpdater(cfg.Inner, nil)
+		},
+	}))
+
+	var identified []string
+	identify := func(s string) peer.Identifier {
+		identified = append(identified, s)
+		return hostport.Identify(s)
+	}
+
+	// Without an identify function, peers are identified like the peers of
+	// the peer chooser being built.
+	kit := (&Kit{c: c, name: "foo"}).withIdentify(identify)
+	_, err := kit.BuildPeerListUpdater(map[string]interface{}{
+		"peers": []string{"127.0.0.1:8080"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:8080"}, identified)
+
+	// Wrapped peer list updaters identify peers like the wrapping one.
+	identified = nil
+	kit = &Kit{c: c, name: "foo"}
+	_, err = kit.BuildPeerListUpdater(map[string]interface{}{
+		"wrapper": map[string]interface{}{
+			"inner": map[string]interface{}{"peers": []string{"127.0.0.1:8081"}},
+		},
+	}, identify)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:8081"}, identified)
+}
+
+func TestKitBuildPeerChooser(t *testing.T) {
+	type outerConfig struct {
+		Inner map[string]interface{} `config:"inner"`
+	}
+
+	c := New()
+	require.NoError(t, c.RegisterPeerChooser(PeerChooserSpec{
+		Name: "outer",
+		BuildPeerChooser: func(cfg outerConfig, t peer.Transport, k *Kit) (peer.Chooser, error) {
+			return k.BuildPeerChooser(cfg.Inner, t)
+		},
+	}))
+	kit := &Kit{c: c, name: "foo"}
+	trans := nopPeerTransport{}
+
+	chooser, err := kit.BuildPeerChooser(map[string]interface{}{"peer": "127.0.0.1:8080"}, trans)
+	require.NoError(t, err)
+	assert.IsType(t, &peerbind.Single{}, chooser)
+
+	// Nested peer choosers identify peers like the outer one.
+	var identified []string
+	identify := func(s string) peer.Identifier {
+		identified = append(identified, s)
+		return hostport.Identify(s)
+	}
+	var pc PeerChooser
+	require.NoError(t, config.DecodeInto(&pc, map[string]interface{}{
+		"outer": map[string]interface{}{
+			"inner": map[string]interface{}{"peer": "127.0.0.1:8081"},
+		},
+	}))
+	chooser, err = pc.BuildPeerChooser(trans, identify, kit)
+	require.NoError(t, err)
+	assert.IsType(t, &peerbind.Single{}, chooser)
+	assert.Equal(t, []string{"127.0.0.1:8081"}, identified)
+
+	_, err = kit.BuildPeerChooser(map[string]interface{}{}, trans)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no peer list or chooser provided in config")
+}
+
+func TestKitMeter(t *testing.T) {
+	assert.Nil(t, (&Kit{c: New()}).Meter(), "meter must be nil without a scope")
+
+	root := metrics.New()
+	kit := &Kit{c: New(Metrics(root.Scope())), name: "foo"}
+	_, err := kit.Meter().Counter(metrics.Spec{Name: "dispatcher_counter", Help: "Counter."})
+	require.NoError(t, err)
+	_, err = kit.withOutbound("bar", "unary").Meter().Counter(metrics.Spec{Name: "outbound_counter", Help: "Counter."})
+	require.NoError(t, err)
+
+	counters := root.Snapshot().Counters
+	require.Len(t, counters, 2)
+	assert.Equal(t, "dispatcher_counter", counters[0].Name)
+	assert.Empty(t, counters[0].Tags)
+	assert.Equal(t, "outbound_counter", counters[1].Name)
+	assert.Equal(t, metrics.Tags{"outbound": "bar", "rpc_type": "unary"}, counters[1].Tags)
+}
+
+func TestKitRequireMeter(t *testing.T) {
+	_, err := (&Kit{c: New()}).RequireMeter()
+	assert.True(t, yarpcerrors.IsInvalidArgument(err), "expected an invalid argument error, got %v", err)
+	assert.Contains(t, err.Error(), "yarpcconfig.Metrics")
+
+	root := metrics.New()
+	meter, err := (&Kit{c: New(Metrics(root.Scope()))}).RequireMeter()
+	require.NoError(t, err)
+	assert.NotNil(t, meter)
+}
+
+type addrIdentifier struct{ addr string }
+
+func (a addrIdentifier) Identifier() string { return a.addr }
+
+func TestIdentifyAllKeepsIdentifierType(t *testing.T) {
+	identify := func(addr string) peer.Identifier { return addrIdentifier{addr} }
+	pids, err := identifyAll(identify, []string{"127.0.0.1:8080", "127.0.0.1:8081;weight=3;zone=west"})
+	require.NoError(t, err)
+	require.Len(t, pids, 2)
+
+	assert.Equal(t, addrIdentifier{"127.0.0.1:8080"}, pids[0])
+	assert.Equal(t, hostport.AttributedIdentifier{
+		Peer:       addrIdentifier{"127.0.0.1:8081"},
+		Attributes: hostport.PeerAttributes{Weight: 3, Zone: "west"},
+	}, pids[1])
+}
@@ -20,6 +20,8 @@
 
 package yarpcconfig
 
+import "go.uber.org/net/metrics"
+
 // Option customizes a Configurator.
 type Option func(*Configurator)
 
@@ -44,3 +46,11 @@ func InterpolationResolver(f func(k string) (v string, ok bool)) Option {
 		c.resolver = f
 	}
 }
+
+// Metrics specifies a metrics scope for components built from configuration
+// that emit metrics, like peer lists with metrics enabled. See Kit.Meter.
+func Metrics(meter *metrics.Scope) Option {
+	return func(c *Configurator) {
+		c.meter = meter
+	}
+}
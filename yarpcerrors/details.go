@@ -0,0 +1,56 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package yarpcerrors
+
+import "encoding/json"
+
+// Detail is a typed detail of a Status, like a field violation, retry
+// information or a quota failure, which callers can inspect
+// programmatically.
+//
+// Details are modeled on google.protobuf.Any: a serialized value identified
+// by a type URL. Transports propagate details to callers unchanged. Use the
+// helpers in go.uber.org/yarpc/encoding/protobuf to attach and read protobuf
+// messages, NewJSONDetail and DecodeJSON for JSON values, or set the fields
+// directly for other formats.
+type Detail struct {
+	// TypeURL identifies the type of the detail, for instance
+	// "type.googleapis.com/google.rpc.RetryInfo" for protobuf messages.
+	TypeURL string
+
+	// Value is the serialized detail.
+	Value []byte
+}
+
+// NewJSONDetail returns a Detail with the given type URL holding the JSON
+// encoding of the given value.
+func NewJSONDetail(typeURL string, v interface{}) (Detail, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return Detail{}, err
+	}
+	return Detail{TypeURL: typeURL, Value: value}, nil
+}
+
+// DecodeJSON decodes the JSON value of the Detail into v.
+func (d Detail) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(d.Value, v)
+}
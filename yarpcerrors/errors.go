@@ -23,6 +23,7 @@ package yarpcerrors
 import (
 	"bytes"
 	"fmt"
+	"time"
 )
 
 // Newf returns a new Status.
@@ -68,6 +69,9 @@ type Status struct {
 	code    Code
 	name    string
 	message string
+	details []Detail
+
+	retryAfter time.Duration
 }
 
 // WithName returns a new Status with the given name.
@@ -80,8 +84,6 @@ type Status struct {
 //
 // Deprecated: Use only error codes to represent the type of the error.
 func (s *Status) WithName(name string) *Status {
-	// TODO: We plan to add a WithDetails method to add semantic metadata to
-	// Statuses soon.
 	if s == nil {
 		return nil
 	}
@@ -89,9 +91,59 @@ func (s *Status) WithName(name string) *Status {
 		return err.(*Status)
 	}
 	return &Status{
-		code:    s.code,
-		name:    name,
-		message: s.message,
+		code:       s.code,
+		name:       name,
+		message:    s.message,
+		details:    s.details,
+		retryAfter: s.retryAfter,
+	}
+}
+
+// WithDetails returns a new Status with the given details added to the
+// details of this Status.
+//
+// Details are propagated by the HTTP, gRPC and TChannel transports, so that
+// callers can inspect them with Details.
+func (s *Status) WithDetails(details ...Detail) *Status {
+	if s == nil {
+		return nil
+	}
+	if len(details) == 0 {
+		return s
+	}
+	all := make([]Detail, 0, len(s.details)+len(details))
+	all = append(all, s.details...)
+	all = append(all, details...)
+	return &Status{
+		code:       s.code,
+		name:       s.name,
+		message:    s.message,
+		details:    all,
+		retryAfter: s.retryAfter,
+	}
+}
+
+// WithRetryAfter returns a new Status with a hint that the caller should
+// wait at least the given duration before retrying the request. This is
+// meant for errors like CodeResourceExhausted and CodeUnavailable, where the
+// server knows when it expects to accept requests again.
+//
+// Retry hints are propagated by the HTTP, gRPC and TChannel transports, so
+// that outbound middleware and peer lists can honor them with RetryAfter.
+// Durations are truncated to milliseconds on the wire.
+func (s *Status) WithRetryAfter(d time.Duration) *Status {
+	if s == nil {
+		return nil
+	}
+	if d < 0 {
+		d = 0
+	}
+	return &Status{
+		code:       s.code,
+		name:       s.name,
+		message:    s.message,
+		details:    s.details,
+		retryAfter: d,
 	}
 }
 
@@ -122,6 +174,23 @@ func (s *Status) Message() string {
 	return s.message
 }
 
+// Details returns the details of this Status, if any.
+func (s *Status) Details() []Detail {
+	if s == nil {
+		return nil
+	}
+	return s.details
+}
+
+// RetryAfter returns how long the caller should wait before retrying the
+// request, or zero if this Status has no retry hint.
+func (s *Status) RetryAfter() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return s.retryAfter
+}
+
 // Error implements the error interface.
 func (s *Status) Error() string {
 	buffer := bytes.NewBuffer(nil)
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -168,3 +169,53 @@ func testAllErrorConstructors(
 	}
 	t.Run("Named", namedFunc)
 }
+
+func TestStatusDetails(t *testing.T) {
+	retry := Detail{TypeURL: "type.googleapis.com/google.rpc.RetryInfo", Value: []byte{0x0a, 0x02, 0x08, 0x01}}
+	quota, err := NewJSONDetail("example.com/quota", map[string]int{"limit": 10})
+	require.NoError(t, err)
+
+	status := Newf(CodeResourceExhausted, "slow down").WithDetails(retry)
+	assert.Equal(t, []Detail{retry}, status.Details())
+
+	named := status.WithName("too-many").WithDetails(quota)
+	assert.Equal(t, []Detail{retry, quota}, named.Details())
+	assert.Equal(t, []Detail{retry}, status.Details(), "WithDetails must not modify the original status")
+	assert.Equal(t, "too-many", named.Name())
+	assert.Equal(t, CodeResourceExhausted, named.Code())
+	assert.Equal(t, "slow down", named.Message())
+
+	var got map[string]int
+	require.NoError(t, named.Details()[1].DecodeJSON(&got))
+	assert.Equal(t, map[string]int{"limit": 10}, got)
+
+	assert.Equal(t, status, status.WithDetails(), "adding no details must return the same status")
+	assert.Nil(t, Newf(CodeUnknown, "hello").Details())
+
+	var nilStatus *Status
+	assert.Nil(t, nilStatus.WithDetails(retry))
+	assert.Nil(t, nilStatus.Details())
+
+	_, err = NewJSONDetail("example.com/bad", make(chan int))
+	assert.Error(t, err)
+}
+
+func TestStatusRetryAfter(t *testing.T) {
+	status := Newf(CodeResourceExhausted, "slow down")
+	assert.Equal(t, time.Duration(0), status.RetryAfter())
+
+	withHint := status.WithRetryAfter(time.Second)
+	assert.Equal(t, time.Second, withHint.RetryAfter())
+	assert.Equal(t, time.Duration(0), status.RetryAfter(), "WithRetryAfter must not modify the original status")
+
+	detail := Detail{TypeURL: "foo", Value: []byte("bar")}
+	named := withHint.WithDetails(detail).WithName("too-many")
+	assert.Equal(t, time.Second, named.RetryAfter(), "retry hint must be kept by WithDetails and WithName")
+	assert.Equal(t, []Detail{detail}, named.WithRetryAfter(time.Minute).Details())
+
+	assert.Equal(t, time.Duration(0), status.WithRetryAfter(-time.Second).RetryAfter())
+
+	var nilStatus *Status
+	assert.Nil(t, nilStatus.WithRetryAfter(time.Second))
+	assert.Equal(t, time.Duration(0), nilStatus.RetryAfter())
+}